@@ -0,0 +1,162 @@
+package backups
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+	"io"
+)
+
+// Backup archives are encrypted in chunks, so that large archives can be streamed without keeping them in memory.
+// Layout: magic | salt | chunks, each chunk being: final flag (1 byte) | ciphertext length (4 bytes) | ciphertext.
+// The chunk counter and the final flag are part of the nonce, so reordered, removed or truncated chunks are detected.
+const (
+	archiveMagic         = "OCELOTBACKUP1"
+	archiveSaltSize      = 16
+	archiveChunkSize     = 64 * 1024
+	archiveMaxCipherSize = archiveChunkSize + 16
+)
+
+var errTamperedArchive = errors.New("archive is corrupted or password is wrong")
+
+func deriveArchiveCipher(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(password), salt, 32768, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func archiveChunkNonce(counter uint64, isFinal bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, counter)
+	if isFinal {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+type archiveEncrypter struct {
+	writer  io.Writer
+	aead    cipher.AEAD
+	buffer  []byte
+	counter uint64
+}
+
+func newArchiveEncrypter(writer io.Writer, password string) (io.WriteCloser, error) {
+	salt := make([]byte, archiveSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := deriveArchiveCipher(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = writer.Write(append([]byte(archiveMagic), salt...)); err != nil {
+		return nil, err
+	}
+	return &archiveEncrypter{writer: writer, aead: aead}, nil
+}
+
+func (e *archiveEncrypter) Write(data []byte) (int, error) {
+	e.buffer = append(e.buffer, data...)
+	// the last chunk must be written by Close with the final flag set, so a full buffer is only flushed when more data follows
+	for len(e.buffer) > archiveChunkSize {
+		if err := e.writeChunk(e.buffer[:archiveChunkSize], false); err != nil {
+			return 0, err
+		}
+		e.buffer = e.buffer[archiveChunkSize:]
+	}
+	return len(data), nil
+}
+
+func (e *archiveEncrypter) Close() error {
+	err := e.writeChunk(e.buffer, true)
+	e.buffer = nil
+	return err
+}
+
+func (e *archiveEncrypter) writeChunk(plaintext []byte, isFinal bool) error {
+	ciphertext := e.aead.Seal(nil, archiveChunkNonce(e.counter, isFinal), plaintext, nil)
+	e.counter++
+
+	header := make([]byte, 5)
+	if isFinal {
+		header[0] = 1
+	}
+	binary.BigEndian.PutUint32(header[1:], uint32(len(ciphertext))) // #nosec G115 (CWE-190): ciphertext length is bounded by the chunk size
+	if _, err := e.writer.Write(header); err != nil {
+		return err
+	}
+	_, err := e.writer.Write(ciphertext)
+	return err
+}
+
+type archiveDecrypter struct {
+	reader   io.Reader
+	aead     cipher.AEAD
+	buffer   []byte
+	counter  uint64
+	didFinal bool
+}
+
+func newArchiveDecrypter(reader io.Reader, password string) (io.Reader, error) {
+	header := make([]byte, len(archiveMagic)+archiveSaltSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("archive is too short")
+	}
+	if string(header[:len(archiveMagic)]) != archiveMagic {
+		return nil, fmt.Errorf("file is not a backup archive")
+	}
+	aead, err := deriveArchiveCipher(password, header[len(archiveMagic):])
+	if err != nil {
+		return nil, err
+	}
+	return &archiveDecrypter{reader: reader, aead: aead}, nil
+}
+
+func (d *archiveDecrypter) Read(p []byte) (int, error) {
+	for len(d.buffer) == 0 {
+		if d.didFinal {
+			return 0, io.EOF
+		}
+		if err := d.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buffer)
+	d.buffer = d.buffer[n:]
+	return n, nil
+}
+
+func (d *archiveDecrypter) readChunk() error {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(d.reader, header); err != nil {
+		return errTamperedArchive
+	}
+	isFinal := header[0] == 1
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > archiveMaxCipherSize {
+		return errTamperedArchive
+	}
+	ciphertext := make([]byte, length)
+	if _, err := io.ReadFull(d.reader, ciphertext); err != nil {
+		return errTamperedArchive
+	}
+	plaintext, err := d.aead.Open(nil, archiveChunkNonce(d.counter, isFinal), ciphertext, nil)
+	if err != nil {
+		return errTamperedArchive
+	}
+	d.counter++
+	d.buffer = plaintext
+	d.didFinal = isFinal
+	return nil
+}
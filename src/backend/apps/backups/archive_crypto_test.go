@@ -0,0 +1,50 @@
+package backups
+
+import (
+	"bytes"
+	"github.com/ocelot-cloud/shared/assert"
+	"io"
+	"testing"
+)
+
+func encryptArchive(t *testing.T, plaintext []byte, password string) []byte {
+	var archive bytes.Buffer
+	encrypter, err := newArchiveEncrypter(&archive, password)
+	assert.Nil(t, err)
+	_, err = encrypter.Write(plaintext)
+	assert.Nil(t, err)
+	assert.Nil(t, encrypter.Close())
+	return archive.Bytes()
+}
+
+func TestArchiveEncryptionRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 10, archiveChunkSize, 3*archiveChunkSize + 7} {
+		plaintext := bytes.Repeat([]byte("a"), size)
+		archive := encryptArchive(t, plaintext, "password")
+
+		decrypter, err := newArchiveDecrypter(bytes.NewReader(archive), "password")
+		assert.Nil(t, err)
+		decrypted, err := io.ReadAll(decrypter)
+		assert.Nil(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
+
+func TestArchiveDecryptionFailures(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("a"), 2*archiveChunkSize+1)
+	archive := encryptArchive(t, plaintext, "password")
+
+	decrypter, err := newArchiveDecrypter(bytes.NewReader(archive), "wrongpassword")
+	assert.Nil(t, err)
+	_, err = io.ReadAll(decrypter)
+	assert.Equal(t, errTamperedArchive, err)
+
+	truncatedArchive := archive[:len(archive)-100]
+	decrypter, err = newArchiveDecrypter(bytes.NewReader(truncatedArchive), "password")
+	assert.Nil(t, err)
+	_, err = io.ReadAll(decrypter)
+	assert.Equal(t, errTamperedArchive, err)
+
+	_, err = newArchiveDecrypter(bytes.NewReader([]byte("not an archive at all, just text")), "password")
+	assert.NotNil(t, err)
+}
@@ -3,13 +3,19 @@ package backups
 import (
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"ocelot/backend/apps/common"
 	"ocelot/backend/clients"
+	"ocelot/backend/notifications"
+	"ocelot/backend/repositories"
 	"ocelot/backend/settings"
 	"ocelot/backend/tools"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
+	// the timezone database is embedded, since the container image does not necessarily provide one
+	_ "time/tzdata"
 )
 
 var (
@@ -17,11 +23,72 @@ var (
 	enableAutoBackupsKeyword                   settings.ConfigFieldKey = "ENABLE_AUTO_BACKUPS"
 	enableAutoUpdatesKeyword                   settings.ConfigFieldKey = "ENABLE_AUTO_UPDATES"
 	latestMaintenanceCycleExecutionDateKeyword settings.ConfigFieldKey = "LAST_MAINTENANCE_CYCLE_EXECUTION_DATE"
-	preferredMaintenanceHourKeyword            settings.ConfigFieldKey = "PREFERRED_MAINTENANCE_HOUR"
+	backupScheduleKeyword                      settings.ConfigFieldKey = "BACKUP_SCHEDULE"
+	updateScheduleKeyword                      settings.ConfigFieldKey = "UPDATE_SCHEDULE"
+	retentionScheduleKeyword                   settings.ConfigFieldKey = "RETENTION_SCHEDULE"
+	repositoryCheckScheduleKeyword             settings.ConfigFieldKey = "REPOSITORY_CHECK_SCHEDULE"
+	timezoneKeyword                            settings.ConfigFieldKey = "TIMEZONE"
+	catchUpJitterMinutesKeyword                settings.ConfigFieldKey = "CATCH_UP_JITTER_MINUTES"
+	// only read to derive the default schedules of installations which were set up before schedules were introduced
+	legacyPreferredMaintenanceHourKeyword settings.ConfigFieldKey = "PREFERRED_MAINTENANCE_HOUR"
 
 	UnixEpochStartTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	preferredMaintenanceHourOutOfRangeError = "preferred maintenance hour must be between 0 and 23"
+	defaultMaintenanceSchedule  = "0 4 * * *"
+	defaultTimezone             = "UTC"
+	defaultCatchUpJitterMinutes = 10
+)
+
+const (
+	maintenanceCheckInterval = time.Minute
+	// A scheduled run is started on time if the agent notices it within this period. Runs which were missed for longer, e.g.
+	// because the server was down, are caught up after a random jitter, so that servers which were down together do not
+	// start their maintenance at the same moment.
+	maintenanceGracePeriod  = time.Hour
+	maxCatchUpJitterMinutes = 24 * 60
+)
+
+// dueMaintenanceTasks tells which tasks are conducted in a maintenance cycle. Tasks which are due at the same time are
+// conducted in the same cycle, so that e.g. an update does not create a second backup right after a scheduled one.
+type dueMaintenanceTasks struct {
+	backups, updates, retention, repositoryChecks bool
+}
+
+var (
+	// Scheduled and manually started maintenance cycles must not run at the same time, since they would maintain the same apps twice.
+	maintenanceCycleMutex        sync.Mutex
+	ErrMaintenanceCycleIsOngoing = errors.New("a maintenance cycle is already ongoing")
+)
+
+var allMaintenanceTasks = dueMaintenanceTasks{backups: true, updates: true, retention: true, repositoryChecks: true}
+
+func (d dueMaintenanceTasks) isEmpty() bool {
+	return !d.backups && !d.updates && !d.retention && !d.repositoryChecks
+}
+
+func (d dueMaintenanceTasks) union(other dueMaintenanceTasks) dueMaintenanceTasks {
+	return dueMaintenanceTasks{
+		backups:          d.backups || other.backups,
+		updates:          d.updates || other.updates,
+		retention:        d.retention || other.retention,
+		repositoryChecks: d.repositoryChecks || other.repositoryChecks,
+	}
+}
+
+var (
+	maintenanceAgentMutex sync.Mutex
+	// nil while the agent is not running
+	stopMaintenanceAgentChannel chan struct{}
+	maintenanceAgentStopped     chan struct{}
+
+	// only accessed while holding the maintenanceCycleMutex; zero if no catch-up is pending
+	pendingCatchUpStart time.Time
+	randomCatchUpJitter = func(maxJitter time.Duration) time.Duration {
+		if maxJitter <= 0 {
+			return 0
+		}
+		return rand.N(maxJitter)
+	}
 )
 
 func StartMaintenanceAgent() {
@@ -29,77 +96,331 @@ func StartMaintenanceAgent() {
 	if err != nil {
 		Logger.Fatal("Error setting default maintenance settings: %v", err)
 	}
+	err = MaintenanceRunRepo.MarkInterruptedRuns()
+	if err != nil {
+		Logger.Error("Error marking interrupted maintenance runs: %v", err)
+	}
 
 	if tools.Config.IsMaintenanceAgentEnabled {
-		Logger.Info("Starting maintenance agent for automatic updates and backups")
-		go func() {
-			for {
-				maintenanceSettings, err := GetMaintenanceSettings()
-				if err != nil {
-					Logger.Error("Error getting maintenance settings")
-					continue
-				}
-
-				lastExecutionDate, err := getLastMaintenanceCycleExecutionDate()
-				if err != nil {
-					Logger.Error("Error getting last maintenance cycle execution date")
-					continue
-				}
-
-				isMaintenanceCycleDueValue := IsMaintenanceCycleDue(time.Now(), *lastExecutionDate, maintenanceSettings.PreferredMaintenanceHour)
-				if isMaintenanceCycleDueValue {
-					conductMaintenanceTasks()
-				}
-				time.Sleep(5 * time.Minute)
+		StartMaintenanceAgentLoop()
+	}
+}
+
+func StartMaintenanceAgentLoop() {
+	maintenanceAgentMutex.Lock()
+	defer maintenanceAgentMutex.Unlock()
+	if stopMaintenanceAgentChannel != nil {
+		return
+	}
+	Logger.Info("Starting maintenance agent for automatic updates and backups")
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	stopMaintenanceAgentChannel = stop
+	maintenanceAgentStopped = stopped
+
+	// a catch-up pending from an earlier run of the agent is evaluated anew
+	maintenanceCycleMutex.Lock()
+	pendingCatchUpStart = time.Time{}
+	maintenanceCycleMutex.Unlock()
+
+	go func() {
+		defer close(stopped)
+		for {
+			conductDueMaintenanceTasks(tools.Clock.Now())
+			select {
+			case <-stop:
+				return
+			case <-tools.Clock.After(maintenanceCheckInterval):
 			}
-		}()
+		}
+	}()
+}
+
+// StopMaintenanceAgent stops the agent loop and waits until an ongoing maintenance cycle is finished. The agent can be
+// started again afterwards.
+func StopMaintenanceAgent() {
+	maintenanceAgentMutex.Lock()
+	defer maintenanceAgentMutex.Unlock()
+	if stopMaintenanceAgentChannel == nil {
+		return
 	}
+	close(stopMaintenanceAgentChannel)
+	<-maintenanceAgentStopped
+	stopMaintenanceAgentChannel = nil
+	maintenanceAgentStopped = nil
+	Logger.Info("Maintenance agent stopped")
 }
 
-func conductMaintenanceTasks() {
-	err := tools.AppOperationMutex.TryLock("maintenance cycle")
-	if err != nil {
-		Logger.Warn("Maintenance cycle could not be started, another process is running")
+// Tasks whose scheduled run was missed are caught up after a random jitter, unless other tasks are due on time anyway.
+// Evaluations are skipped while a manually started cycle is ongoing, since its completion changes which tasks are due.
+func conductDueMaintenanceTasks(now time.Time) {
+	if !maintenanceCycleMutex.TryLock() {
+		Logger.Debug("skipping evaluation of maintenance schedules, since a maintenance cycle is ongoing")
+		return
 	}
-	defer tools.AppOperationMutex.Unlock()
+	defer maintenanceCycleMutex.Unlock()
 
-	SetLastMaintenanceCycleExecutionDate(time.Now())
-	apps, err := common.AppRepo.ListApps()
+	maintenanceSettings, err := GetMaintenanceSettings()
+	if err != nil {
+		Logger.Error("Error getting maintenance settings")
+		return
+	}
+	now = now.In(maintenanceSettings.getLocation())
+	lastExecutionDate, err := getLastMaintenanceCycleExecutionDate()
+	if err != nil {
+		Logger.Error("Error getting last maintenance cycle execution date")
+		return
+	}
+	if !lastExecutionDate.After(UnixEpochStartTime) {
+		// no cycle was missed on a fresh installation, so the schedules are evaluated from its first start on
+		Logger.Info("No previous maintenance cycle found, the first one is conducted as scheduled")
+		SetLastMaintenanceCycleExecutionDate(now)
+		return
+	}
+	dueTasks, overdueTasks, err := findDueMaintenanceTasks(*maintenanceSettings, now, *lastExecutionDate)
 	if err != nil {
-		Logger.Error("Error listing apps: %v", err)
+		Logger.Error("Error evaluating maintenance schedules: %v", err)
 		return
 	}
-	for _, app := range apps {
-		createBackupsAndConductUpdates(app)
+
+	if dueTasks.isEmpty() {
+		if overdueTasks.isEmpty() {
+			pendingCatchUpStart = time.Time{}
+			return
+		}
+		if pendingCatchUpStart.IsZero() {
+			jitter := randomCatchUpJitter(time.Duration(maintenanceSettings.CatchUpJitterMinutes) * time.Minute)
+			pendingCatchUpStart = now.Add(jitter)
+			Logger.Info("Scheduled maintenance runs were missed, catching them up at %s", pendingCatchUpStart.Format(time.RFC3339))
+		}
+		if now.Before(pendingCatchUpStart) {
+			return
+		}
+	}
+	pendingCatchUpStart = time.Time{}
+	conductMaintenanceTasks(now, dueTasks.union(overdueTasks))
+}
+
+// findDueMaintenanceTasks returns the tasks which are due on time and the tasks whose scheduled run was missed.
+func findDueMaintenanceTasks(maintenanceSettings MaintenanceSettings, now, lastRun time.Time) (dueMaintenanceTasks, dueMaintenanceTasks, error) {
+	var dueTasks, overdueTasks dueMaintenanceTasks
+	for _, task := range []struct {
+		isDue, isOverdue *bool
+		isEnabled        bool
+		schedule         string
+	}{
+		{&dueTasks.backups, &overdueTasks.backups, maintenanceSettings.AreAutoBackupsEnabled, maintenanceSettings.BackupSchedule},
+		{&dueTasks.updates, &overdueTasks.updates, maintenanceSettings.AreAutoUpdatesEnabled, maintenanceSettings.UpdateSchedule},
+		{&dueTasks.retention, &overdueTasks.retention, true, maintenanceSettings.RetentionSchedule},
+		{&dueTasks.repositoryChecks, &overdueTasks.repositoryChecks, true, maintenanceSettings.RepositoryCheckSchedule},
+	} {
+		if !task.isEnabled {
+			continue
+		}
+		isDue, err := IsScheduledRunDue(task.schedule, now, lastRun)
+		if err != nil {
+			return dueMaintenanceTasks{}, dueMaintenanceTasks{}, err
+		}
+		isOverdue, err := IsScheduledRunOverdue(task.schedule, now, lastRun)
+		if err != nil {
+			return dueMaintenanceTasks{}, dueMaintenanceTasks{}, err
+		}
+		*task.isDue = isDue
+		*task.isOverdue = isOverdue && !isDue
 	}
+	return dueTasks, overdueTasks, nil
+}
 
-	err = clients.BackupManager.RunRetentionPolicy()
+// IsScheduledRunDue tells whether the schedule had a run since the last maintenance cycle which is not longer ago than the grace period.
+// The schedule is evaluated in the location of "now", so that runs stay at the same local time across daylight saving changes.
+func IsScheduledRunDue(scheduleExpression string, now, lastRun time.Time) (bool, error) {
+	schedule, err := tools.ParseCronSchedule(scheduleExpression)
 	if err != nil {
-		Logger.Error("Error running retention policy: %v", err)
+		return false, err
+	}
+	searchStart := now.Add(-maintenanceGracePeriod)
+	if lastRun.After(searchStart) {
+		searchStart = lastRun.In(now.Location())
 	}
+	return !schedule.Next(searchStart).After(now), nil
 }
 
-func createBackupsAndConductUpdates(app tools.RepoApp) {
+// IsScheduledRunOverdue tells whether the last completed maintenance cycle is older than the schedule interval plus the grace
+// period. Since a cycle conducts all due tasks, this is the case if the first scheduled run after it was missed for longer
+// than the grace period.
+func IsScheduledRunOverdue(scheduleExpression string, now, lastRun time.Time) (bool, error) {
+	schedule, err := tools.ParseCronSchedule(scheduleExpression)
+	if err != nil {
+		return false, err
+	}
+	missedRun := schedule.Next(lastRun.In(now.Location()))
+	return !missedRun.IsZero() && now.Sub(missedRun) > maintenanceGracePeriod, nil
+}
+
+// conductMaintenanceTasks must be called while holding the maintenanceCycleMutex.
+func conductMaintenanceTasks(now time.Time, dueTasks dueMaintenanceTasks) {
+	report, err := newMaintenanceReport(tools.MaintenanceRunTriggerSchedule, now)
+	if err != nil {
+		Logger.Error("Error recording maintenance run, conducting it anyway: %v", err)
+	}
+	conductMaintenanceCycle(now, dueTasks, report)
+}
+
+// StartMaintenanceCycleNow conducts the enabled maintenance tasks in the background without waiting for their schedules and
+// returns the id of the recorded run. Like in scheduled cycles, apps are only backed up to repositories whose backup interval elapsed.
+func StartMaintenanceCycleNow(now time.Time) (int, error) {
+	if !maintenanceCycleMutex.TryLock() {
+		return 0, ErrMaintenanceCycleIsOngoing
+	}
 	maintenanceSettings, err := GetMaintenanceSettings()
 	if err != nil {
-		Logger.Error("Error getting maintenance settings: %v", err)
+		maintenanceCycleMutex.Unlock()
+		return 0, err
+	}
+	now = now.In(maintenanceSettings.getLocation())
+	report, err := newMaintenanceReport(tools.MaintenanceRunTriggerManual, now)
+	if err != nil {
+		maintenanceCycleMutex.Unlock()
+		return 0, err
+	}
+
+	dueTasks := dueMaintenanceTasks{
+		backups:          maintenanceSettings.AreAutoBackupsEnabled,
+		updates:          maintenanceSettings.AreAutoUpdatesEnabled,
+		retention:        true,
+		repositoryChecks: true,
+	}
+	go func() {
+		defer maintenanceCycleMutex.Unlock()
+		conductMaintenanceCycle(now, dueTasks, report)
+	}()
+	return report.run.Id, nil
+}
+
+// Apps are maintained one after another, each one only blocking operations on itself, so that the other apps stay operable.
+// The execution date is only saved when the cycle is completed, so that a cycle which failed early or was interrupted by a
+// shutdown is caught up.
+func conductMaintenanceCycle(now time.Time, dueTasks dueMaintenanceTasks, report *maintenanceReport) {
+	err := conductMaintenanceCycleTasks(now, dueTasks, report)
+	if errors.Is(err, tools.ErrOperationQueueClosed) {
+		Logger.Info("maintenance cycle was interrupted: %v", err)
+		report.interrupt()
 		return
 	}
+	if err == nil {
+		SetLastMaintenanceCycleExecutionDate(now)
+	}
+	report.finish()
+}
+
+// conductMaintenanceCycleTasks returns an error if the cycle could not be completed.
+func conductMaintenanceCycleTasks(now time.Time, dueTasks dueMaintenanceTasks, report *maintenanceReport) error {
+	if dueTasks.backups || dueTasks.updates {
+		apps, err := common.AppRepo.ListApps()
+		if err != nil {
+			report.addFailure(notifications.EventMaintenanceFailed, "Listing apps failed", err)
+			return err
+		}
+		var dueRepositoryIds []int
+		if dueTasks.backups {
+			dueRepositoryIds, err = listRepositoryIdsDueForAutoBackup(now)
+			if err != nil {
+				report.addFailure(notifications.EventMaintenanceFailed, "Listing backup repositories due for auto backup failed", err)
+				return err
+			}
+		}
+		for _, app := range apps {
+			ticket, err := tools.AppOperationQueue.Acquire(common.GetOperationScope(app.AppId), "maintenance of app "+app.AppName)
+			if err != nil {
+				return err
+			}
+			createBackupsAndConductUpdates(app, dueRepositoryIds, dueTasks.updates, report)
+			tools.AppOperationQueue.Release(ticket)
+		}
+		for _, repositoryId := range dueRepositoryIds {
+			err = repositories.BackupRepositoryRepo.SetLastAutoBackupDate(repositoryId, now)
+			if err != nil {
+				Logger.Error("Error setting last auto backup date of backup repository %d: %v", repositoryId, err)
+			}
+		}
+	}
+
+	if !dueTasks.retention && !dueTasks.repositoryChecks {
+		return nil
+	}
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "retention policy and repository checks")
+	if err != nil {
+		return err
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+	if dueTasks.retention {
+		err := clients.BackupManager.RunRetentionPolicy()
+		if err != nil {
+			report.addFailure(notifications.EventMaintenanceFailed, "Running the retention policy failed", err)
+			report.setRetentionResult(tools.MaintenanceOutcomeFailed, err.Error())
+		} else {
+			report.setRetentionResult(tools.MaintenanceOutcomeSucceeded, "")
+		}
+	}
+	if dueTasks.repositoryChecks {
+		checkBackupRepositories(report)
+		checkCertificateExpiry(now, report)
+	}
+	return nil
+}
+
+func listRepositoryIdsDueForAutoBackup(now time.Time) ([]int, error) {
+	dueRepositories, err := repositories.BackupRepositoryRepo.ListRepositoriesDueForAutoBackup(now)
+	if err != nil {
+		return nil, err
+	}
+	var repositoryIds []int
+	for _, repository := range dueRepositories {
+		repositoryIds = append(repositoryIds, repository.Id)
+	}
+	return repositoryIds, nil
+}
+
+// The per-app maintenance overrides take precedence over the due tasks, so an excluded app is neither backed up nor updated automatically.
+func createBackupsAndConductUpdates(app tools.RepoApp, dueRepositoryIds []int, isUpdateDue bool, report *maintenanceReport) {
+	previousResultsCount := len(report.run.AppResults)
+	defer func() {
+		if len(report.run.AppResults) == previousResultsCount {
+			report.addAppResult(app, tools.MaintenanceOutcomeSkipped, "no backup or update was due")
+		}
+	}()
+
+	if !app.IsAutoUpdateEnabled && isUpdateDue {
+		report.addAppResult(app, tools.MaintenanceOutcomeSkipped, "app is excluded from auto updates")
+		isUpdateDue = false
+	}
+	if !app.IsAutoBackupEnabled && len(dueRepositoryIds) > 0 {
+		report.addAppResult(app, tools.MaintenanceOutcomeSkipped, "app is excluded from auto backups")
+		dueRepositoryIds = nil
+	}
 
 	wasPreUpdateBackupCreated := false
-	if maintenanceSettings.AreAutoUpdatesEnabled && !common.IsOcelotDbApp(app) {
+	if isUpdateDue && !common.IsOcelotDbApp(app) {
 		err := clients.BackupManager.UpdateAppVersion(app.AppId)
-		if err != nil {
+		if err == nil {
+			wasPreUpdateBackupCreated = true
+			report.addResult("app '%s' was backed up and updated", app.AppName)
+			report.addAppResult(app, tools.MaintenanceOutcomeUpdated, "app was backed up before the update")
+		} else if isAppAlreadyUpToDateError(err) {
 			Logger.Info("app was not updated: %v", err)
+			report.addAppResult(app, tools.MaintenanceOutcomeSkipped, "no newer version is available within the update policy")
 		} else {
-			wasPreUpdateBackupCreated = true
+			report.addAppFailure(app, notifications.EventUpdateFailed, fmt.Sprintf("Update of app '%s' failed", app.AppName), err)
 		}
 	}
 
-	if maintenanceSettings.AreAutoBackupsEnabled && !wasPreUpdateBackupCreated {
-		err := clients.BackupManager.CreateBackup(app.AppId, tools.AutoBackupDescription)
+	if !wasPreUpdateBackupCreated && len(dueRepositoryIds) > 0 {
+		err := clients.BackupManager.CreateBackupInRepositories(app.AppId, tools.AutoBackupDescription, dueRepositoryIds)
 		if err != nil {
-			Logger.Info("app was not backed up: %v", err)
+			report.addAppFailure(app, notifications.EventBackupFailed, fmt.Sprintf("Backup of app '%s' failed", app.AppName), err)
+		} else {
+			report.addResult("app '%s' was backed up", app.AppName)
+			report.addAppResult(app, tools.MaintenanceOutcomeBackedUp, "")
 		}
 	}
 }
@@ -125,10 +446,90 @@ func getLastMaintenanceCycleExecutionDate() (*time.Time, error) {
 	return &lastMaintenanceCycleExecutionDate, nil
 }
 
+// MaintenanceSettings hold a cron expression for each maintenance task, e.g. "0 */6 * * *" for every six hours. The schedules,
+// the backup intervals of the repositories and their transfer windows are evaluated in the configured IANA timezone.
 type MaintenanceSettings struct {
-	AreAutoBackupsEnabled    bool `json:"are_auto_backups_enabled"`
-	AreAutoUpdatesEnabled    bool `json:"are_auto_updates_enabled"`
-	PreferredMaintenanceHour int  `json:"preferred_maintenance_hour"`
+	AreAutoBackupsEnabled   bool   `json:"are_auto_backups_enabled"`
+	AreAutoUpdatesEnabled   bool   `json:"are_auto_updates_enabled"`
+	BackupSchedule          string `json:"backup_schedule" validate:"cron_expression"`
+	UpdateSchedule          string `json:"update_schedule" validate:"cron_expression"`
+	RetentionSchedule       string `json:"retention_schedule" validate:"cron_expression"`
+	RepositoryCheckSchedule string `json:"repository_check_schedule" validate:"cron_expression"`
+	Timezone                string `json:"timezone" validate:"timezone"`
+	// missed runs are caught up after a random delay of up to this many minutes
+	CatchUpJitterMinutes int `json:"catch_up_jitter_minutes"`
+}
+
+func (m MaintenanceSettings) getLocation() *time.Location {
+	location, err := time.LoadLocation(m.Timezone)
+	if err != nil {
+		Logger.Error("timezone '%s' could not be loaded, falling back to UTC: %v", m.Timezone, err)
+		return time.UTC
+	}
+	return location
+}
+
+func getMaintenanceLocation() *time.Location {
+	maintenanceSettings, err := GetMaintenanceSettings()
+	if err != nil {
+		Logger.Error("Error getting maintenance settings, falling back to UTC: %v", err)
+		return time.UTC
+	}
+	return maintenanceSettings.getLocation()
+}
+
+// MaintenanceSettingsResponse adds the times of the last and the next maintenance runs, formatted in the configured timezone.
+// Next runs of disabled tasks are left empty.
+type MaintenanceSettingsResponse struct {
+	MaintenanceSettings
+	LastMaintenanceCycle   string `json:"last_maintenance_cycle"`
+	NextBackupRun          string `json:"next_backup_run"`
+	NextUpdateRun          string `json:"next_update_run"`
+	NextRetentionRun       string `json:"next_retention_run"`
+	NextRepositoryCheckRun string `json:"next_repository_check_run"`
+}
+
+func GetMaintenanceSettingsResponse(now time.Time) (*MaintenanceSettingsResponse, error) {
+	maintenanceSettings, err := GetMaintenanceSettings()
+	if err != nil {
+		return nil, err
+	}
+	lastExecutionDate, err := getLastMaintenanceCycleExecutionDate()
+	if err != nil {
+		return nil, err
+	}
+	location := maintenanceSettings.getLocation()
+	now = now.In(location)
+
+	response := MaintenanceSettingsResponse{MaintenanceSettings: *maintenanceSettings}
+	if lastExecutionDate.After(UnixEpochStartTime) {
+		response.LastMaintenanceCycle = lastExecutionDate.In(location).Format(time.RFC3339)
+	}
+	response.NextBackupRun = formatNextRun(maintenanceSettings.BackupSchedule, maintenanceSettings.AreAutoBackupsEnabled, now)
+	response.NextUpdateRun = formatNextRun(maintenanceSettings.UpdateSchedule, maintenanceSettings.AreAutoUpdatesEnabled, now)
+	response.NextRetentionRun = formatNextRun(maintenanceSettings.RetentionSchedule, true, now)
+	response.NextRepositoryCheckRun = formatNextRun(maintenanceSettings.RepositoryCheckSchedule, true, now)
+	return &response, nil
+}
+
+func formatNextRun(scheduleExpression string, isEnabled bool, now time.Time) string {
+	if !isEnabled {
+		return ""
+	}
+	schedule, err := tools.ParseCronSchedule(scheduleExpression)
+	if err != nil {
+		return ""
+	}
+	return schedule.Next(now).Format(time.RFC3339)
+}
+
+func (m MaintenanceSettings) schedules() map[settings.ConfigFieldKey]string {
+	return map[settings.ConfigFieldKey]string{
+		backupScheduleKeyword:          m.BackupSchedule,
+		updateScheduleKeyword:          m.UpdateSchedule,
+		retentionScheduleKeyword:       m.RetentionSchedule,
+		repositoryCheckScheduleKeyword: m.RepositoryCheckSchedule,
+	}
 }
 
 func AreMaintenanceSettingsInitialized() bool {
@@ -150,31 +551,63 @@ func GetMaintenanceSettings() (*MaintenanceSettings, error) {
 		return nil, err
 	}
 
-	preferredMaintenanceHourString, err := settings.ConfigsRepo.GetValue(preferredMaintenanceHourKeyword)
-	if err != nil {
-		return nil, err
+	defaultSchedule := getLegacyDefaultSchedule()
+	maintenanceSettings := MaintenanceSettings{
+		AreAutoBackupsEnabled:   enableAutoBackups == "true",
+		AreAutoUpdatesEnabled:   enableAutoUpdates == "true",
+		BackupSchedule:          getConfigValueOrDefault(backupScheduleKeyword, defaultSchedule),
+		UpdateSchedule:          getConfigValueOrDefault(updateScheduleKeyword, defaultSchedule),
+		RetentionSchedule:       getConfigValueOrDefault(retentionScheduleKeyword, defaultSchedule),
+		RepositoryCheckSchedule: getConfigValueOrDefault(repositoryCheckScheduleKeyword, defaultSchedule),
+		Timezone:                getConfigValueOrDefault(timezoneKeyword, defaultTimezone),
+		CatchUpJitterMinutes:    defaultCatchUpJitterMinutes,
 	}
-	preferredMaintenanceHour, err := strconv.Atoi(preferredMaintenanceHourString)
-	if err != nil {
-		Logger.Error("preferred maintenance hour '%s' could not be parsed %v", preferredMaintenanceHourString, err)
-		return nil, fmt.Errorf("preferred maintenance hour could not be parsed")
+	catchUpJitterMinutes, err := strconv.Atoi(getConfigValueOrDefault(catchUpJitterMinutesKeyword, ""))
+	if err == nil {
+		maintenanceSettings.CatchUpJitterMinutes = catchUpJitterMinutes
 	}
 
-	maintenanceSettings := MaintenanceSettings{
-		AreAutoBackupsEnabled:    enableAutoBackups == "true",
-		AreAutoUpdatesEnabled:    enableAutoUpdates == "true",
-		PreferredMaintenanceHour: preferredMaintenanceHour,
+	return &maintenanceSettings, nil
+}
+
+func getConfigValueOrDefault(keyword settings.ConfigFieldKey, defaultValue string) string {
+	value, err := settings.ConfigsRepo.GetValue(keyword)
+	if err != nil {
+		return defaultValue
 	}
+	return value
+}
 
-	return &maintenanceSettings, nil
+// Installations set up before schedules were introduced keep their maintenance time until they save new schedules.
+func getLegacyDefaultSchedule() string {
+	preferredMaintenanceHourString, err := settings.ConfigsRepo.GetValue(legacyPreferredMaintenanceHourKeyword)
+	if err != nil {
+		return defaultMaintenanceSchedule
+	}
+	preferredMaintenanceHour, err := strconv.Atoi(preferredMaintenanceHourString)
+	if err != nil || preferredMaintenanceHour < 0 || preferredMaintenanceHour > 23 {
+		return defaultMaintenanceSchedule
+	}
+	return fmt.Sprintf("0 %d * * *", preferredMaintenanceHour)
 }
 
 func SetMaintenanceSettings(maintenanceSettings MaintenanceSettings) error {
-	if maintenanceSettings.PreferredMaintenanceHour < 0 || maintenanceSettings.PreferredMaintenanceHour > 23 {
-		return errors.New(preferredMaintenanceHourOutOfRangeError)
+	schedules := maintenanceSettings.schedules()
+	for _, schedule := range schedules {
+		_, err := tools.ParseCronSchedule(schedule)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := time.LoadLocation(maintenanceSettings.Timezone)
+	if err != nil || maintenanceSettings.Timezone == "" || maintenanceSettings.Timezone == "Local" {
+		return fmt.Errorf("unknown timezone '%s'", maintenanceSettings.Timezone)
+	}
+	if maintenanceSettings.CatchUpJitterMinutes < 0 || maintenanceSettings.CatchUpJitterMinutes > maxCatchUpJitterMinutes {
+		return fmt.Errorf("catch-up jitter must be between 0 and %d minutes", maxCatchUpJitterMinutes)
 	}
 
-	err := settings.ConfigsRepo.SetConfigField(enableAutoBackupsKeyword, fmt.Sprintf("%v", maintenanceSettings.AreAutoBackupsEnabled))
+	err = settings.ConfigsRepo.SetConfigField(enableAutoBackupsKeyword, fmt.Sprintf("%v", maintenanceSettings.AreAutoBackupsEnabled))
 	if err != nil {
 		return err
 	}
@@ -184,7 +617,19 @@ func SetMaintenanceSettings(maintenanceSettings MaintenanceSettings) error {
 		return err
 	}
 
-	err = settings.ConfigsRepo.SetConfigField(preferredMaintenanceHourKeyword, fmt.Sprintf("%d", maintenanceSettings.PreferredMaintenanceHour))
+	for keyword, schedule := range schedules {
+		err = settings.ConfigsRepo.SetConfigField(keyword, schedule)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = settings.ConfigsRepo.SetConfigField(timezoneKeyword, maintenanceSettings.Timezone)
+	if err != nil {
+		return err
+	}
+
+	err = settings.ConfigsRepo.SetConfigField(catchUpJitterMinutesKeyword, strconv.Itoa(maintenanceSettings.CatchUpJitterMinutes))
 	if err != nil {
 		return err
 	}
@@ -199,26 +644,6 @@ func SetMaintenanceSettings(maintenanceSettings MaintenanceSettings) error {
 	return nil
 }
 
-func IsMaintenanceCycleDue(now, lastRun time.Time, preferredHour int) bool {
-	wasLastMaintenanceCycleExecutedToday := wasLastMaintenanceCycleExecutedToday(now, lastRun)
-	isWithinTimeRangeOfBeingExecutedToday := isWithinTimeRangeOfBeingExecutedToday(now, preferredHour)
-	return isMaintenanceCycleDue(wasLastMaintenanceCycleExecutedToday, isWithinTimeRangeOfBeingExecutedToday)
-}
-
-func isMaintenanceCycleDue(wasLastMaintenanceCycleExecutedToday, isWithinTimeRangeOfBeingExecutedToday bool) bool {
-	return !wasLastMaintenanceCycleExecutedToday && isWithinTimeRangeOfBeingExecutedToday
-}
-
-func isWithinTimeRangeOfBeingExecutedToday(now time.Time, preferredHour int) bool {
-	start := time.Date(now.Year(), now.Month(), now.Day(), preferredHour, 0, 0, 0, time.UTC)
-	end := start.Add(time.Hour)
-	return !now.Before(start) && now.Before(end)
-}
-
-func wasLastMaintenanceCycleExecutedToday(now, lastRun time.Time) bool {
-	return now.Year() == lastRun.Year() && now.YearDay() == lastRun.YearDay()
-}
-
 func FindBackupsForDeletionAccordingToRetentionPolicy(backups []tools.BackupInfo, keepDaily, keepWeekly, keepMonthly int) []tools.BackupInfo {
 	sort.Slice(backups, func(i, j int) bool {
 		return backups[i].BackupCreationTimestamp.After(backups[j].BackupCreationTimestamp)
@@ -269,8 +694,13 @@ func SetDefaultMaintenanceSettingsIfNotExisting() error {
 
 func GetDefaultMaintenanceSettings() MaintenanceSettings {
 	return MaintenanceSettings{
-		AreAutoBackupsEnabled:    true,
-		AreAutoUpdatesEnabled:    true,
-		PreferredMaintenanceHour: 4,
+		AreAutoBackupsEnabled:   true,
+		AreAutoUpdatesEnabled:   true,
+		BackupSchedule:          defaultMaintenanceSchedule,
+		UpdateSchedule:          defaultMaintenanceSchedule,
+		RetentionSchedule:       defaultMaintenanceSchedule,
+		RepositoryCheckSchedule: defaultMaintenanceSchedule,
+		Timezone:                defaultTimezone,
+		CatchUpJitterMinutes:    defaultCatchUpJitterMinutes,
 	}
 }
@@ -3,6 +3,8 @@ package backups
 import (
 	"github.com/ocelot-cloud/shared/assert"
 	"ocelot/backend/apps/common"
+	"ocelot/backend/clients"
+	"ocelot/backend/settings"
 	"ocelot/backend/tools"
 	"testing"
 	"time"
@@ -25,6 +27,113 @@ func TestSetLastMaintenanceCycleExecutionDate(t *testing.T) {
 	assert.Equal(t, someNewDate, *lastExecutionDate)
 }
 
+func TestMissedMaintenanceCyclesAreCaughtUpAfterJitter(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer WipeDatabaseForTesting()
+	clients.BackupManager = &clients.MockBackupManager{}
+	assert.Nil(t, SetDefaultMaintenanceSettingsIfNotExisting())
+	originalJitter := randomCatchUpJitter
+	defer func() {
+		randomCatchUpJitter = originalJitter
+		pendingCatchUpStart = time.Time{}
+	}()
+	randomCatchUpJitter = func(maxJitter time.Duration) time.Duration {
+		assert.Equal(t, time.Duration(defaultCatchUpJitterMinutes)*time.Minute, maxJitter)
+		return 10 * time.Minute
+	}
+
+	lastRun := time.Date(2025, 3, 8, 4, 0, 0, 0, time.UTC)
+	SetLastMaintenanceCycleExecutionDate(lastRun)
+	bootTime := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	conductDueMaintenanceTasks(bootTime)
+	conductDueMaintenanceTasks(bootTime.Add(9 * time.Minute))
+	lastExecutionDate, err := getLastMaintenanceCycleExecutionDate()
+	assert.Nil(t, err)
+	assert.Equal(t, lastRun, *lastExecutionDate)
+
+	catchUpTime := bootTime.Add(10 * time.Minute)
+	conductDueMaintenanceTasks(catchUpTime)
+	lastExecutionDate, err = getLastMaintenanceCycleExecutionDate()
+	assert.Nil(t, err)
+	assert.Equal(t, catchUpTime, *lastExecutionDate)
+	assert.True(t, pendingCatchUpStart.IsZero())
+
+	runs, err := MaintenanceRunRepo.ListRuns()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(runs))
+	assert.Equal(t, tools.MaintenanceRunStatusSucceeded, runs[0].Status)
+}
+
+func TestMaintenanceCycleInterruptedByShutdownIsNotSavedAsExecuted(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer WipeDatabaseForTesting()
+	clients.BackupManager = &clients.MockBackupManager{}
+	assert.Nil(t, SetDefaultMaintenanceSettingsIfNotExisting())
+	originalQueue := tools.AppOperationQueue
+	tools.AppOperationQueue = tools.ProvideAppOperationQueue()
+	defer func() { tools.AppOperationQueue = originalQueue }()
+
+	lastRun := time.Date(2025, 3, 9, 4, 0, 0, 0, time.UTC)
+	SetLastMaintenanceCycleExecutionDate(lastRun)
+	now := time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)
+	report, err := newMaintenanceReport(tools.MaintenanceRunTriggerSchedule, now)
+	assert.Nil(t, err)
+
+	tools.AppOperationQueue.Close()
+	conductMaintenanceCycle(now, allMaintenanceTasks, report)
+
+	lastExecutionDate, err := getLastMaintenanceCycleExecutionDate()
+	assert.Nil(t, err)
+	assert.Equal(t, lastRun, *lastExecutionDate)
+	run, err := MaintenanceRunRepo.GetRun(report.run.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.MaintenanceRunStatusInterrupted, run.Status)
+	assert.NotNil(t, run.FinishedAt)
+}
+
+func TestFirstStartIsNotTreatedAsMissedMaintenanceCycle(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer WipeDatabaseForTesting()
+	clients.BackupManager = &clients.MockBackupManager{}
+	assert.Nil(t, SetDefaultMaintenanceSettingsIfNotExisting())
+	defer func() { pendingCatchUpStart = time.Time{} }()
+
+	bootTime := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	conductDueMaintenanceTasks(bootTime)
+	lastExecutionDate, err := getLastMaintenanceCycleExecutionDate()
+	assert.Nil(t, err)
+	assert.Equal(t, bootTime, *lastExecutionDate)
+
+	conductDueMaintenanceTasks(bootTime.Add(time.Duration(defaultCatchUpJitterMinutes) * time.Minute))
+	assert.True(t, pendingCatchUpStart.IsZero())
+	runs, err := MaintenanceRunRepo.ListRuns()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(runs))
+
+	scheduledRunTime := time.Date(2025, 3, 11, 4, 0, 0, 0, time.UTC)
+	conductDueMaintenanceTasks(scheduledRunTime)
+	lastExecutionDate, err = getLastMaintenanceCycleExecutionDate()
+	assert.Nil(t, err)
+	assert.Equal(t, scheduledRunTime, *lastExecutionDate)
+}
+
+func TestMaintenanceAgentCanBeStoppedAndRestarted(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer WipeDatabaseForTesting()
+	assert.Nil(t, SetDefaultMaintenanceSettingsIfNotExisting())
+	SetLastMaintenanceCycleExecutionDate(time.Now().UTC())
+
+	StopMaintenanceAgent()
+	StartMaintenanceAgentLoop()
+	StartMaintenanceAgentLoop()
+	StopMaintenanceAgent()
+	assert.Nil(t, stopMaintenanceAgentChannel)
+	StartMaintenanceAgentLoop()
+	StopMaintenanceAgent()
+	assert.Nil(t, stopMaintenanceAgentChannel)
+}
+
 func TestFindRetentionCandidates(t *testing.T) {
 	backups := []tools.BackupInfo{
 		{
@@ -106,16 +215,20 @@ func TestManagingMaintenanceSettings(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, true, settings.AreAutoBackupsEnabled)
 	assert.Equal(t, true, settings.AreAutoUpdatesEnabled)
-	assert.Equal(t, 4, settings.PreferredMaintenanceHour)
+	assert.Equal(t, GetDefaultMaintenanceSettings(), *settings)
 
 	lastExecutionDate, err := getLastMaintenanceCycleExecutionDate()
 	assert.Nil(t, err)
 	assert.Equal(t, UnixEpochStartTime, *lastExecutionDate)
 
 	customMaintenanceSettings := MaintenanceSettings{
-		AreAutoBackupsEnabled:    false,
-		AreAutoUpdatesEnabled:    false,
-		PreferredMaintenanceHour: 2,
+		AreAutoBackupsEnabled:   false,
+		AreAutoUpdatesEnabled:   false,
+		BackupSchedule:          "0 */6 * * *",
+		UpdateSchedule:          "0 3 * * 0",
+		RetentionSchedule:       "30 4 * * *",
+		RepositoryCheckSchedule: "0 5 1 * *",
+		Timezone:                "Europe/Berlin",
 	}
 	assert.Nil(t, SetMaintenanceSettings(customMaintenanceSettings))
 
@@ -124,27 +237,45 @@ func TestManagingMaintenanceSettings(t *testing.T) {
 	settingsFromDatabase, err := GetMaintenanceSettings()
 	assert.Nil(t, err)
 
-	assert.Equal(t, customMaintenanceSettings.AreAutoBackupsEnabled, settingsFromDatabase.AreAutoBackupsEnabled)
-	assert.Equal(t, customMaintenanceSettings.AreAutoUpdatesEnabled, settingsFromDatabase.AreAutoUpdatesEnabled)
-	assert.Equal(t, customMaintenanceSettings.PreferredMaintenanceHour, settingsFromDatabase.PreferredMaintenanceHour)
+	assert.Equal(t, customMaintenanceSettings, *settingsFromDatabase)
 }
 
-func TestPreferredMaintenanceHourValidityRange(t *testing.T) {
+func TestInvalidMaintenanceSchedulesAreRejected(t *testing.T) {
 	common.InitializeDatabase(false, false)
 	defer WipeDatabaseForTesting()
 	maintenanceSettings := GetDefaultMaintenanceSettings()
 
-	maintenanceSettings.PreferredMaintenanceHour = -1
+	maintenanceSettings.BackupSchedule = "0 24 * * *"
 	assert.NotNil(t, SetMaintenanceSettings(maintenanceSettings))
 
-	maintenanceSettings.PreferredMaintenanceHour = 0
+	maintenanceSettings.BackupSchedule = "0 23 * * *"
 	assert.Nil(t, SetMaintenanceSettings(maintenanceSettings))
 
-	maintenanceSettings.PreferredMaintenanceHour = 23
-	assert.Nil(t, SetMaintenanceSettings(maintenanceSettings))
+	maintenanceSettings.UpdateSchedule = "0 0 31 2 *"
+	assert.NotNil(t, SetMaintenanceSettings(maintenanceSettings))
+	maintenanceSettings.UpdateSchedule = "0 4 * * *"
 
-	maintenanceSettings.PreferredMaintenanceHour = 24
+	maintenanceSettings.Timezone = "Invalid/Zone"
 	assert.NotNil(t, SetMaintenanceSettings(maintenanceSettings))
+	maintenanceSettings.Timezone = "Local"
+	assert.NotNil(t, SetMaintenanceSettings(maintenanceSettings))
+	maintenanceSettings.Timezone = "Asia/Tokyo"
+	assert.Nil(t, SetMaintenanceSettings(maintenanceSettings))
+}
+
+func TestSchedulesOfLegacyInstallationsAreDerivedFromPreferredMaintenanceHour(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer WipeDatabaseForTesting()
+	_, err := common.DB.Exec("DELETE FROM configs")
+	assert.Nil(t, err)
+	assert.Nil(t, settings.ConfigsRepo.SetConfigField(enableAutoBackupsKeyword, "true"))
+	assert.Nil(t, settings.ConfigsRepo.SetConfigField(enableAutoUpdatesKeyword, "true"))
+	assert.Nil(t, settings.ConfigsRepo.SetConfigField(legacyPreferredMaintenanceHourKeyword, "7"))
+
+	maintenanceSettings, err := GetMaintenanceSettings()
+	assert.Nil(t, err)
+	assert.Equal(t, "0 7 * * *", maintenanceSettings.BackupSchedule)
+	assert.Equal(t, "0 7 * * *", maintenanceSettings.RepositoryCheckSchedule)
 }
 
 func TestGetLastMaintenanceCycleExecutionDate(t *testing.T) {
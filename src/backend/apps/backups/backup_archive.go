@@ -0,0 +1,219 @@
+package backups
+
+import (
+	"archive/tar"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ocelot-cloud/shared/utils"
+	"github.com/ocelot-cloud/shared/validation"
+	"io"
+	"net/http"
+	"ocelot/backend/tools"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// An exported archive contains the metadata of the snapshot as length-prefixed JSON, followed by a tar file of the snapshot content.
+const maxArchiveMetadataSize = 64 * 1024
+
+func (b *RealBackupManager) ExportBackup(w http.ResponseWriter, request tools.BackupExportRequest) error {
+	envs, err := prepareResticOperationAndReturnCommandEnvs(request.RepositoryId)
+	if err != nil {
+		return err
+	}
+	info, err := getBackupInfo(request.BackupId, envs)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(BackupArchiveMetadata{
+		Maintainer:               info.Maintainer,
+		AppName:                  info.AppName,
+		VersionName:              info.VersionName,
+		VersionCreationTimestamp: info.VersionCreationTimestamp.Format(time.RFC3339),
+		Description:              string(info.Description),
+		BackupCreationTimestamp:  info.BackupCreationTimestamp.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s_%s_%s.ocelotbackup", info.Maintainer, info.AppName, info.BackupCreationTimestamp.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+
+	encrypter, err := newArchiveEncrypter(w, request.Password)
+	if err != nil {
+		return err
+	}
+	metadataLength := make([]byte, 4)
+	binary.BigEndian.PutUint32(metadataLength, uint32(len(metadata))) // #nosec G115 (CWE-190): metadata is small
+	if _, err = encrypter.Write(append(metadataLength, metadata...)); err != nil {
+		return err
+	}
+	err = streamFromResticContainer("restic dump --archive tar "+request.BackupId+" /", envs, encrypter)
+	if err != nil {
+		return err
+	}
+	return encrypter.Close()
+}
+
+// ImportBackup stores the snapshot of an exported archive as new snapshot in the local repository, so it can be restored afterward.
+func (b *RealBackupManager) ImportBackup(archive io.Reader, password string) error {
+	decrypter, err := newArchiveDecrypter(archive, password)
+	if err != nil {
+		return err
+	}
+	metadata, err := readArchiveMetadata(decrypter)
+	if err != nil {
+		return err
+	}
+	backupCreationTimestamp, err := time.Parse(time.RFC3339, metadata.BackupCreationTimestamp)
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp(tools.TempDir, "backup-import")
+	if err != nil {
+		return err
+	}
+	defer utils.RemoveDir(tempDir)
+
+	snapshotFile, err := os.Create(tempDir + "/snapshot.tar") // #nosec G304 (CWE-22): Potential file inclusion via variable; is okay, since path is generated internally
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(snapshotFile, decrypter)
+	utils.Close(snapshotFile)
+	if err != nil {
+		return err
+	}
+
+	if err = validateSnapshotArchive(tempDir + "/snapshot.tar"); err != nil {
+		return err
+	}
+	// the archive is unpacked into an empty directory, which is mounted as source directory of the snapshot, so that it
+	// can not modify the repository, the ssh key or other files of the restic container
+	contentDir := tempDir + "/content"
+	if err = os.MkdirAll(contentDir+"/source", 0700); err != nil {
+		return err
+	}
+
+	envs, err := prepareResticOperationAndReturnCommandEnvs(tools.LocalBackupRepositoryId)
+	if err != nil {
+		return err
+	}
+	resticTags := []string{
+		"maintainer=" + metadata.Maintainer,
+		"app=" + metadata.AppName,
+		"version=" + metadata.VersionName,
+		"version_creation_timestamp=" + metadata.VersionCreationTimestamp,
+		"description=" + metadata.Description,
+	}
+	command := fmt.Sprintf("tar -xf /import/snapshot.tar -C /import/content && restic backup --json --time '%s' /source", backupCreationTimestamp.UTC().Format("2006-01-02 15:04:05"))
+	output, err := executeInResticContainer(command, nil, resticTags, envs, "-v "+tempDir+":/import -v "+contentDir+"/source:/source ")
+	if err != nil {
+		return err
+	}
+	saveStatisticsFromResticBackupOutput(output)
+	return nil
+}
+
+// validateSnapshotArchive ensures that the tar file only contains directories, regular files and symlinks below "source",
+// since it is unpacked as root. Symlinks must point to a relative target within "source" and no entry may be placed below
+// a symlink, so that unpacking can not write files outside the target directory.
+func validateSnapshotArchive(path string) error {
+	file, err := os.Open(path) // #nosec G304 (CWE-22): Potential file inclusion via variable; is okay, since path is generated internally
+	if err != nil {
+		return err
+	}
+	defer utils.Close(file)
+
+	reader := tar.NewReader(file)
+	symlinks := map[string]bool{}
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("invalid snapshot archive: %w", err)
+		}
+		if err = validateSnapshotArchiveEntry(header); err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(header.Name, "/")
+		for parent := filepath.Dir(name); parent != "."; parent = filepath.Dir(parent) {
+			if symlinks[parent] {
+				return fmt.Errorf("snapshot archive contains entry '%s' below a symlink", header.Name)
+			}
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			symlinks[name] = true
+		}
+	}
+}
+
+func validateSnapshotArchiveEntry(header *tar.Header) error {
+	name := strings.TrimSuffix(header.Name, "/")
+	if strings.HasPrefix(name, "/") {
+		return fmt.Errorf("snapshot archive contains absolute path '%s'", header.Name)
+	}
+	for _, component := range strings.Split(name, "/") {
+		if component == ".." {
+			return fmt.Errorf("snapshot archive contains parent directory reference in '%s'", header.Name)
+		}
+	}
+	if name != "source" && !strings.HasPrefix(name, "source/") {
+		return fmt.Errorf("snapshot archive contains entry '%s' outside of the source directory", header.Name)
+	}
+	switch header.Typeflag {
+	case tar.TypeDir, tar.TypeReg:
+		return nil
+	case tar.TypeSymlink:
+		return validateSymlinkTarget(name, header.Linkname)
+	default:
+		return fmt.Errorf("snapshot archive contains entry '%s' which is neither a directory, a regular file nor a symlink", header.Name)
+	}
+}
+
+// Apps commonly contain relative symlinks, e.g. "current -> releases/1.0", so only targets leaving "source" are rejected.
+func validateSymlinkTarget(name, target string) error {
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("snapshot archive contains symlink '%s' with absolute target '%s'", name, target)
+	}
+	resolvedTarget := filepath.Join(filepath.Dir(name), target)
+	if resolvedTarget != "source" && !strings.HasPrefix(resolvedTarget, "source/") {
+		return fmt.Errorf("snapshot archive contains symlink '%s' with target '%s' outside of the source directory", name, target)
+	}
+	return nil
+}
+
+// The metadata is used to build the restic command, so it must be validated like any other user input.
+func readArchiveMetadata(reader io.Reader) (*BackupArchiveMetadata, error) {
+	metadataLength := make([]byte, 4)
+	if _, err := io.ReadFull(reader, metadataLength); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(metadataLength)
+	if length > maxArchiveMetadataSize {
+		return nil, fmt.Errorf("archive metadata is too large")
+	}
+	metadataBytes := make([]byte, length)
+	if _, err := io.ReadFull(reader, metadataBytes); err != nil {
+		return nil, err
+	}
+
+	var metadata BackupArchiveMetadata
+	if err := json.Unmarshal(metadataBytes, &metadata); err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateStruct(metadata); err != nil {
+		Logger.Warn("invalid archive metadata: %v", err)
+		return nil, fmt.Errorf("invalid archive metadata")
+	}
+	return &metadata, nil
+}
@@ -0,0 +1,61 @@
+package backups
+
+import (
+	"archive/tar"
+	"github.com/ocelot-cloud/shared/assert"
+	"os"
+	"testing"
+)
+
+func writeSnapshotArchive(t *testing.T, headers ...*tar.Header) string {
+	path := t.TempDir() + "/snapshot.tar"
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	writer := tar.NewWriter(file)
+	for _, header := range headers {
+		assert.Nil(t, writer.WriteHeader(header))
+		if header.Size > 0 {
+			_, err = writer.Write(make([]byte, header.Size))
+			assert.Nil(t, err)
+		}
+	}
+	assert.Nil(t, writer.Close())
+	assert.Nil(t, file.Close())
+	return path
+}
+
+func TestValidateSnapshotArchive(t *testing.T) {
+	validEntries := []*tar.Header{
+		{Name: "source/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "source/maintainer_app_data/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "source/maintainer_app_data/file.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 3},
+		{Name: "source/maintainer_app_data/current", Typeflag: tar.TypeSymlink, Linkname: "releases/1.0"},
+		{Name: "source/maintainer_app_data/node_modules/.bin/tool", Typeflag: tar.TypeSymlink, Linkname: "../tool/bin.js"},
+	}
+	assert.Nil(t, validateSnapshotArchive(writeSnapshotArchive(t, validEntries...)))
+
+	invalidEntries := []*tar.Header{
+		{Name: "/backups/config", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "source/../backups/config", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "root/.ssh/id_ed25519", Typeflag: tar.TypeReg, Mode: 0600},
+		{Name: "sourcecode/file.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "source/link", Typeflag: tar.TypeSymlink, Linkname: "/backups"},
+		{Name: "source/link", Typeflag: tar.TypeSymlink, Linkname: "../backups"},
+		{Name: "source/maintainer_app_data/link", Typeflag: tar.TypeSymlink, Linkname: "../../root/.ssh"},
+		{Name: "source/hardlink", Typeflag: tar.TypeLink, Linkname: "source/maintainer_app_data/file.txt"},
+		{Name: "source/device", Typeflag: tar.TypeChar, Mode: 0600},
+	}
+	for _, invalidEntry := range invalidEntries {
+		path := writeSnapshotArchive(t, append(validEntries[:2:2], invalidEntry)...)
+		assert.NotNil(t, validateSnapshotArchive(path))
+	}
+}
+
+func TestSnapshotArchiveEntriesBelowSymlinksAreRejected(t *testing.T) {
+	path := writeSnapshotArchive(t,
+		&tar.Header{Name: "source/", Typeflag: tar.TypeDir, Mode: 0755},
+		&tar.Header{Name: "source/data", Typeflag: tar.TypeSymlink, Linkname: "other"},
+		&tar.Header{Name: "source/data/file.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 3},
+	)
+	assert.NotNil(t, validateSnapshotArchive(path))
+}
@@ -1,6 +1,7 @@
 package backups
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"ocelot/backend/apps/cloud"
 	"ocelot/backend/apps/common"
 	"ocelot/backend/clients"
+	"ocelot/backend/repositories"
 	"ocelot/backend/ssh"
 	"ocelot/backend/tools"
 	"os"
@@ -19,32 +21,53 @@ import (
 	"time"
 )
 
-var (
-	numberOfDailyBackupsToKeep   = 7
-	numberOfWeeklyBackupsToKeep  = 4
-	numberOfMonthlyBackupsToKeep = 12
-)
-
 // for developers: set to true to print command output to console
 const showCommandOutput = false
 
 type RealBackupManager struct{}
 
 func (b *RealBackupManager) CreateBackup(appId int, description tools.BackupDescription) error {
+	return b.CreateBackupWithProgress(appId, description, nil)
+}
+
+func (b *RealBackupManager) CreateBackupWithProgress(appId int, description tools.BackupDescription, progress tools.ProgressReporter) error {
+	enabledRepositories, err := repositories.BackupRepositoryRepo.ListEnabledRepositories()
+	if err != nil {
+		return err
+	}
+	if len(enabledRepositories) == 0 {
+		return fmt.Errorf("no backup repository is enabled")
+	}
+	var repositoryIds []int
+	for _, repository := range enabledRepositories {
+		repositoryIds = append(repositoryIds, repository.Id)
+	}
+	return b.createBackupInRepositories(appId, description, repositoryIds, progress)
+}
+
+func (b *RealBackupManager) CreateBackupInRepositories(appId int, description tools.BackupDescription, repositoryIds []int) error {
+	return b.createBackupInRepositories(appId, description, repositoryIds, nil)
+}
+
+func (b *RealBackupManager) createBackupInRepositories(appId int, description tools.BackupDescription, repositoryIds []int, progress tools.ProgressReporter) error {
 	defer cloud.UpdateAppConfigs()
-	err := b.createBackupAtLocation(appId, description, true)
+	if len(repositoryIds) == 0 {
+		return fmt.Errorf("no backup repository selected")
+	}
+	primaryRepositoryId, replicaRepositoryIds := selectPrimaryRepository(repositoryIds)
+	err := checkTransferWindow(primaryRepositoryId, tools.Clock.Now())
 	if err != nil {
 		return err
 	}
-	isRemoteBackupEnabled, err := ssh.IsRemoteBackupEnabled()
+	snapshotId, err := b.createBackupAtLocation(appId, description, primaryRepositoryId, progress)
 	if err != nil {
 		return err
 	}
-	if isRemoteBackupEnabled {
-		return b.createBackupAtLocation(appId, description, false)
-	} else {
-		return nil
+	if snapshotId == "" && len(replicaRepositoryIds) > 0 {
+		return fmt.Errorf("backup was created, but could not be replicated to all selected backup repositories")
 	}
+	replicateSnapshotInBackground(appId, snapshotId, primaryRepositoryId, replicaRepositoryIds)
+	return nil
 }
 
 var (
@@ -76,15 +99,15 @@ func getBackupCreationDto(appId int, description tools.BackupDescription) (Backu
 	return backupCreation, nil
 }
 
-func (b *RealBackupManager) createBackupAtLocation(appId int, description tools.BackupDescription, isLocalBackup bool) error {
+func (b *RealBackupManager) createBackupAtLocation(appId int, description tools.BackupDescription, repositoryId int, progress tools.ProgressReporter) (string, error) {
 	backupCreationDto, err := getBackupCreationDto(appId, description)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	volumes, err := extractVolumesFromZipsDockerComposeYaml(backupCreationDto.VersionZipContent)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	resticTags := []string{
@@ -97,28 +120,37 @@ func (b *RealBackupManager) createBackupAtLocation(appId int, description tools.
 
 	app, err := common.AppRepo.GetApp(appId)
 	if err != nil {
-		return err
+		return "", err
 	}
-	envs, err := prepareResticOperationAndReturnCommandEnvs(isLocalBackup)
+	envs, err := prepareResticOperationAndReturnCommandEnvs(repositoryId)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	err = clients.Apps.StopApp(appId)
+	appConfig, err := cloud.ReadAppConfigFromVersionContent(backupCreationDto.VersionZipContent)
 	if err != nil {
-		return err
+		return "", err
+	}
+	var snapshotId string
+	if isOnlineBackupPossible(*app, *appConfig) {
+		Logger.Info("creating backup of app %s via its backup hooks without stopping it", app.AppName)
+		err = createBackupUsingHooks(appId, *appConfig, func() error {
+			snapshotId, err = runResticBackup(backupCreationDto, volumes, resticTags, envs, progress)
+			return err
+		})
+		return snapshotId, err
 	}
 
-	tempDir, zipName, err := createZipFile(backupCreationDto)
+	tools.AppsUnderMaintenance.Begin(app.AppName, tools.AppMaintenanceReasonBackup)
+	defer tools.AppsUnderMaintenance.End(app.AppName, tools.AppMaintenanceReasonBackup)
+	err = clients.Apps.StopApp(appId)
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer utils.RemoveDir(tempDir)
-	zipFileMountVolume := fmt.Sprintf("-v %s/%s:/source/%s ", tempDir, zipName, zipName)
 
-	_, err = executeInResticContainer("restic backup /source", volumes, resticTags, envs, zipFileMountVolume)
+	snapshotId, err = runResticBackup(backupCreationDto, volumes, resticTags, envs, progress)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if common.IsOcelotDbApp(*app) {
@@ -126,54 +158,182 @@ func (b *RealBackupManager) createBackupAtLocation(appId int, description tools.
 		err = common.AppRepo.SetAppShouldBeRunning(appId, true)
 		if err != nil {
 			Logger.Error("Error setting postgres app should be running")
-			return err
+			return "", err
 		}
 	} else {
 		err = clients.Apps.StartApp(appId)
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 
-	return nil
+	return snapshotId, nil
 }
 
-func prepareResticOperationAndReturnCommandEnvs(isLocalBackup bool) ([]string, error) {
-	if isLocalBackup {
-		_, err := executeInResticContainer("restic check || restic init", nil, nil, localBackupResticCommandEnvs, "")
-		if err != nil {
-			return nil, err
-		}
-		return localBackupResticCommandEnvs, nil
-	} else {
-		repository, err := ssh.GetRemoteBackupRepository()
-		if err != nil {
-			return nil, err
-		}
-		knownHostsFileLocation := "/root/.ssh/known_hosts"
-		rcloneSetupCmd := fmt.Sprintf("rclone config create myssh sftp host=%s user=%s pass=%s port=%s known_hosts_file=%s use_insecure_cipher=false", repository.Host, repository.SshUser, repository.SshPassword, repository.SshPort, knownHostsFileLocation)
-		_, err = executeInResticContainer(rcloneSetupCmd, nil, nil, nil, "")
+func runResticBackup(backupCreationDto BackupCreationDto, volumes, resticTags []string, envs resticEnvironment, progress tools.ProgressReporter) (string, error) {
+	tempDir, zipName, err := createZipFile(backupCreationDto)
+	if err != nil {
+		return "", err
+	}
+	defer utils.RemoveDir(tempDir)
+	zipFileMountVolume := fmt.Sprintf("-v %s/%s:/source/%s ", tempDir, zipName, zipName)
+
+	output, err := executeInResticContainerWithProgress("restic backup --json /source", volumes, resticTags, envs, zipFileMountVolume, progress)
+	if err != nil {
+		return "", err
+	}
+	return saveStatisticsFromResticBackupOutput(output), nil
+}
+
+// Hooks can only be executed in running containers. Stopped apps do not need them anyway, since their data is consistent.
+func isOnlineBackupPossible(app tools.RepoApp, appConfig cloud.AppConfig) bool {
+	return app.ShouldBeRunning && appConfig.HasBackupHooks() && !common.IsOcelotDbApp(app)
+}
+
+func createBackupUsingHooks(appId int, appConfig cloud.AppConfig, backup func() error) error {
+	err := runBackupHooks(appId, appConfig.PreBackupHooks)
+	if err == nil {
+		err = backup()
+	}
+	// post-backup hooks usually release locks acquired by the pre-backup hooks, so they must run in any case
+	postBackupHooksErr := runBackupHooks(appId, appConfig.PostBackupHooks)
+	if err != nil {
+		return err
+	}
+	return postBackupHooksErr
+}
+
+func runBackupHooks(appId int, hooks []cloud.BackupHook) error {
+	for _, hook := range hooks {
+		Logger.Debug("running backup hook in service '%s' of app with id %d", hook.Service, appId)
+		err := clients.Apps.RunCommandInAppService(appId, hook.Service, hook.Command)
 		if err != nil {
-			return nil, err
+			Logger.Error("backup hook in service '%s' failed: %v", hook.Service, err)
+			return fmt.Errorf("backup hook in service '%s' failed", hook.Service)
 		}
+	}
+	return nil
+}
 
-		knownHostsSetupCmd := fmt.Sprintf("printf '%s' > %s", repository.SshKnownHosts, knownHostsFileLocation)
-		_, err = executeInResticContainer(knownHostsSetupCmd, nil, nil, nil, "")
+func prepareResticOperationAndReturnCommandEnvs(repositoryId int) (resticEnvironment, error) {
+	return prepareResticOperationWithRcloneRemote(repositoryId, fmt.Sprintf("repo%d", repositoryId))
+}
+
+// The rclone remote of an sftp repository is recreated for every operation. Background operations pass their own remote
+// name, so that they do not replace the remote while a foreground operation uses it.
+func prepareResticOperationWithRcloneRemote(repositoryId int, rcloneRemoteName string) (resticEnvironment, error) {
+	repository, err := repositories.BackupRepositoryRepo.GetRepository(repositoryId)
+	if err != nil {
+		return resticEnvironment{}, err
+	}
+
+	envs := resticEnvironment{limits: getBandwidthLimits(*repository)}
+	switch {
+	case repository.IsLocal():
+		envs = resticEnvironment{variables: localBackupResticCommandEnvs}
+	case repository.IsS3():
+		envs.variables = getS3ResticCommandEnvs(*repository)
+	default:
+		envs.variables, err = prepareSftpRepositoryAndReturnCommandEnvs(*repository, rcloneRemoteName)
 		if err != nil {
-			return nil, err
+			return resticEnvironment{}, err
 		}
+	}
 
-		envs := []string{
-			"RESTIC_REPOSITORY=rclone:myssh:backups",
-			"RESTIC_PASSWORD=" + repository.EncryptionPassword,
-		}
-		_, err = executeInResticContainer("restic check || restic init", nil, nil, envs, "")
+	// restic exits with code 10 if the repository does not exist, so that a wrong password or an unreachable host is not
+	// mistaken for a missing repository. The bandwidth limits are left out, since the command does not end with a restic
+	// invocation and hardly transfers any data.
+	output, err := executeInResticContainer(`restic cat config > /dev/null || { [ \$? -eq 10 ] && restic init; }`, nil, nil, resticEnvironment{variables: envs.variables}, "")
+	if err != nil {
+		return resticEnvironment{}, fmt.Errorf("%v: %s", err, strings.TrimSpace(output))
+	}
+	err = repositories.BackupRepositoryRepo.SetRepositoryInitialized(repositoryId, true)
+	if err != nil {
+		return resticEnvironment{}, err
+	}
+	return envs, nil
+}
+
+// Each sftp repository gets its own rclone remote and known_hosts file, so that several remote hosts can be used side by side.
+func prepareSftpRepositoryAndReturnCommandEnvs(repository tools.BackupRepository, rcloneRemoteName string) ([]string, error) {
+	knownHostsFileLocation := getKnownHostsFileLocation(rcloneRemoteName)
+	authentication := "pass=" + repository.SshPassword
+	if repository.UsesSshKey() {
+		err := writeSshPrivateKeyToResticContainer()
 		if err != nil {
 			return nil, err
 		}
+		authentication = "key_file=" + sshPrivateKeyFileLocation
+	}
+
+	// the remote is deleted first, so that no password is left over after switching to key authentication
+	rcloneSetupCmd := fmt.Sprintf("rclone config delete %s; rclone config create %s sftp host=%s user=%s %s port=%s known_hosts_file=%s use_insecure_cipher=false", rcloneRemoteName, rcloneRemoteName, repository.Host, repository.SshUser, authentication, repository.SshPort, knownHostsFileLocation)
+	_, err := executeInResticContainer(rcloneSetupCmd, nil, nil, resticEnvironment{}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	knownHostsSetupCmd := fmt.Sprintf("printf '%s' > %s", repository.SshKnownHosts, knownHostsFileLocation)
+	_, err = executeInResticContainer(knownHostsSetupCmd, nil, nil, resticEnvironment{}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		"RESTIC_REPOSITORY=rclone:" + rcloneRemoteName + ":backups",
+		"RESTIC_PASSWORD=" + repository.EncryptionPassword,
+	}, nil
+}
+
+func getKnownHostsFileLocation(rcloneRemoteName string) string {
+	return "/root/.ssh/known_hosts_" + rcloneRemoteName
+}
+
+// removeRcloneRemote deletes a remote which was only created for a single background operation.
+func removeRcloneRemote(rcloneRemoteName string) {
+	_, err := executeInResticContainer(fmt.Sprintf("rclone config delete %s; rm -f %s", rcloneRemoteName, getKnownHostsFileLocation(rcloneRemoteName)), nil, nil, resticEnvironment{}, "")
+	if err != nil {
+		Logger.Warn("failed to remove rclone remote %s: %v", rcloneRemoteName, err)
+	}
+}
 
-		return envs, nil
+const sshPrivateKeyFileLocation = "/root/.ssh/ocelot_ed25519"
+
+func writeSshPrivateKeyToResticContainer() error {
+	privateKey, err := ssh.LoadOrCreateSshPrivateKey()
+	if err != nil {
+		return err
+	}
+	// the key is passed via a mounted file, since command lines are visible in the process list and in logs
+	tempDir, err := os.MkdirTemp(tools.TempDir, "ssh-key")
+	if err != nil {
+		return err
 	}
+	defer utils.RemoveDir(tempDir)
+	if err = os.WriteFile(tempDir+"/key", []byte(privateKey), 0600); err != nil {
+		return err
+	}
+	// the key file is replaced atomically, since background operations may read it at the same time
+	keySetupCmd := fmt.Sprintf(`cp /ssh-key/key %[1]s.\$\$ && chmod 600 %[1]s.\$\$ && mv %[1]s.\$\$ %[1]s`, sshPrivateKeyFileLocation)
+	_, err = executeInResticContainer(keySetupCmd, nil, nil, resticEnvironment{}, "-v "+tempDir+":/ssh-key:ro ")
+	return err
+}
+
+func getS3ResticCommandEnvs(repository tools.BackupRepository) []string {
+	resticRepository := "s3:" + repository.S3Endpoint + "/" + repository.S3Bucket
+	if repository.S3Prefix != "" {
+		resticRepository += "/" + repository.S3Prefix
+	}
+	envs := []string{
+		"RESTIC_REPOSITORY=" + resticRepository,
+		"RESTIC_PASSWORD=" + repository.EncryptionPassword,
+		"AWS_ACCESS_KEY_ID=" + repository.S3AccessKey,
+		"AWS_SECRET_ACCESS_KEY=" + repository.S3SecretKey,
+	}
+	if repository.S3Region != "" {
+		envs = append(envs, "AWS_DEFAULT_REGION="+repository.S3Region)
+	}
+	return envs
 }
 
 func createZipFile(backup BackupCreationDto) (string, string, error) {
@@ -190,23 +350,69 @@ func createZipFile(backup BackupCreationDto) (string, string, error) {
 	return tempDir, fileName, nil
 }
 
-func executeInResticContainer(command string, appVolumes, resticTags, envs []string, mountVolume string) (string, error) {
-	resticTagsFlags := ""
+func executeInResticContainer(command string, appVolumes, resticTags []string, envs resticEnvironment, mountVolume string) (string, error) {
+	return runCommandWithOutputString(buildResticContainerCommand(command, appVolumes, resticTags, envs, mountVolume))
+}
+
+// With the --json flag, restic prints status messages while running, which are forwarded to the progress reporter.
+func executeInResticContainerWithProgress(command string, appVolumes, resticTags []string, envs resticEnvironment, mountVolume string, progress tools.ProgressReporter) (string, error) {
+	if progress == nil {
+		return executeInResticContainer(command, appVolumes, resticTags, envs, mountVolume)
+	}
+	var outputBuffer bytes.Buffer
+	reader, writer := io.Pipe()
+	cmd := exec.Command("sh", "-c", buildResticContainerCommand(command, appVolumes, resticTags, envs, mountVolume)) // #nosec G204 (CWE-78): Execution as root with variables in subprocess is required by design
+	cmd.Stdout = io.MultiWriter(&outputBuffer, writer)
+	cmd.Stderr = &outputBuffer
+
+	scanningDone := make(chan struct{})
+	go func() {
+		defer close(scanningDone)
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if status, ok := parseResticStatus(scanner.Text()); ok {
+				progress.Report(status.toJobProgress())
+			}
+		}
+		// drains the pipe in case of overly long lines, so that the command is not blocked
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+
+	err := cmd.Run()
+	_ = writer.Close()
+	<-scanningDone
+	return outputBuffer.String(), err
+}
+
+func streamFromResticContainer(command string, envs resticEnvironment, writer io.Writer) error {
+	var errorOutput bytes.Buffer
+	cmd := exec.Command("sh", "-c", buildResticContainerCommand(command, nil, nil, envs, "")) // #nosec G204 (CWE-78): Execution as root with variables in subprocess is required by design
+	cmd.Stdout = writer
+	cmd.Stderr = &errorOutput
+	err := cmd.Run()
+	if err != nil {
+		Logger.Error("streaming from restic container failed: %s", errorOutput.String())
+	}
+	return err
+}
+
+// Like the tags, the bandwidth limits are appended to the command, so it must end with the restic invocation they apply to.
+func buildResticContainerCommand(command string, appVolumes, resticTags []string, envs resticEnvironment, mountVolume string) string {
+	resticFlags := envs.limits.toResticFlags()
 	for _, tag := range resticTags {
-		resticTagsFlags += `--tag ` + tag + ` `
+		resticFlags += `--tag ` + tag + ` `
 	}
 	volumeFlags := ""
 	for _, volume := range appVolumes {
 		volumeFlags += `-v ` + volume + `:/source/` + volume + ` `
 	}
 	envFlags := ""
-	for _, env := range envs {
+	for _, env := range envs.variables {
 		envFlags += `-e ` + env + ` `
 	}
 
 	// the "--network host" is only needed for testing during development
-	wholeCommand := fmt.Sprintf(`docker run --rm --network host %s-v %s:%s %s%s--entrypoint "" -v restic_rclone:/root/.config/rclone -v restic_ssh:/root/.ssh restic:local sh -c "%s %s"`, mountVolume, backupDockerVolumeName, backupRepositoryPathInResticContainer, volumeFlags, envFlags, command, resticTagsFlags)
-	return runCommandWithOutputString(wholeCommand)
+	return fmt.Sprintf(`docker run --rm --network host %s-v %s:%s %s%s--entrypoint "" -v restic_rclone:/root/.config/rclone -v restic_ssh:/root/.ssh restic:local sh -c "%s %s"`, mountVolume, backupDockerVolumeName, backupRepositoryPathInResticContainer, volumeFlags, envFlags, command, resticFlags)
 }
 
 func extractVolumesFromZipsDockerComposeYaml(zipContent []byte) ([]string, error) {
@@ -240,32 +446,46 @@ func (b *RealBackupManager) ListBackupsOfApp(backupListRequest tools.BackupListR
 		"app=" + backupListRequest.AppName,
 	}
 
-	backupInfos, err := b.getBackupsMatchingTags(backupListRequest.IsLocal, resticTagFilters)
+	backupInfos, err := b.getBackupsMatchingTags(backupListRequest.RepositoryId, resticTagFilters)
 	if err != nil {
 		return nil, err
 	}
 
 	var filteredBackupInfos []tools.BackupInfo
+	var snapshotIds []string
 	for _, backupInfo := range backupInfos {
 		if backupInfo.Maintainer == backupListRequest.Maintainer && backupInfo.AppName == backupListRequest.AppName {
 			filteredBackupInfos = append(filteredBackupInfos, backupInfo)
+			snapshotIds = append(snapshotIds, backupInfo.BackupId)
 		}
 	}
+	if len(filteredBackupInfos) == 0 {
+		return filteredBackupInfos, nil
+	}
 
+	statisticsBySnapshotId, err := BackupStatisticsRepo.GetStatisticsOfSnapshots(snapshotIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range filteredBackupInfos {
+		if statistics, ok := statisticsBySnapshotId[filteredBackupInfos[i].BackupId]; ok {
+			filteredBackupInfos[i].Statistics = &statistics
+		}
+	}
 	return filteredBackupInfos, nil
 }
 
-func (b *RealBackupManager) getBackupsMatchingTags(useLocalBackupRepo bool, resticTagFilters []string) ([]tools.BackupInfo, error) {
-	isRemoteRepoEnabled, err := ssh.IsRemoteBackupEnabled()
+func (b *RealBackupManager) getBackupsMatchingTags(repositoryId int, resticTagFilters []string) ([]tools.BackupInfo, error) {
+	repository, err := repositories.BackupRepositoryRepo.GetRepository(repositoryId)
 	if err != nil {
 		return nil, err
 	}
-	if !useLocalBackupRepo && !isRemoteRepoEnabled {
-		Logger.Info("remote backup repository is not enabled, skipping backup listing")
+	if !repository.IsEnabled {
+		Logger.Info("backup repository '%s' is not enabled, skipping backup listing", repository.Name)
 		return nil, nil
 	}
 
-	envs, err := prepareResticOperationAndReturnCommandEnvs(useLocalBackupRepo)
+	envs, err := prepareResticOperationAndReturnCommandEnvs(repositoryId)
 	if err != nil {
 		return nil, err
 	}
@@ -321,8 +541,8 @@ func parseBackupInfo(jsonStr string) ([]tools.BackupInfo, error) {
 	return backups, nil
 }
 
-func (b *RealBackupManager) DeleteBackup(backupId string, isLocalBackup bool) error {
-	envs, err := prepareResticOperationAndReturnCommandEnvs(isLocalBackup)
+func (b *RealBackupManager) DeleteBackup(backupId string, repositoryId int) error {
+	envs, err := prepareResticOperationAndReturnCommandEnvs(repositoryId)
 	if err != nil {
 		return err
 	}
@@ -330,12 +550,16 @@ func (b *RealBackupManager) DeleteBackup(backupId string, isLocalBackup bool) er
 	if err != nil {
 		return err
 	}
-	return nil
+	return BackupStatisticsRepo.DeleteStatistics(backupId)
 }
 
 func (b *RealBackupManager) RestoreBackup(request tools.BackupOperationRequest) (*tools.RestoredVersionInfo, error) {
+	return b.RestoreBackupWithProgress(request, nil)
+}
+
+func (b *RealBackupManager) RestoreBackupWithProgress(request tools.BackupOperationRequest, progress tools.ProgressReporter) (*tools.RestoredVersionInfo, error) {
 	defer cloud.UpdateAppConfigs()
-	envs, err := prepareResticOperationAndReturnCommandEnvs(request.IsLocal)
+	envs, err := prepareResticOperationAndReturnCommandEnvs(request.RepositoryId)
 	if err != nil {
 		return nil, err
 	}
@@ -345,12 +569,19 @@ func (b *RealBackupManager) RestoreBackup(request tools.BackupOperationRequest)
 		return nil, err
 	}
 
-	err = b.cleanupAndRestoreVolumes(request.BackupId, volumes, envs)
+	info, err := getBackupInfo(request.BackupId, envs)
+	if err != nil {
+		return nil, err
+	}
+	tools.AppsUnderMaintenance.Begin(info.AppName, tools.AppMaintenanceReasonRestore)
+	defer tools.AppsUnderMaintenance.End(info.AppName, tools.AppMaintenanceReasonRestore)
+
+	err = b.cleanupAndRestoreVolumes(request.BackupId, volumes, envs, progress)
 	if err != nil {
 		return nil, err
 	}
 
-	rvi, err := b.finalizeRestore(request.BackupId, zipFileContent, envs)
+	rvi, err := b.finalizeRestore(*info, zipFileContent)
 	if err != nil {
 		return nil, err
 	}
@@ -358,7 +589,7 @@ func (b *RealBackupManager) RestoreBackup(request tools.BackupOperationRequest)
 	return rvi, nil
 }
 
-func (b *RealBackupManager) fetchAndPrepareZip(backupId string, envs []string) ([]byte, []string, error) {
+func (b *RealBackupManager) fetchAndPrepareZip(backupId string, envs resticEnvironment) ([]byte, []string, error) {
 	tempDir, err := os.MkdirTemp(tools.TempDir, "temp")
 	if err != nil {
 		return nil, nil, err
@@ -408,7 +639,7 @@ func (b *RealBackupManager) fetchAndPrepareZip(backupId string, envs []string) (
 	return content, volumes, nil
 }
 
-func (b *RealBackupManager) cleanupAndRestoreVolumes(backupId string, volumes, envs []string) error {
+func (b *RealBackupManager) cleanupAndRestoreVolumes(backupId string, volumes []string, envs resticEnvironment, progress tools.ProgressReporter) error {
 	for _, volume := range volumes {
 		stopCmd := fmt.Sprintf(
 			"docker ps -a --filter \"volume=%s\" --format \"{{.ID}}\" | xargs -r docker rm -f",
@@ -423,14 +654,14 @@ func (b *RealBackupManager) cleanupAndRestoreVolumes(backupId string, volumes, e
 		}
 	}
 
-	_, err := executeInResticContainer(
-		"restic restore "+backupId+" --target /",
-		volumes, nil, envs, "",
+	_, err := executeInResticContainerWithProgress(
+		"restic restore --json "+backupId+" --target /",
+		volumes, nil, envs, "", progress,
 	)
 	return err
 }
 
-func (b *RealBackupManager) finalizeRestore(backupId string, zipFileContent []byte, envs []string) (*tools.RestoredVersionInfo, error) {
+func getBackupInfo(backupId string, envs resticEnvironment) (*tools.BackupInfo, error) {
 	output, err := executeInResticContainer(
 		"restic snapshots --json "+backupId,
 		nil, nil, envs, "",
@@ -446,8 +677,10 @@ func (b *RealBackupManager) finalizeRestore(backupId string, zipFileContent []by
 	if len(backupInfos) != 1 {
 		return nil, fmt.Errorf("expected exactly one backup info, got %d", len(backupInfos))
 	}
-	info := backupInfos[0]
+	return &backupInfos[0], nil
+}
 
+func (b *RealBackupManager) finalizeRestore(info tools.BackupInfo, zipFileContent []byte) (*tools.RestoredVersionInfo, error) {
 	restoredVersionInfo := &tools.RestoredVersionInfo{
 		Maintainer:     info.Maintainer,
 		AppName:        info.AppName,
@@ -506,8 +739,8 @@ func runCommandWithOutputString(command string) (string, error) {
 	return outputBuffer.String(), err
 }
 
-func (r *RealBackupManager) ListAppsInBackupRepo(isLocalBackup bool) ([]tools.MaintainerAndApp, error) {
-	allBackups, err := r.getBackupsMatchingTags(isLocalBackup, nil)
+func (r *RealBackupManager) ListAppsInBackupRepo(repositoryId int) ([]tools.MaintainerAndApp, error) {
+	allBackups, err := r.getBackupsMatchingTags(repositoryId, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -523,54 +756,63 @@ func (r *RealBackupManager) ListAppsInBackupRepo(isLocalBackup bool) ([]tools.Ma
 }
 
 func (r *RealBackupManager) RunRetentionPolicy() error {
-	err := r.applyRetentionPolicyToRepo(true)
+	enabledRepositories, err := repositories.BackupRepositoryRepo.ListEnabledRepositories()
 	if err != nil {
 		return err
 	}
+	for _, repository := range enabledRepositories {
+		err = r.applyRetentionPolicyToRepo(repository)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	repository, err := ssh.GetRemoteBackupRepository()
+// CheckRepository verifies the structural integrity of the repository without reading all data, which would take too long
+// for remote repositories.
+func (r *RealBackupManager) CheckRepository(repositoryId int) error {
+	envs, err := prepareResticOperationAndReturnCommandEnvs(repositoryId)
 	if err != nil {
 		return err
 	}
-	if repository.IsEnabled {
-		err = r.applyRetentionPolicyToRepo(false)
-		if err != nil {
-			return err
-		}
+	output, err := executeInResticContainer("restic check", nil, nil, envs, "")
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(output))
 	}
 	return nil
 }
 
-func (r *RealBackupManager) applyRetentionPolicyToRepo(isLocalRepo bool) error {
-	repoApps, err := clients.BackupManager.ListAppsInBackupRepo(isLocalRepo)
+func (r *RealBackupManager) applyRetentionPolicyToRepo(repository tools.BackupRepository) error {
+	repoApps, err := clients.BackupManager.ListAppsInBackupRepo(repository.Id)
 	if err != nil {
 		return err
 	}
-	err = r.runRetentionPolicyOfAppInRepo(repoApps, isLocalRepo)
+	err = r.runRetentionPolicyOfAppInRepo(repoApps, repository)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *RealBackupManager) runRetentionPolicyOfAppInRepo(apps []tools.MaintainerAndApp, isLocal bool) error {
+func (r *RealBackupManager) runRetentionPolicyOfAppInRepo(apps []tools.MaintainerAndApp, repository tools.BackupRepository) error {
 	for _, app := range apps {
 		backups, err := r.ListBackupsOfApp(tools.BackupListRequest{
-			Maintainer: app.Maintainer,
-			AppName:    app.AppName,
-			IsLocal:    isLocal,
+			Maintainer:   app.Maintainer,
+			AppName:      app.AppName,
+			RepositoryId: repository.Id,
 		})
 		if err != nil {
 			Logger.Error("Error running retention policy of app %s: %v", app.AppName, err)
 			continue
 		}
 
-		backupsToDelete := FindBackupsForDeletionAccordingToRetentionPolicy(backups, numberOfDailyBackupsToKeep, numberOfWeeklyBackupsToKeep, numberOfMonthlyBackupsToKeep)
+		backupsToDelete := FindBackupsForDeletionAccordingToRetentionPolicy(backups, repository.KeepDaily, repository.KeepWeekly, repository.KeepMonthly)
 		for _, backup := range backupsToDelete {
 			if backup.Description == tools.ManualBackupDescription {
 				continue
 			}
-			err = r.DeleteBackup(backup.BackupId, isLocal)
+			err = r.DeleteBackup(backup.BackupId, repository.Id)
 			if err != nil {
 				Logger.Error("Error deleting backup %s: %v", backup.BackupId, err)
 				continue
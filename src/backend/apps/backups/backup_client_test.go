@@ -9,6 +9,7 @@ import (
 	"ocelot/backend/apps/cloud"
 	"ocelot/backend/apps/common"
 	"ocelot/backend/clients"
+	"ocelot/backend/repositories"
 	"ocelot/backend/security"
 	"ocelot/backend/ssh"
 	"ocelot/backend/tools"
@@ -36,6 +37,7 @@ func TestMain(m *testing.M) {
 
 func setup() {
 	cleanup()
+	replicationRetryDelays = nil
 	err := PrepareLocalBackupContainer()
 	if err != nil {
 		Logger.Fatal("Failed to prepare backup container and repos: %v", err)
@@ -66,7 +68,7 @@ func TestBackupApiLocally(t *testing.T) {
 	backupVersionInfo := createAndAssertBackup(t, tools.SampleAppBackupListRequestLocal)
 	restoreBackupAndAssertRestoredFiles(t, backupVersionInfo.BackupId)
 
-	assert.Nil(t, clients.BackupManager.DeleteBackup(backupVersionInfo.BackupId, true))
+	assert.Nil(t, clients.BackupManager.DeleteBackup(backupVersionInfo.BackupId, tools.LocalBackupRepositoryId))
 	assertNoBackupsPresent(t, tools.SampleAppBackupListRequestLocal)
 }
 
@@ -83,6 +85,15 @@ func cleanup() {
 	}
 }
 
+func saveRemoteRepository(repository *tools.BackupRepository) error {
+	if repository.Id != 0 {
+		return repositories.BackupRepositoryRepo.UpdateRepository(*repository)
+	}
+	repositoryId, err := repositories.BackupRepositoryRepo.CreateRepository(*repository)
+	repository.Id = repositoryId
+	return err
+}
+
 func assertNoBackupsPresent(t *testing.T, sampleBackupRequest tools.BackupListRequest) {
 	backups, err := clients.BackupManager.ListBackupsOfApp(sampleBackupRequest)
 	assert.Nil(t, err)
@@ -97,8 +108,8 @@ func restoreBackupAndAssertRestoredFiles(t *testing.T, backupId string) {
 	_, err = executeInAppContainer(`[ ! -d "testfolder" ] || exit 1`)
 	assert.Nil(t, err)
 	backupInfo, err := clients.BackupManager.RestoreBackup(tools.BackupOperationRequest{
-		BackupId: backupId,
-		IsLocal:  true,
+		BackupId:     backupId,
+		RepositoryId: tools.LocalBackupRepositoryId,
 	})
 	assert.Nil(t, err)
 	assertTestFileCorrectness(t)
@@ -168,8 +179,8 @@ func TestBackupOfOcelotCloudDatabase(t *testing.T) {
 	assert.False(t, security.UserRepo.DoesUserExist("testuser"))
 
 	_, err = clients.BackupManager.RestoreBackup(tools.BackupOperationRequest{
-		BackupId: appBackup[0].BackupId,
-		IsLocal:  true,
+		BackupId:     appBackup[0].BackupId,
+		RepositoryId: tools.LocalBackupRepositoryId,
 	})
 	assert.Nil(t, err)
 	assert.True(t, security.UserRepo.DoesUserExist("testuser"))
@@ -188,25 +199,28 @@ func TestRemoteRepoBackupAcceptance(t *testing.T) {
 	assert.True(t, security.UserRepo.DoesUserExist(tools.SampleMaintainer))
 
 	remoteRepo := ssh.GetSampleRemoteRepo()
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
 
 	postgresAppId, err := common.AppRepo.GetAppId(tools.OcelotDbMaintainer, tools.OcelotDbAppName)
 	assert.Nil(t, err)
 	_, err = clients.BackupManager.ListBackupsOfApp(tools.OcelotDbAppBackupListRequestRemote)
 	assert.NotNil(t, err)
+	// the local backup succeeds, while the replication to the remote repository fails due to missing known hosts
 	err = clients.BackupManager.CreateBackup(postgresAppId, "testing-ocelotcloud-database-backup")
-	assert.NotNil(t, err)
+	assert.Nil(t, err)
+	waitForPendingReplications()
 
 	knownHosts, err := ssh.SshClient.GetKnownHosts(remoteRepo.Host, remoteRepo.SshPort)
 	assert.Nil(t, err)
 	remoteRepo.SshKnownHosts = knownHosts
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
 
 	backups, err := clients.BackupManager.ListBackupsOfApp(tools.OcelotDbAppBackupListRequestRemote)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(backups))
 	err = clients.BackupManager.CreateBackup(postgresAppId, "testing-ocelotcloud-database-backup")
 	assert.Nil(t, err)
+	waitForPendingReplications()
 
 	assert.True(t, security.UserRepo.DoesUserExist(tools.SampleMaintainer))
 	userId, err := security.UserRepo.GetUserId(tools.SampleMaintainer)
@@ -220,36 +234,71 @@ func TestRemoteRepoBackupAcceptance(t *testing.T) {
 	backup := backups[0]
 
 	remoteRepo.SshKnownHosts = "sample-string"
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
 	_, err = clients.BackupManager.RestoreBackup(tools.BackupOperationRequest{
-		BackupId: backup.BackupId,
-		IsLocal:  false,
+		BackupId:     backup.BackupId,
+		RepositoryId: remoteRepo.Id,
 	})
 	assert.NotNil(t, err)
 
 	remoteRepo.SshKnownHosts = knownHosts
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
 	_, err = clients.BackupManager.RestoreBackup(tools.BackupOperationRequest{
-		BackupId: backup.BackupId,
-		IsLocal:  false,
+		BackupId:     backup.BackupId,
+		RepositoryId: remoteRepo.Id,
 	})
 	assert.Nil(t, err)
 	assert.True(t, security.UserRepo.DoesUserExist(tools.SampleMaintainer))
 
 	remoteRepo.SshKnownHosts = "sample-string"
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
-	err = clients.BackupManager.DeleteBackup(backup.BackupId, false)
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
+	err = clients.BackupManager.DeleteBackup(backup.BackupId, remoteRepo.Id)
 	assert.NotNil(t, err)
 
 	remoteRepo.SshKnownHosts = knownHosts
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
-	err = clients.BackupManager.DeleteBackup(backup.BackupId, false)
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
+	err = clients.BackupManager.DeleteBackup(backup.BackupId, remoteRepo.Id)
 	assert.Nil(t, err)
 	backups, err = clients.BackupManager.ListBackupsOfApp(tools.OcelotDbAppBackupListRequestRemote)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(backups))
 }
 
+func TestRotateRemoteRepositoryPassword(t *testing.T) {
+	setup()
+	defer cleanup()
+	defer ssh.ShutDownSshTestContainer()
+	ssh.StartSshTestContainer()
+	common.InitializeDatabase(false, false)
+	defer common.WipeWholeDatabase()
+
+	remoteRepo := ssh.GetSampleRemoteRepo()
+	knownHosts, err := ssh.SshClient.GetKnownHosts(remoteRepo.Host, remoteRepo.SshPort)
+	assert.Nil(t, err)
+	remoteRepo.SshKnownHosts = knownHosts
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
+
+	postgresAppId, err := common.AppRepo.GetAppId(tools.OcelotDbMaintainer, tools.OcelotDbAppName)
+	assert.Nil(t, err)
+	assert.Nil(t, clients.BackupManager.CreateBackupInRepositories(postgresAppId, tools.ManualBackupDescription, []int{remoteRepo.Id}))
+	isInitialized, err := repositories.BackupRepositoryRepo.IsRepositoryInitialized(remoteRepo.Id)
+	assert.Nil(t, err)
+	assert.True(t, isInitialized)
+
+	assert.NotNil(t, clients.BackupManager.RotateRepositoryPassword(remoteRepo.Id, remoteRepo.EncryptionPassword))
+	assert.Nil(t, clients.BackupManager.RotateRepositoryPassword(remoteRepo.Id, "new-restic-password"))
+	backups, err := clients.BackupManager.ListBackupsOfApp(tools.OcelotDbAppBackupListRequestRemote)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(backups))
+
+	// the key of the old password is removed, so the repository can not be opened with it anymore
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
+	_, err = clients.BackupManager.ListBackupsOfApp(tools.OcelotDbAppBackupListRequestRemote)
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, clients.BackupManager.RotateRepositoryPassword(tools.LocalBackupRepositoryId, "new-restic-password"))
+}
+
 func TestShowCommandOutput(t *testing.T) {
 	assert.False(t, showCommandOutput)
 }
@@ -289,41 +338,42 @@ func TestListAppsInBackupRepo(t *testing.T) {
 	ocelotAppId, err := common.AppRepo.GetAppId(tools.OcelotDbMaintainer, tools.OcelotDbAppName)
 	assert.Nil(t, err)
 
-	repo, err := clients.BackupManager.ListAppsInBackupRepo(true)
+	repo, err := clients.BackupManager.ListAppsInBackupRepo(tools.LocalBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(repo))
-	repo, err = clients.BackupManager.ListAppsInBackupRepo(false)
+	repo, err = clients.BackupManager.ListAppsInBackupRepo(tools.SampleRemoteBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(repo))
 
 	err = clients.BackupManager.CreateBackup(ocelotAppId, "testing-ocelotcloud-database-backup")
 	assert.Nil(t, err)
-	repo, err = clients.BackupManager.ListAppsInBackupRepo(true)
+	repo, err = clients.BackupManager.ListAppsInBackupRepo(tools.LocalBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(repo))
 	assert.Equal(t, tools.OcelotDbMaintainer, repo[0].Maintainer)
 	assert.Equal(t, tools.OcelotDbAppName, repo[0].AppName)
-	repo, err = clients.BackupManager.ListAppsInBackupRepo(false)
+	repo, err = clients.BackupManager.ListAppsInBackupRepo(tools.SampleRemoteBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(repo))
 
 	remoteRepo := ssh.GetSampleRemoteRepo()
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
 	knownHosts, err := ssh.SshClient.GetKnownHosts(remoteRepo.Host, remoteRepo.SshPort)
 	assert.Nil(t, err)
 	remoteRepo.SshKnownHosts = knownHosts
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
 
 	err = clients.BackupManager.CreateBackup(sampleAppId, "testing-ocelotcloud-database-backup")
 	assert.Nil(t, err)
-	repo, err = clients.BackupManager.ListAppsInBackupRepo(true)
+	waitForPendingReplications()
+	repo, err = clients.BackupManager.ListAppsInBackupRepo(tools.LocalBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(repo))
 	assert.Equal(t, tools.OcelotDbMaintainer, repo[0].Maintainer)
 	assert.Equal(t, tools.OcelotDbAppName, repo[0].AppName)
 	assert.Equal(t, tools.SampleMaintainer, repo[1].Maintainer)
 	assert.Equal(t, tools.SampleApp, repo[1].AppName)
-	repo, err = clients.BackupManager.ListAppsInBackupRepo(false)
+	repo, err = clients.BackupManager.ListAppsInBackupRepo(tools.SampleRemoteBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(repo))
 	assert.Equal(t, tools.SampleMaintainer, repo[0].Maintainer)
@@ -0,0 +1,166 @@
+package backups
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ocelot/backend/tools"
+	"path"
+	"strings"
+)
+
+// All volumes of an app are mounted below this directory when creating a backup, so it is the root directory of each snapshot.
+const snapshotSourceDir = "/source"
+
+func (b *RealBackupManager) ListBackupFiles(request tools.BackupFileRequest) ([]tools.BackupFileInfo, error) {
+	snapshotPath, err := toSnapshotPath(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := prepareResticOperationAndReturnCommandEnvs(request.RepositoryId)
+	if err != nil {
+		return nil, err
+	}
+	err = assertPathIsWithinAppVolume(request.BackupId, snapshotPath, envs, true)
+	if err != nil {
+		return nil, err
+	}
+	return listFilesInSnapshot(request.BackupId, snapshotPath, envs)
+}
+
+func (b *RealBackupManager) DownloadBackupFiles(w http.ResponseWriter, request tools.BackupFileRequest) error {
+	snapshotPath, err := toSnapshotPath(request.Path)
+	if err != nil {
+		return err
+	}
+	envs, err := prepareResticOperationAndReturnCommandEnvs(request.RepositoryId)
+	if err != nil {
+		return err
+	}
+	err = assertPathIsWithinAppVolume(request.BackupId, snapshotPath, envs, false)
+	if err != nil {
+		return err
+	}
+
+	fileInfo, err := findFileInSnapshot(request.BackupId, snapshotPath, envs)
+	if err != nil {
+		return err
+	}
+
+	dumpCommand := fmt.Sprintf("restic dump %s '%s'", request.BackupId, snapshotPath)
+	if fileInfo.Type == "dir" {
+		dumpCommand = fmt.Sprintf("restic dump --archive zip %s '%s'", request.BackupId, snapshotPath)
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, fileInfo.Name))
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileInfo.Name))
+	}
+	return streamFromResticContainer(dumpCommand, envs, w)
+}
+
+func toSnapshotPath(apiPath string) (string, error) {
+	cleanedPath := path.Clean("/" + apiPath)
+	for _, segment := range strings.Split(apiPath, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("relative path segments are not allowed")
+		}
+	}
+	if cleanedPath == "/" {
+		return snapshotSourceDir, nil
+	}
+	return snapshotSourceDir + cleanedPath, nil
+}
+
+func toApiPath(snapshotPath string) string {
+	apiPath := strings.TrimPrefix(snapshotPath, snapshotSourceDir)
+	if apiPath == "" {
+		return "/"
+	}
+	return apiPath
+}
+
+// Besides the app volumes, a snapshot contains the zipped app version, which must not be exposed via the file browser.
+func assertPathIsWithinAppVolume(backupId, snapshotPath string, envs resticEnvironment, isSnapshotRootAllowed bool) error {
+	if snapshotPath == snapshotSourceDir {
+		if isSnapshotRootAllowed {
+			return nil
+		}
+		return fmt.Errorf("path must point into an app volume")
+	}
+
+	volumeName := strings.SplitN(strings.TrimPrefix(snapshotPath, snapshotSourceDir+"/"), "/", 2)[0]
+	volumes, err := listFilesInSnapshot(backupId, snapshotSourceDir, envs)
+	if err != nil {
+		return err
+	}
+	for _, volume := range volumes {
+		if volume.Name == volumeName {
+			return nil
+		}
+	}
+	return fmt.Errorf("path does not point into an app volume of the backup")
+}
+
+func findFileInSnapshot(backupId, snapshotPath string, envs resticEnvironment) (*tools.BackupFileInfo, error) {
+	filesInParentDir, err := listFilesInSnapshot(backupId, path.Dir(snapshotPath), envs)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range filesInParentDir {
+		if file.Path == toApiPath(snapshotPath) {
+			return &file, nil
+		}
+	}
+	return nil, fmt.Errorf("file not found in backup")
+}
+
+func listFilesInSnapshot(backupId, snapshotPath string, envs resticEnvironment) ([]tools.BackupFileInfo, error) {
+	output, err := executeInResticContainer(fmt.Sprintf("restic ls --json %s '%s'", backupId, snapshotPath), nil, nil, envs, "")
+	if err != nil {
+		return nil, err
+	}
+	files, err := parseResticLsOutput(output, snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshotPath != snapshotSourceDir {
+		return files, nil
+	}
+	var volumes []tools.BackupFileInfo
+	for _, file := range files {
+		if file.Type == "dir" {
+			volumes = append(volumes, file)
+		}
+	}
+	return volumes, nil
+}
+
+// "restic ls --json" prints the snapshot as first line, followed by one line per node. Only direct children of the given directory are returned.
+func parseResticLsOutput(output, snapshotPath string) ([]tools.BackupFileInfo, error) {
+	var files []tools.BackupFileInfo
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var node ResticNode
+		if err := json.Unmarshal([]byte(line), &node); err != nil {
+			Logger.Error("failed to parse restic ls output: %v", err)
+			return nil, fmt.Errorf("failed to parse files of backup")
+		}
+		isNode := node.StructType == "node" || node.MessageType == "node"
+		if !isNode || path.Dir(node.Path) != snapshotPath {
+			continue
+		}
+		files = append(files, tools.BackupFileInfo{
+			Name:             node.Name,
+			Path:             toApiPath(node.Path),
+			Type:             node.Type,
+			Size:             node.Size,
+			ModificationTime: node.Mtime.UTC(),
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,34 @@
+package backups
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"testing"
+)
+
+func TestToSnapshotPath(t *testing.T) {
+	snapshotPath, err := toSnapshotPath("/")
+	assert.Nil(t, err)
+	assert.Equal(t, "/source", snapshotPath)
+
+	snapshotPath, err = toSnapshotPath("/volume/dir/")
+	assert.Nil(t, err)
+	assert.Equal(t, "/source/volume/dir", snapshotPath)
+
+	_, err = toSnapshotPath("/volume/../../etc")
+	assert.NotNil(t, err)
+}
+
+func TestParseResticLsOutput(t *testing.T) {
+	output := `{"time":"2025-01-01T00:00:00Z","paths":["/source"],"id":"abc","struct_type":"snapshot"}
+{"name":"source","type":"dir","path":"/source","mtime":"2025-01-01T00:00:00Z","struct_type":"node"}
+{"name":"vol","type":"dir","path":"/source/vol","mtime":"2025-01-01T00:00:00Z","struct_type":"node"}
+{"name":"hello.txt","type":"file","path":"/source/vol/hello.txt","size":5,"mtime":"2025-01-01T00:00:00Z","struct_type":"node"}
+`
+	files, err := parseResticLsOutput(output, "/source/vol")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, "hello.txt", files[0].Name)
+	assert.Equal(t, "/vol/hello.txt", files[0].Path)
+	assert.Equal(t, "file", files[0].Type)
+	assert.Equal(t, int64(5), files[0].Size)
+}
@@ -1,5 +1,7 @@
 package backups
 
+import "time"
+
 type BackupCreationDto struct {
 	Maintainer               string
 	AppName                  string
@@ -17,4 +19,47 @@ type Snapshot struct {
 	Time string   `json:"time"`
 	Tags []string `json:"tags"`
 	Id   string   `json:"id"`
+	// only set for snapshots created by "restic copy"
+	Original string `json:"original"`
+}
+
+type ResticBackupSummary struct {
+	MessageType         string  `json:"message_type"`
+	FilesNew            int     `json:"files_new"`
+	FilesChanged        int     `json:"files_changed"`
+	FilesUnmodified     int     `json:"files_unmodified"`
+	DataAdded           int64   `json:"data_added"`
+	TotalFilesProcessed int     `json:"total_files_processed"`
+	TotalBytesProcessed int64   `json:"total_bytes_processed"`
+	TotalDuration       float64 `json:"total_duration"`
+	SnapshotId          string  `json:"snapshot_id"`
+}
+
+// "restic backup" reports bytes_done, while "restic restore" reports bytes_restored
+type ResticStatus struct {
+	MessageType      string  `json:"message_type"`
+	PercentDone      float64 `json:"percent_done"`
+	TotalBytes       int64   `json:"total_bytes"`
+	BytesDone        int64   `json:"bytes_done"`
+	BytesRestored    int64   `json:"bytes_restored"`
+	SecondsRemaining int     `json:"seconds_remaining"`
+}
+
+type ResticNode struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Path        string    `json:"path"`
+	Size        int64     `json:"size"`
+	Mtime       time.Time `json:"mtime"`
+	StructType  string    `json:"struct_type"`
+	MessageType string    `json:"message_type"`
+}
+
+type BackupArchiveMetadata struct {
+	Maintainer               string `json:"maintainer" validate:"user_name"`
+	AppName                  string `json:"app_name" validate:"app_name"`
+	VersionName              string `json:"version_name" validate:"version_name"`
+	VersionCreationTimestamp string `json:"version_creation_timestamp" validate:"timestamp"`
+	Description              string `json:"description" validate:"backup_description"`
+	BackupCreationTimestamp  string `json:"backup_creation_timestamp" validate:"timestamp"`
 }
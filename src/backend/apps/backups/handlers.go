@@ -1,14 +1,16 @@
 package backups
 
 import (
+	"errors"
 	"github.com/ocelot-cloud/shared/utils"
 	"github.com/ocelot-cloud/shared/validation"
 	"net/http"
 	"ocelot/backend/apps/cloud"
+	"ocelot/backend/apps/common"
 	"ocelot/backend/clients"
+	"ocelot/backend/jobs"
 	"ocelot/backend/tools"
 	"strconv"
-	"strings"
 )
 
 func CreateBackupHandler(w http.ResponseWriter, r *http.Request) {
@@ -23,18 +25,19 @@ func CreateBackupHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Error converting appId to int", http.StatusBadRequest)
 		return
 	}
-	err = tools.TryLockAndRespondForError(w, "create backup")
+	ticket, err := tools.AppOperationQueue.Enqueue(common.GetOperationScope(appId), "create backup")
 	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
 		return
 	}
-	defer tools.AppOperationMutex.Unlock()
-
-	err = clients.BackupManager.CreateBackup(appId, tools.ManualBackupDescription)
+	jobId, err := jobs.StartJob(ticket, appId, func(progress tools.ProgressReporter) error {
+		return clients.BackupManager.CreateBackupWithProgress(appId, tools.ManualBackupDescription, progress)
+	})
 	if err != nil {
-		Logger.Error("Error creating backup: %v", err)
 		http.Error(w, "Error creating backup", http.StatusInternalServerError)
 		return
 	}
+	utils.SendJsonResponse(w, tools.JobCreationResponse{JobId: jobId})
 }
 
 func ListBackupsHandler(w http.ResponseWriter, r *http.Request) {
@@ -57,18 +60,92 @@ func RestoreBackupHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		return
 	}
-	err = tools.TryLockAndRespondForError(w, "restore backup")
+	// the restored app is only known after reading the backup, so the restore must not run concurrently with any other operation
+	ticket, err := tools.AppOperationQueue.Enqueue(tools.AllAppsScope, "restore backup")
 	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
 		return
 	}
-	defer tools.AppOperationMutex.Unlock()
-
-	_, err = clients.BackupManager.RestoreBackup(*backupRestoreRequest)
+	jobId, err := jobs.StartJob(ticket, 0, func(progress tools.ProgressReporter) error {
+		_, err := clients.BackupManager.RestoreBackupWithProgress(*backupRestoreRequest, progress)
+		return err
+	})
 	if err != nil {
-		Logger.Error("Error restoring backup: %v", err)
 		http.Error(w, "Error restoring backup", http.StatusInternalServerError)
 		return
 	}
+	utils.SendJsonResponse(w, tools.JobCreationResponse{JobId: jobId})
+}
+
+func RestoreBackupAsCloneHandler(w http.ResponseWriter, r *http.Request) {
+	cloneRequest, err := validation.ReadBody[tools.BackupCloneRequest](w, r)
+	if err != nil {
+		return
+	}
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "clone backup")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	_, err = clients.BackupManager.RestoreBackupAsClone(*cloneRequest)
+	if err != nil {
+		Logger.Error("Error restoring backup as clone: %v", err)
+		http.Error(w, "Error restoring backup as clone", http.StatusInternalServerError)
+		return
+	}
+}
+
+func ExportBackupHandler(w http.ResponseWriter, r *http.Request) {
+	exportRequest, err := validation.ReadBody[tools.BackupExportRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	err = clients.BackupManager.ExportBackup(w, *exportRequest)
+	if err != nil {
+		// if streaming already started, the status code was already sent and this error message is appended to the broken download
+		Logger.Error("Error exporting backup: %v", err)
+		http.Error(w, "Error exporting backup", http.StatusInternalServerError)
+		return
+	}
+}
+
+func ImportBackupHandler(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		Logger.Warn("Failed to parse multipart form: %v", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	importRequest := tools.BackupImportRequest{Password: r.FormValue("password")}
+	if err = validation.ValidateStruct(importRequest); err != nil {
+		Logger.Info("invalid input: %v", err)
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	archive, _, err := r.FormFile("archive")
+	if err != nil {
+		Logger.Warn("Failed to read archive from request: %v", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer utils.Close(archive)
+
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "import backup")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	err = clients.BackupManager.ImportBackup(archive, importRequest.Password)
+	if err != nil {
+		Logger.Error("Error importing backup: %v", err)
+		http.Error(w, "Error importing backup", http.StatusBadRequest)
+		return
+	}
 }
 
 func DeleteBackupHandler(w http.ResponseWriter, r *http.Request) {
@@ -77,13 +154,14 @@ func DeleteBackupHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = tools.TryLockAndRespondForError(w, "delete backup")
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "delete backup")
 	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
 		return
 	}
-	defer tools.AppOperationMutex.Unlock()
+	defer tools.AppOperationQueue.Release(ticket)
 
-	err = clients.BackupManager.DeleteBackup(deleteBackupRequest.BackupId, deleteBackupRequest.IsLocal)
+	err = clients.BackupManager.DeleteBackup(deleteBackupRequest.BackupId, deleteBackupRequest.RepositoryId)
 	if err != nil {
 		Logger.Error("Error deleting backup, %v", err)
 		http.Error(w, "Error deleting backup", http.StatusInternalServerError)
@@ -104,11 +182,12 @@ func VersionUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = tools.TryLockAndRespondForError(w, "update app")
+	ticket, err := tools.AppOperationQueue.Acquire(common.GetOperationScope(appId), "update app")
 	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
 		return
 	}
-	defer tools.AppOperationMutex.Unlock()
+	defer tools.AppOperationQueue.Release(ticket)
 
 	if cloud.IsOcelotDbApp(w, appId) {
 		return
@@ -118,7 +197,7 @@ func VersionUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		msg := "Failed to update app version: " + err.Error()
 		println("error: ", err.Error())
-		if strings.Contains(err.Error(), "can't update app") {
+		if isAppAlreadyUpToDateError(err) {
 			Logger.Info(msg)
 			http.Error(w, msg, http.StatusConflict)
 		} else {
@@ -132,12 +211,12 @@ func VersionUpdateHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func ListAppsOfBackupRepository(w http.ResponseWriter, r *http.Request) {
-	readFromLocalBackupRepo, err := validation.ReadBody[tools.SingleBool](w, r)
+	request, err := validation.ReadBody[tools.BackupRepositoryRequest](w, r)
 	if err != nil {
 		return
 	}
 
-	apps, err := clients.BackupManager.ListAppsInBackupRepo(readFromLocalBackupRepo.Value)
+	apps, err := clients.BackupManager.ListAppsInBackupRepo(request.RepositoryId)
 	if err != nil {
 		Logger.Error("Error listing apps in backup repository: %v", err)
 		http.Error(w, "Error listing apps in backup repository", http.StatusInternalServerError)
@@ -147,8 +226,38 @@ func ListAppsOfBackupRepository(w http.ResponseWriter, r *http.Request) {
 	utils.SendJsonResponse(w, apps)
 }
 
+func ListBackupFilesHandler(w http.ResponseWriter, r *http.Request) {
+	request, err := validation.ReadBody[tools.BackupFileRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	files, err := clients.BackupManager.ListBackupFiles(*request)
+	if err != nil {
+		Logger.Error("Error listing backup files: %v", err)
+		http.Error(w, "Error listing backup files", http.StatusBadRequest)
+		return
+	}
+	utils.SendJsonResponse(w, files)
+}
+
+func DownloadBackupFilesHandler(w http.ResponseWriter, r *http.Request) {
+	request, err := validation.ReadBody[tools.BackupFileRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	err = clients.BackupManager.DownloadBackupFiles(w, *request)
+	if err != nil {
+		// if streaming already started, the status code was already sent and this error message is appended to the broken download
+		Logger.Error("Error downloading backup files: %v", err)
+		http.Error(w, "Error downloading backup files", http.StatusBadRequest)
+		return
+	}
+}
+
 func GetMaintenanceSettingsHandler(w http.ResponseWriter, r *http.Request) {
-	maintenanceSettings, err := GetMaintenanceSettings()
+	maintenanceSettings, err := GetMaintenanceSettingsResponse(tools.Clock.Now())
 	if err != nil {
 		Logger.Error("Error getting maintenance settings: %v", err)
 		http.Error(w, "Error getting maintenance settings", http.StatusInternalServerError)
@@ -172,3 +281,46 @@ func SetMaintenanceSettingsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	utils.SendJsonResponse(w, maintenanceSettings)
 }
+
+func ListMaintenanceRunsHandler(w http.ResponseWriter, r *http.Request) {
+	runs, err := MaintenanceRunRepo.ListRuns()
+	if err != nil {
+		http.Error(w, "Error listing maintenance runs", http.StatusInternalServerError)
+		return
+	}
+	utils.SendJsonResponse(w, runs)
+}
+
+func StartMaintenanceRunHandler(w http.ResponseWriter, r *http.Request) {
+	runId, err := StartMaintenanceCycleNow(tools.Clock.Now())
+	if errors.Is(err, ErrMaintenanceCycleIsOngoing) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		Logger.Error("Error starting maintenance cycle: %v", err)
+		http.Error(w, "Error starting maintenance cycle", http.StatusInternalServerError)
+		return
+	}
+	utils.SendJsonResponse(w, tools.MaintenanceRunCreationResponse{RunId: runId})
+}
+
+func RotateRepositoryPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	request, err := validation.ReadBody[tools.RepositoryPasswordRotationRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "rotate backup repository password")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	err = clients.BackupManager.RotateRepositoryPassword(request.RepositoryId, request.NewPassword)
+	if err != nil {
+		Logger.Error("Failed to rotate password of backup repository %d: %v", request.RepositoryId, err)
+		http.Error(w, "Failed to rotate password of backup repository", http.StatusBadRequest)
+		return
+	}
+}
@@ -0,0 +1,136 @@
+package backups
+
+import (
+	"fmt"
+	"ocelot/backend/certs"
+	"ocelot/backend/clients"
+	"ocelot/backend/notifications"
+	"ocelot/backend/repositories"
+	"ocelot/backend/tools"
+	"strings"
+	"time"
+)
+
+const certificateExpiryWarningPeriod = 14 * 24 * time.Hour
+
+// maintenanceReport collects the results of a maintenance cycle. Problems are notified as soon as they occur, while the
+// summary is sent when the cycle is finished. The run is recorded in the maintenance history unless its id is 0.
+type maintenanceReport struct {
+	results       []string
+	problemsCount int
+	run           tools.MaintenanceRun
+}
+
+func newMaintenanceReport(triggeredBy tools.MaintenanceRunTrigger, now time.Time) (*maintenanceReport, error) {
+	runId, err := MaintenanceRunRepo.CreateRun(triggeredBy, now)
+	if err != nil {
+		return &maintenanceReport{}, err
+	}
+	run := tools.MaintenanceRun{Id: runId, TriggeredBy: triggeredBy, Status: tools.MaintenanceRunStatusRunning, StartedAt: now}
+	return &maintenanceReport{run: run}, nil
+}
+
+func (r *maintenanceReport) addResult(format string, args ...any) {
+	r.results = append(r.results, fmt.Sprintf(format, args...))
+}
+
+func (r *maintenanceReport) addProblem(event notifications.Event, subject, message string) {
+	r.problemsCount++
+	r.addResult("%s: %s", subject, message)
+	notifications.Notify(event, subject, message)
+}
+
+func (r *maintenanceReport) addFailure(event notifications.Event, subject string, err error) {
+	Logger.Error("%s: %v", subject, err)
+	r.addProblem(event, subject, err.Error())
+}
+
+// App results are saved immediately, so that the progress of an ongoing run can be followed in the maintenance history.
+func (r *maintenanceReport) addAppResult(app tools.RepoApp, outcome tools.MaintenanceOutcome, reason string) {
+	result := tools.MaintenanceAppResult{Maintainer: app.Maintainer, AppName: app.AppName, Outcome: outcome, Reason: reason}
+	r.run.AppResults = append(r.run.AppResults, result)
+	if r.run.Id != 0 {
+		_ = MaintenanceRunRepo.AddAppResult(r.run.Id, result)
+	}
+}
+
+func (r *maintenanceReport) addAppFailure(app tools.RepoApp, event notifications.Event, subject string, err error) {
+	r.addFailure(event, subject, err)
+	r.addAppResult(app, tools.MaintenanceOutcomeFailed, fmt.Sprintf("%s: %v", subject, err))
+}
+
+func (r *maintenanceReport) setRetentionResult(outcome tools.MaintenanceOutcome, reason string) {
+	r.run.RetentionOutcome = outcome
+	r.run.RetentionReason = reason
+}
+
+func (r *maintenanceReport) finish() {
+	r.sendSummary()
+	status := tools.MaintenanceRunStatusSucceeded
+	if r.problemsCount > 0 {
+		status = tools.MaintenanceRunStatusFailed
+	}
+	r.saveFinishedRun(status)
+}
+
+// interrupt records a cycle which was cut short by a shutdown without sending a summary, since its results are incomplete.
+func (r *maintenanceReport) interrupt() {
+	r.saveFinishedRun(tools.MaintenanceRunStatusInterrupted)
+}
+
+func (r *maintenanceReport) saveFinishedRun(status tools.MaintenanceRunStatus) {
+	if r.run.Id == 0 {
+		return
+	}
+	finishedAt := tools.Clock.Now().In(r.run.StartedAt.Location())
+	r.run.FinishedAt = &finishedAt
+	r.run.ProblemsCount = r.problemsCount
+	r.run.Status = status
+	_ = MaintenanceRunRepo.FinishRun(r.run)
+}
+
+func (r *maintenanceReport) sendSummary() {
+	subject := "Maintenance cycle finished successfully"
+	if r.problemsCount > 0 {
+		subject = fmt.Sprintf("Maintenance cycle finished with %d problem(s)", r.problemsCount)
+	}
+	message := "There was nothing to do."
+	if len(r.results) > 0 {
+		message = strings.Join(r.results, "\n")
+	}
+	notifications.Notify(notifications.EventMaintenanceSummary, subject, message)
+}
+
+// Self-signed certificates are not checked, since they are not uploaded by the user and browsers distrust them anyway.
+func checkCertificateExpiry(now time.Time, report *maintenanceReport) {
+	certificateInfo, err := certs.GetCertificateInfo()
+	if err != nil {
+		Logger.Warn("Error checking the expiry date of the certificate: %v", err)
+		return
+	}
+	if certificateInfo.IsSelfSigned || certificateInfo.ExpiryDate.Sub(now) > certificateExpiryWarningPeriod {
+		return
+	}
+	subject := "TLS certificate expires soon"
+	if !certificateInfo.ExpiryDate.After(now) {
+		subject = "TLS certificate has expired"
+	}
+	message := fmt.Sprintf("The TLS certificate expires on %s. Please upload a renewed certificate.", certificateInfo.ExpiryDate.In(now.Location()).Format(time.RFC3339))
+	report.addProblem(notifications.EventCertificateExpiry, subject, message)
+}
+
+func checkBackupRepositories(report *maintenanceReport) {
+	enabledRepositories, err := repositories.BackupRepositoryRepo.ListEnabledRepositories()
+	if err != nil {
+		report.addFailure(notifications.EventRepositoryCheckFailed, "Listing backup repositories failed", err)
+		return
+	}
+	for _, repository := range enabledRepositories {
+		err = clients.BackupManager.CheckRepository(repository.Id)
+		if err != nil {
+			report.addFailure(notifications.EventRepositoryCheckFailed, fmt.Sprintf("Check of backup repository '%s' failed", repository.Name), err)
+		} else {
+			report.addResult("backup repository '%s' is intact", repository.Name)
+		}
+	}
+}
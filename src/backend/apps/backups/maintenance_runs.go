@@ -0,0 +1,166 @@
+package backups
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/ocelot-cloud/shared/utils"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"time"
+)
+
+var MaintenanceRunRepo = &MaintenanceRunRepository{}
+
+// older runs are deleted when a run is finished, so that the history does not grow indefinitely
+const maxKeptMaintenanceRuns = 100
+
+type MaintenanceRunRepository struct{}
+
+const maintenanceRunColumns = `run_id, triggered_by, status, started_at, finished_at, retention_outcome, retention_reason, problems_count`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMaintenanceRun(row rowScanner) (*tools.MaintenanceRun, error) {
+	var run tools.MaintenanceRun
+	var startedAt, finishedAt string
+	err := row.Scan(&run.Id, &run.TriggeredBy, &run.Status, &startedAt, &finishedAt, &run.RetentionOutcome, &run.RetentionReason, &run.ProblemsCount)
+	if err != nil {
+		return nil, err
+	}
+	run.StartedAt, err = time.Parse(time.RFC3339, startedAt)
+	if err != nil {
+		return nil, err
+	}
+	if finishedAt != "" {
+		finishedAtTime, err := time.Parse(time.RFC3339, finishedAt)
+		if err != nil {
+			return nil, err
+		}
+		run.FinishedAt = &finishedAtTime
+	}
+	return &run, nil
+}
+
+func (r *MaintenanceRunRepository) CreateRun(triggeredBy tools.MaintenanceRunTrigger, startedAt time.Time) (int, error) {
+	var runId int
+	err := common.DB.QueryRow("INSERT INTO maintenance_runs (triggered_by, status, started_at) VALUES ($1, $2, $3) RETURNING run_id",
+		triggeredBy, tools.MaintenanceRunStatusRunning, startedAt.Format(time.RFC3339)).Scan(&runId)
+	if err != nil {
+		Logger.Error("failed to create maintenance run: %v", err)
+		return 0, fmt.Errorf("failed to create maintenance run")
+	}
+	return runId, nil
+}
+
+func (r *MaintenanceRunRepository) AddAppResult(runId int, result tools.MaintenanceAppResult) error {
+	_, err := common.DB.Exec("INSERT INTO maintenance_run_app_results (run_id, maintainer, app_name, outcome, reason) VALUES ($1, $2, $3, $4, $5)",
+		runId, result.Maintainer, result.AppName, result.Outcome, result.Reason)
+	if err != nil {
+		Logger.Error("failed to add app result to maintenance run %d: %v", runId, err)
+		return fmt.Errorf("failed to add app result to maintenance run")
+	}
+	return nil
+}
+
+func (r *MaintenanceRunRepository) FinishRun(run tools.MaintenanceRun) error {
+	finishedAt := ""
+	if run.FinishedAt != nil {
+		finishedAt = run.FinishedAt.Format(time.RFC3339)
+	}
+	_, err := common.DB.Exec("UPDATE maintenance_runs SET status = $1, finished_at = $2, retention_outcome = $3, retention_reason = $4, problems_count = $5 WHERE run_id = $6",
+		run.Status, finishedAt, run.RetentionOutcome, run.RetentionReason, run.ProblemsCount, run.Id)
+	if err != nil {
+		Logger.Error("failed to finish maintenance run %d: %v", run.Id, err)
+		return fmt.Errorf("failed to finish maintenance run")
+	}
+
+	_, err = common.DB.Exec("DELETE FROM maintenance_runs WHERE run_id NOT IN (SELECT run_id FROM maintenance_runs ORDER BY run_id DESC LIMIT $1)", maxKeptMaintenanceRuns)
+	if err != nil {
+		Logger.Error("failed to delete old maintenance runs: %v", err)
+		return fmt.Errorf("failed to delete old maintenance runs")
+	}
+	return nil
+}
+
+func (r *MaintenanceRunRepository) GetRun(runId int) (*tools.MaintenanceRun, error) {
+	row := common.DB.QueryRow("SELECT "+maintenanceRunColumns+" FROM maintenance_runs WHERE run_id = $1", runId)
+	run, err := scanMaintenanceRun(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("maintenance run does not exist")
+	} else if err != nil {
+		Logger.Error("failed to get maintenance run %d: %v", runId, err)
+		return nil, fmt.Errorf("failed to get maintenance run")
+	}
+	run.AppResults, err = r.listAppResults(runId)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListRuns returns the most recent runs first.
+func (r *MaintenanceRunRepository) ListRuns() ([]tools.MaintenanceRun, error) {
+	rows, err := common.DB.Query("SELECT " + maintenanceRunColumns + " FROM maintenance_runs ORDER BY run_id DESC")
+	if err != nil {
+		Logger.Error("failed to list maintenance runs: %v", err)
+		return nil, fmt.Errorf("failed to list maintenance runs")
+	}
+	defer utils.Close(rows)
+
+	var runs []tools.MaintenanceRun
+	for rows.Next() {
+		run, err := scanMaintenanceRun(rows)
+		if err != nil {
+			Logger.Error("failed to scan maintenance run: %v", err)
+			return nil, fmt.Errorf("failed to list maintenance runs")
+		}
+		runs = append(runs, *run)
+	}
+	if err = rows.Err(); err != nil {
+		Logger.Error("failed to list maintenance runs: %v", err)
+		return nil, fmt.Errorf("failed to list maintenance runs")
+	}
+
+	for i := range runs {
+		runs[i].AppResults, err = r.listAppResults(runs[i].Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+func (r *MaintenanceRunRepository) listAppResults(runId int) ([]tools.MaintenanceAppResult, error) {
+	rows, err := common.DB.Query("SELECT maintainer, app_name, outcome, reason FROM maintenance_run_app_results WHERE run_id = $1 ORDER BY result_id", runId)
+	if err != nil {
+		Logger.Error("failed to list app results of maintenance run %d: %v", runId, err)
+		return nil, fmt.Errorf("failed to list app results of maintenance run")
+	}
+	defer utils.Close(rows)
+
+	results := []tools.MaintenanceAppResult{}
+	for rows.Next() {
+		var result tools.MaintenanceAppResult
+		if err := rows.Scan(&result.Maintainer, &result.AppName, &result.Outcome, &result.Reason); err != nil {
+			Logger.Error("failed to scan app result of maintenance run %d: %v", runId, err)
+			return nil, fmt.Errorf("failed to list app results of maintenance run")
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// Maintenance cycles are executed in a goroutine of the backend process, so runs which are still marked as running at startup
+// were interrupted by a restart of the backend.
+func (r *MaintenanceRunRepository) MarkInterruptedRuns() error {
+	_, err := common.DB.Exec("UPDATE maintenance_runs SET status = $1, finished_at = $2 WHERE status = $3",
+		tools.MaintenanceRunStatusInterrupted, tools.Clock.Now().UTC().Format(time.RFC3339), tools.MaintenanceRunStatusRunning)
+	if err != nil {
+		Logger.Error("failed to mark interrupted maintenance runs: %v", err)
+		return fmt.Errorf("failed to mark interrupted maintenance runs")
+	}
+	return nil
+}
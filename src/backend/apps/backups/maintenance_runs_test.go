@@ -0,0 +1,80 @@
+package backups
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceRunRepository(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer WipeDatabaseForTesting()
+
+	startedAt := time.Date(2025, 4, 17, 4, 0, 0, 0, time.UTC)
+	runId, err := MaintenanceRunRepo.CreateRun(tools.MaintenanceRunTriggerManual, startedAt)
+	assert.Nil(t, err)
+	result := tools.MaintenanceAppResult{Maintainer: tools.SampleMaintainer, AppName: tools.SampleApp, Outcome: tools.MaintenanceOutcomeFailed, Reason: "backup failed"}
+	assert.Nil(t, MaintenanceRunRepo.AddAppResult(runId, result))
+
+	run, err := MaintenanceRunRepo.GetRun(runId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.MaintenanceRunTriggerManual, run.TriggeredBy)
+	assert.Equal(t, tools.MaintenanceRunStatusRunning, run.Status)
+	assert.Equal(t, startedAt, run.StartedAt)
+	assert.Nil(t, run.FinishedAt)
+	assert.Equal(t, []tools.MaintenanceAppResult{result}, run.AppResults)
+
+	finishedAt := startedAt.Add(time.Minute)
+	run.FinishedAt = &finishedAt
+	run.Status = tools.MaintenanceRunStatusFailed
+	run.RetentionOutcome = tools.MaintenanceOutcomeSucceeded
+	run.ProblemsCount = 1
+	assert.Nil(t, MaintenanceRunRepo.FinishRun(*run))
+
+	runs, err := MaintenanceRunRepo.ListRuns()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(runs))
+	assert.Equal(t, tools.MaintenanceRunStatusFailed, runs[0].Status)
+	assert.Equal(t, finishedAt, *runs[0].FinishedAt)
+	assert.Equal(t, tools.MaintenanceOutcomeSucceeded, runs[0].RetentionOutcome)
+	assert.Equal(t, 1, runs[0].ProblemsCount)
+	assert.Equal(t, 1, len(runs[0].AppResults))
+
+	_, err = MaintenanceRunRepo.GetRun(runId + 1)
+	assert.NotNil(t, err)
+	assert.Equal(t, "maintenance run does not exist", err.Error())
+}
+
+func TestInterruptedMaintenanceRunsAreMarked(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer WipeDatabaseForTesting()
+
+	runId, err := MaintenanceRunRepo.CreateRun(tools.MaintenanceRunTriggerSchedule, time.Now().UTC())
+	assert.Nil(t, err)
+	assert.Nil(t, MaintenanceRunRepo.MarkInterruptedRuns())
+
+	run, err := MaintenanceRunRepo.GetRun(runId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.MaintenanceRunStatusInterrupted, run.Status)
+	assert.NotNil(t, run.FinishedAt)
+}
+
+func TestAppsExcludedFromMaintenanceAreRecordedAsSkipped(t *testing.T) {
+	app := tools.RepoApp{Maintainer: tools.SampleMaintainer, AppName: tools.SampleApp}
+	report := &maintenanceReport{}
+	createBackupsAndConductUpdates(app, []int{tools.LocalBackupRepositoryId}, true, report)
+	assert.Equal(t, []tools.MaintenanceAppResult{
+		{Maintainer: tools.SampleMaintainer, AppName: tools.SampleApp, Outcome: tools.MaintenanceOutcomeSkipped, Reason: "app is excluded from auto updates"},
+		{Maintainer: tools.SampleMaintainer, AppName: tools.SampleApp, Outcome: tools.MaintenanceOutcomeSkipped, Reason: "app is excluded from auto backups"},
+	}, report.run.AppResults)
+
+	app.IsAutoBackupEnabled = true
+	app.IsAutoUpdateEnabled = true
+	report = &maintenanceReport{}
+	createBackupsAndConductUpdates(app, nil, false, report)
+	assert.Equal(t, []tools.MaintenanceAppResult{
+		{Maintainer: tools.SampleMaintainer, AppName: tools.SampleApp, Outcome: tools.MaintenanceOutcomeSkipped, Reason: "no backup or update was due"},
+	}, report.run.AppResults)
+}
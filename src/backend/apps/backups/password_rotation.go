@@ -0,0 +1,99 @@
+package backups
+
+import (
+	"encoding/json"
+	"fmt"
+	"ocelot/backend/repositories"
+	"strings"
+)
+
+const newResticPasswordFileLocation = "/tmp/new_restic_password"
+
+type resticKey struct {
+	Id        string `json:"id"`
+	IsCurrent bool   `json:"current"`
+}
+
+// RotateRepositoryPassword adds a restic key for the new password, verifies that it opens the repository and only then
+// removes the key of the old password, so that the existing snapshots stay readable at any point in time.
+func (b *RealBackupManager) RotateRepositoryPassword(repositoryId int, newPassword string) error {
+	repository, err := repositories.BackupRepositoryRepo.GetRepository(repositoryId)
+	if err != nil {
+		return err
+	}
+	if repository.IsLocal() {
+		return fmt.Errorf("the password of the local backup repository can not be changed")
+	}
+	if repository.EncryptionPassword == newPassword {
+		return fmt.Errorf("the new password must differ from the current one")
+	}
+
+	oldEnvs, err := prepareResticOperationAndReturnCommandEnvs(repositoryId)
+	if err != nil {
+		return err
+	}
+	oldKeyId, err := getCurrentResticKeyId(oldEnvs)
+	if err != nil {
+		return err
+	}
+
+	// the password file only exists within the container, which is removed after the command
+	addKeyCmd := fmt.Sprintf("printf '%%s' '%s' > %s && restic key add --new-password-file %s", newPassword, newResticPasswordFileLocation, newResticPasswordFileLocation)
+	output, err := executeInResticContainer(addKeyCmd, nil, nil, oldEnvs, "")
+	if err != nil {
+		return fmt.Errorf("adding key for the new password failed: %v: %s", err, strings.TrimSpace(output))
+	}
+
+	newEnvs := withResticPassword(oldEnvs, newPassword)
+	newKeyId, err := getCurrentResticKeyId(newEnvs)
+	if err != nil {
+		return fmt.Errorf("new password could not be verified: %v", err)
+	}
+	if newKeyId == oldKeyId {
+		return fmt.Errorf("new password could not be verified, since it opens the repository with the old key")
+	}
+	err = repositories.BackupRepositoryRepo.SetEncryptionPassword(repositoryId, newPassword)
+	if err != nil {
+		return err
+	}
+
+	output, err = executeInResticContainer("restic key remove "+oldKeyId, nil, nil, newEnvs, "")
+	if err != nil {
+		return fmt.Errorf("new password is in use, but the key of the old password could not be removed: %v: %s", err, strings.TrimSpace(output))
+	}
+	Logger.Info("rotated encryption password of backup repository %s", repository.Name)
+	return nil
+}
+
+func getCurrentResticKeyId(envs resticEnvironment) (string, error) {
+	output, err := executeInResticContainer("restic key list --json", nil, nil, envs, "")
+	if err != nil {
+		return "", fmt.Errorf("listing restic keys failed: %v: %s", err, strings.TrimSpace(output))
+	}
+	return findCurrentResticKeyId(output)
+}
+
+func findCurrentResticKeyId(keyListJson string) (string, error) {
+	var keys []resticKey
+	err := json.Unmarshal([]byte(keyListJson), &keys)
+	if err != nil {
+		return "", fmt.Errorf("restic keys could not be parsed: %v", err)
+	}
+	for _, key := range keys {
+		if key.IsCurrent {
+			return key.Id, nil
+		}
+	}
+	return "", fmt.Errorf("no current restic key found")
+}
+
+func withResticPassword(envs resticEnvironment, password string) resticEnvironment {
+	newEnvs := resticEnvironment{limits: envs.limits}
+	for _, env := range envs.variables {
+		if strings.HasPrefix(env, "RESTIC_PASSWORD=") {
+			env = "RESTIC_PASSWORD=" + password
+		}
+		newEnvs.variables = append(newEnvs.variables, env)
+	}
+	return newEnvs
+}
@@ -0,0 +1,26 @@
+package backups
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"testing"
+)
+
+func TestFindCurrentResticKeyId(t *testing.T) {
+	keyListJson := `[{"current":false,"id":"1a2b3c","userName":"root","hostName":"a","created":"2025-01-01 00:00:00"},{"current":true,"id":"4d5e6f","userName":"root","hostName":"b","created":"2025-01-02 00:00:00"}]`
+	keyId, err := findCurrentResticKeyId(keyListJson)
+	assert.Nil(t, err)
+	assert.Equal(t, "4d5e6f", keyId)
+
+	_, err = findCurrentResticKeyId(`[{"current":false,"id":"1a2b3c"}]`)
+	assert.NotNil(t, err)
+	_, err = findCurrentResticKeyId("Fatal: wrong password")
+	assert.NotNil(t, err)
+}
+
+func TestWithResticPassword(t *testing.T) {
+	envs := resticEnvironment{variables: []string{"RESTIC_REPOSITORY=rclone:repo2:backups", "RESTIC_PASSWORD=old-password"}, limits: bandwidthLimits{uploadKib: 100}}
+	newEnvs := withResticPassword(envs, "new-password")
+	assert.Equal(t, []string{"RESTIC_REPOSITORY=rclone:repo2:backups", "RESTIC_PASSWORD=new-password"}, newEnvs.variables)
+	assert.Equal(t, envs.limits, newEnvs.limits)
+	assert.Equal(t, "RESTIC_PASSWORD=old-password", envs.variables[1])
+}
@@ -25,11 +25,12 @@ func AppPruneHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = tools.TryLockAndRespondForError(w, "prune app")
+	ticket, err := tools.AppOperationQueue.Acquire(common.GetOperationScope(appId), "prune app")
 	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
 		return
 	}
-	defer tools.AppOperationMutex.Unlock()
+	defer tools.AppOperationQueue.Release(ticket)
 
 	if cloud.IsOcelotDbApp(w, appId) {
 		return
@@ -0,0 +1,227 @@
+package backups
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/notifications"
+	"ocelot/backend/repositories"
+	"ocelot/backend/tools"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	replicationRetryDelays = []time.Duration{1 * time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour}
+	pendingReplications    sync.WaitGroup
+	replicationTaskCounter atomic.Int64
+	// closed by StopReplications, so that replications waiting for a retry or a transfer window give up
+	replicationsStopped     = make(chan struct{})
+	stopReplicationsOnce    sync.Once
+	errReplicationCancelled = errors.New("replication was cancelled")
+)
+
+type replicationTask struct {
+	id                      int64
+	operationScope          int
+	snapshotId              string
+	sourceRepositoryId      int
+	destinationRepositoryId int
+}
+
+// The backup is only created once in the primary repository, so the app is stopped only once. All other repositories receive
+// a copy of the snapshot in the background, so that a slow or unreachable remote host does not delay the app's restart.
+func selectPrimaryRepository(repositoryIds []int) (int, []int) {
+	for i, repositoryId := range repositoryIds {
+		if repositoryId == tools.LocalBackupRepositoryId {
+			return repositoryId, append(append([]int{}, repositoryIds[:i]...), repositoryIds[i+1:]...)
+		}
+	}
+	return repositoryIds[0], repositoryIds[1:]
+}
+
+func replicateSnapshotInBackground(appId int, snapshotId string, sourceRepositoryId int, destinationRepositoryIds []int) {
+	operationScope := common.GetOperationScope(appId)
+	for _, destinationRepositoryId := range destinationRepositoryIds {
+		task := replicationTask{replicationTaskCounter.Add(1), operationScope, snapshotId, sourceRepositoryId, destinationRepositoryId}
+		pendingReplications.Add(1)
+		go func() {
+			defer pendingReplications.Done()
+			err := retryWithDelays(func() error { return replicateSnapshot(task) }, replicationRetryDelays, replicationsStopped)
+			if errors.Is(err, errReplicationCancelled) {
+				Logger.Info("cancelled replication of backup %s to backup repository with id %d", task.snapshotId, task.destinationRepositoryId)
+			} else if err != nil {
+				Logger.Error("giving up replicating backup %s to backup repository with id %d: %v", task.snapshotId, task.destinationRepositoryId, err)
+				notifications.Notify(notifications.EventReplicationFailed, fmt.Sprintf("Replication of backup %s failed", task.snapshotId),
+					fmt.Sprintf("The backup could not be copied to the backup repository with id %d: %v", task.destinationRepositoryId, err))
+			}
+		}()
+	}
+}
+
+func waitForPendingReplications() {
+	pendingReplications.Wait()
+}
+
+// StopReplications cancels replications which wait for a retry or a transfer window and waits until running copies are finished.
+func StopReplications() {
+	stopReplicationsOnce.Do(func() { close(replicationsStopped) })
+	pendingReplications.Wait()
+}
+
+// waitUnlessStopped returns errReplicationCancelled if the stop channel is closed before the delay has passed.
+func waitUnlessStopped(delay time.Duration, stop <-chan struct{}) error {
+	select {
+	case <-tools.Clock.After(delay):
+		return nil
+	case <-stop:
+		return errReplicationCancelled
+	}
+}
+
+func retryWithDelays(operation func() error, delays []time.Duration, stop <-chan struct{}) error {
+	err := operation()
+	for _, delay := range delays {
+		if err == nil || errors.Is(err, errReplicationCancelled) {
+			return err
+		}
+		Logger.Warn("operation failed, retrying in %s: %v", delay, err)
+		if waitErr := waitUnlessStopped(delay, stop); waitErr != nil {
+			return waitErr
+		}
+		err = operation()
+	}
+	return err
+}
+
+// The copy is queued like the other operations on the app, so that it does not run concurrently with operations on all apps,
+// like a password rotation of the repositories. It uses rclone remotes of its own, so it can run next to other operations.
+func replicateSnapshot(task replicationTask) error {
+	err := waitForTransferWindow(task.destinationRepositoryId, replicationsStopped)
+	if err != nil {
+		return err
+	}
+	ticket, err := tools.AppOperationQueue.Acquire(task.operationScope, "replicate backup")
+	if err != nil {
+		return errReplicationCancelled
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	sourceRcloneRemoteName := fmt.Sprintf("replication%dsource", task.id)
+	destinationRcloneRemoteName := fmt.Sprintf("replication%ddestination", task.id)
+	defer removeRcloneRemote(sourceRcloneRemoteName)
+	defer removeRcloneRemote(destinationRcloneRemoteName)
+
+	sourceEnvs, err := getResticCopySourceEnvs(task.sourceRepositoryId, sourceRcloneRemoteName)
+	if err != nil {
+		return err
+	}
+	destinationEnvs, err := prepareResticOperationWithRcloneRemote(task.destinationRepositoryId, destinationRcloneRemoteName)
+	if err != nil {
+		return err
+	}
+
+	// restic skips snapshots which are already present in the destination repository, so retrying a partially successful copy is safe
+	envs := resticEnvironment{
+		variables: append(append([]string{}, sourceEnvs.variables...), destinationEnvs.variables...),
+		limits:    bandwidthLimits{uploadKib: destinationEnvs.limits.uploadKib, downloadKib: sourceEnvs.limits.downloadKib},
+	}
+	_, err = executeInResticContainer("restic copy "+task.snapshotId, nil, nil, envs, "")
+	if err != nil {
+		return fmt.Errorf("restic copy failed")
+	}
+	Logger.Info("replicated backup %s to backup repository with id %d", task.snapshotId, task.destinationRepositoryId)
+
+	copyStatisticsToReplica(task.snapshotId, destinationEnvs)
+	return nil
+}
+
+// "restic copy" reads the source repository from RESTIC_FROM_* variables, while all other variables apply to the destination.
+// The credentials of an S3 source are therefore passed via an rclone remote, since restic only reads them from AWS_* variables.
+func getResticCopySourceEnvs(repositoryId int, rcloneRemoteName string) (resticEnvironment, error) {
+	repository, err := repositories.BackupRepositoryRepo.GetRepository(repositoryId)
+	if err != nil {
+		return resticEnvironment{}, err
+	}
+	if repository.IsS3() {
+		return resticEnvironment{variables: getS3RcloneSourceEnvs(*repository, rcloneRemoteName), limits: getBandwidthLimits(*repository)}, nil
+	}
+	envs, err := prepareResticOperationWithRcloneRemote(repositoryId, rcloneRemoteName)
+	if err != nil {
+		return resticEnvironment{}, err
+	}
+	return resticEnvironment{variables: toResticSourceRepositoryEnvs(envs.variables), limits: envs.limits}, nil
+}
+
+func toResticSourceRepositoryEnvs(envs []string) []string {
+	var sourceEnvs []string
+	for _, env := range envs {
+		switch {
+		case strings.HasPrefix(env, "RESTIC_REPOSITORY="):
+			sourceEnvs = append(sourceEnvs, "RESTIC_FROM_REPOSITORY="+strings.TrimPrefix(env, "RESTIC_REPOSITORY="))
+		case strings.HasPrefix(env, "RESTIC_PASSWORD="):
+			sourceEnvs = append(sourceEnvs, "RESTIC_FROM_PASSWORD="+strings.TrimPrefix(env, "RESTIC_PASSWORD="))
+		}
+	}
+	return sourceEnvs
+}
+
+// rclone reads remotes from RCLONE_CONFIG_<NAME>_* variables, so the remote does not have to be stored in its config file.
+func getS3RcloneSourceEnvs(repository tools.BackupRepository, rcloneRemoteName string) []string {
+	path := repository.S3Bucket
+	if repository.S3Prefix != "" {
+		path += "/" + repository.S3Prefix
+	}
+	configPrefix := "RCLONE_CONFIG_" + strings.ToUpper(rcloneRemoteName) + "_"
+	envs := []string{
+		"RESTIC_FROM_REPOSITORY=rclone:" + rcloneRemoteName + ":" + path,
+		"RESTIC_FROM_PASSWORD=" + repository.EncryptionPassword,
+		configPrefix + "TYPE=s3",
+		configPrefix + "PROVIDER=Other",
+		configPrefix + "ENDPOINT=" + repository.S3Endpoint,
+		configPrefix + "ACCESS_KEY_ID=" + repository.S3AccessKey,
+		configPrefix + "SECRET_ACCESS_KEY=" + repository.S3SecretKey,
+	}
+	if repository.S3Region != "" {
+		envs = append(envs, configPrefix+"REGION="+repository.S3Region)
+	}
+	return envs
+}
+
+// Copied snapshots get a new id in the destination repository, which references the id of the original snapshot.
+func copyStatisticsToReplica(snapshotId string, destinationEnvs resticEnvironment) {
+	statistics, err := BackupStatisticsRepo.GetStatistics(snapshotId)
+	if err != nil || statistics == nil {
+		return
+	}
+	output, err := executeInResticContainer("restic snapshots --json", nil, nil, destinationEnvs, "")
+	if err != nil {
+		Logger.Warn("could not list snapshots to assign statistics of replicated backup %s", snapshotId)
+		return
+	}
+	replicaId, err := findReplicaSnapshotId(output, snapshotId)
+	if err != nil {
+		Logger.Warn("could not assign statistics of replicated backup %s: %v", snapshotId, err)
+		return
+	}
+	err = BackupStatisticsRepo.SaveStatistics(replicaId, *statistics)
+	if err != nil {
+		Logger.Warn("could not save statistics of replicated backup %s: %v", replicaId, err)
+	}
+}
+
+func findReplicaSnapshotId(snapshotsJson, originalSnapshotId string) (string, error) {
+	var snapshots []Snapshot
+	if err := json.Unmarshal([]byte(snapshotsJson), &snapshots); err != nil {
+		return "", err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Original == originalSnapshotId {
+			return snapshot.Id, nil
+		}
+	}
+	return "", fmt.Errorf("no replica of snapshot found")
+}
@@ -0,0 +1,102 @@
+package backups
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/tools"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelectPrimaryRepository(t *testing.T) {
+	primary, replicas := selectPrimaryRepository([]int{3, tools.LocalBackupRepositoryId, 2})
+	assert.Equal(t, tools.LocalBackupRepositoryId, primary)
+	assert.Equal(t, []int{3, 2}, replicas)
+
+	primary, replicas = selectPrimaryRepository([]int{3, 2})
+	assert.Equal(t, 3, primary)
+	assert.Equal(t, []int{2}, replicas)
+
+	primary, replicas = selectPrimaryRepository([]int{tools.LocalBackupRepositoryId})
+	assert.Equal(t, tools.LocalBackupRepositoryId, primary)
+	assert.Equal(t, 0, len(replicas))
+}
+
+func TestToResticSourceRepositoryEnvs(t *testing.T) {
+	envs := []string{"RESTIC_REPOSITORY=rclone:repo2:backups", "RESTIC_PASSWORD=secret", "AWS_ACCESS_KEY_ID=key"}
+	expected := []string{"RESTIC_FROM_REPOSITORY=rclone:repo2:backups", "RESTIC_FROM_PASSWORD=secret"}
+	assert.Equal(t, expected, toResticSourceRepositoryEnvs(envs))
+}
+
+func TestGetS3RcloneSourceEnvs(t *testing.T) {
+	repository := tools.BackupRepository{
+		Type:               tools.BackupRepositoryTypeS3,
+		S3Endpoint:         "http://localhost:9000",
+		S3Bucket:           "backups",
+		S3Prefix:           "ocelot",
+		S3Region:           "us-east-1",
+		S3AccessKey:        "source-key",
+		S3SecretKey:        "source-secret",
+		EncryptionPassword: "restic-password",
+	}
+	envs := getS3RcloneSourceEnvs(repository, "replication7source")
+	assert.Equal(t, []string{
+		"RESTIC_FROM_REPOSITORY=rclone:replication7source:backups/ocelot",
+		"RESTIC_FROM_PASSWORD=restic-password",
+		"RCLONE_CONFIG_REPLICATION7SOURCE_TYPE=s3",
+		"RCLONE_CONFIG_REPLICATION7SOURCE_PROVIDER=Other",
+		"RCLONE_CONFIG_REPLICATION7SOURCE_ENDPOINT=http://localhost:9000",
+		"RCLONE_CONFIG_REPLICATION7SOURCE_ACCESS_KEY_ID=source-key",
+		"RCLONE_CONFIG_REPLICATION7SOURCE_SECRET_ACCESS_KEY=source-secret",
+		"RCLONE_CONFIG_REPLICATION7SOURCE_REGION=us-east-1",
+	}, envs)
+	// the AWS_* variables are left to the destination repository
+	for _, env := range envs {
+		assert.False(t, strings.HasPrefix(env, "AWS_"))
+	}
+}
+
+func TestRetryWithDelays(t *testing.T) {
+	attempts := 0
+	err := retryWithDelays(func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("failure")
+		}
+		return nil
+	}, []time.Duration{0, 0, 0}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = retryWithDelays(func() error {
+		attempts++
+		return fmt.Errorf("failure")
+	}, []time.Duration{0, 0}, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryWithDelaysIsCancelledWhenStopped(t *testing.T) {
+	stop := make(chan struct{})
+	close(stop)
+	attempts := 0
+	err := retryWithDelays(func() error {
+		attempts++
+		return fmt.Errorf("failure")
+	}, []time.Duration{24 * time.Hour}, stop)
+	assert.True(t, errors.Is(err, errReplicationCancelled))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestFindReplicaSnapshotId(t *testing.T) {
+	snapshots := `[{"time":"2025-01-01T00:00:00Z","id":"aaa"},{"time":"2025-01-01T00:00:00Z","id":"bbb","original":"xyz"}]`
+	replicaId, err := findReplicaSnapshotId(snapshots, "xyz")
+	assert.Nil(t, err)
+	assert.Equal(t, "bbb", replicaId)
+
+	_, err = findReplicaSnapshotId(snapshots, "unknown")
+	assert.NotNil(t, err)
+}
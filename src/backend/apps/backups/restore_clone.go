@@ -0,0 +1,300 @@
+package backups
+
+import (
+	"fmt"
+	"github.com/ocelot-cloud/shared/utils"
+	"github.com/ocelot-cloud/shared/validation"
+	"gopkg.in/yaml.v3"
+	"ocelot/backend/apps/cloud"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/clients"
+	"ocelot/backend/tools"
+	"os"
+	"strings"
+)
+
+// RestoreBackupAsClone restores a backup next to the original app instead of overwriting it. The clone gets a new app name,
+// so its containers, volumes and network do not collide with the ones of the original app.
+func (b *RealBackupManager) RestoreBackupAsClone(request tools.BackupCloneRequest) (*tools.RestoredVersionInfo, error) {
+	defer cloud.UpdateAppConfigs()
+	envs, err := prepareResticOperationAndReturnCommandEnvs(request.RepositoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := getBackupInfo(request.BackupId, envs)
+	if err != nil {
+		return nil, err
+	}
+	if common.IsOcelotDb(info.Maintainer, info.AppName) {
+		return nil, fmt.Errorf("backups of the ocelot database can not be cloned")
+	}
+	if info.AppName == request.NewAppName {
+		return nil, fmt.Errorf("clone must have a different name than the original app")
+	}
+	if _, err = common.AppRepo.GetAppId(info.Maintainer, request.NewAppName); err == nil {
+		return nil, fmt.Errorf("app with name of clone already exists")
+	}
+
+	zipFileContent, volumes, err := b.fetchAndPrepareZip(request.BackupId, envs)
+	if err != nil {
+		return nil, err
+	}
+
+	clonedZipFileContent, err := cloneVersionContent(zipFileContent, info.Maintainer, info.AppName, request.NewAppName)
+	if err != nil {
+		return nil, err
+	}
+
+	volumeFlags := ""
+	for _, volume := range volumes {
+		volumeFlags += fmt.Sprintf("-v %s:/source/%s ", remapAppPrefix(volume, info.Maintainer, info.AppName, request.NewAppName), volume)
+	}
+	_, err = executeInResticContainer(buildCloneRestoreCommand(request.BackupId, info.VersionName), nil, nil, envs, volumeFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	app := tools.RepoApp{
+		Maintainer:               info.Maintainer,
+		AppName:                  request.NewAppName,
+		VersionName:              info.VersionName,
+		VersionCreationTimestamp: info.VersionCreationTimestamp,
+		VersionContent:           clonedZipFileContent,
+		ShouldBeRunning:          true,
+	}
+	if err = common.AppRepo.CreateApp(app); err != nil {
+		return nil, err
+	}
+	appId, err := common.AppRepo.GetAppId(info.Maintainer, request.NewAppName)
+	if err != nil {
+		return nil, err
+	}
+	if err = clients.Apps.StartApp(appId); err != nil {
+		return nil, err
+	}
+
+	return &tools.RestoredVersionInfo{
+		Maintainer:     info.Maintainer,
+		AppName:        request.NewAppName,
+		VersionName:    info.VersionName,
+		VersionContent: clonedZipFileContent,
+	}, nil
+}
+
+// Only the source archive of the app is excluded, since the volumes may contain zip files of their own.
+func buildCloneRestoreCommand(backupId, versionName string) string {
+	return fmt.Sprintf("restic restore %s --target / --exclude '%s/%s.zip'", backupId, snapshotSourceDir, versionName)
+}
+
+func cloneVersionContent(zipFileContent []byte, maintainer, appName, newAppName string) ([]byte, error) {
+	tempDir, err := utils.UnzipToTempDir(zipFileContent)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.RemoveDir(tempDir)
+
+	composeFilePath := tempDir + "/docker-compose.yml"
+	data, err := os.ReadFile(composeFilePath) // #nosec G304 (CWE-22): Potential file inclusion via variable; is okay, since path is generated internally
+	if err != nil {
+		return nil, err
+	}
+	clonedData, err := cloneDockerComposeYaml(data, maintainer, appName, newAppName)
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(composeFilePath, clonedData, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	appConfigFilePath := tempDir + "/app.yml"
+	if data, err = os.ReadFile(appConfigFilePath); err == nil { // #nosec G304 (CWE-22): Potential file inclusion via variable; is okay, since path is generated internally
+		clonedData, err = cloneAppConfigYaml(data, appName, newAppName)
+		if err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(appConfigFilePath, clonedData, 0600)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return validation.ZipDirectory(tempDir)
+}
+
+// The main service is named after the app and all container and volume names are prefixed with "<maintainer>_<app>_",
+// so these names must be remapped to the name of the clone. Entries which can not be remapped would make the clone use the
+// data of the original app, so they fail the clone.
+func cloneDockerComposeYaml(data []byte, maintainer, appName, newAppName string) ([]byte, error) {
+	var compose map[string]interface{}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, err
+	}
+
+	services, _ := compose["services"].(map[string]interface{})
+	clonedServices := make(map[string]interface{})
+	for serviceName, service := range services {
+		serviceMap, _ := service.(map[string]interface{})
+		if containerName, ok := serviceMap["container_name"].(string); ok {
+			serviceMap["container_name"] = remapContainerName(containerName, maintainer, appName, newAppName)
+		}
+		if volumes, ok := serviceMap["volumes"]; ok {
+			clonedVolumes, err := cloneServiceVolumes(volumes, maintainer, appName, newAppName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to clone volumes of service '%s': %w", serviceName, err)
+			}
+			serviceMap["volumes"] = clonedVolumes
+		}
+		if dependencies, ok := serviceMap["depends_on"]; ok {
+			clonedDependencies, err := cloneServiceDependencies(dependencies, appName, newAppName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to clone dependencies of service '%s': %w", serviceName, err)
+			}
+			serviceMap["depends_on"] = clonedDependencies
+		}
+		if serviceName == appName {
+			serviceName = newAppName
+		}
+		clonedServices[serviceName] = serviceMap
+	}
+	compose["services"] = clonedServices
+
+	if volumes, ok := compose["volumes"].(map[string]interface{}); ok {
+		clonedVolumes := make(map[string]interface{})
+		for volumeName, volume := range volumes {
+			if volumeMap, ok := volume.(map[string]interface{}); ok {
+				if volumeMap["external"] != nil {
+					return nil, fmt.Errorf("external volume '%s' can not be cloned", volumeName)
+				}
+				if name, ok := volumeMap["name"].(string); ok {
+					clonedName, err := remapVolumeSource(name, maintainer, appName, newAppName)
+					if err != nil {
+						return nil, err
+					}
+					volumeMap["name"] = clonedName
+				}
+			}
+			clonedVolumes[remapAppPrefix(volumeName, maintainer, appName, newAppName)] = volume
+		}
+		compose["volumes"] = clonedVolumes
+	}
+
+	return yaml.Marshal(compose)
+}
+
+// Volumes can be given in the short syntax like "maintainer_app_data:/data" or in the long syntax like
+// "{type: volume, source: maintainer_app_data, target: /data}".
+func cloneServiceVolumes(volumes interface{}, maintainer, appName, newAppName string) ([]interface{}, error) {
+	volumeList, ok := volumes.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("volumes must be a list")
+	}
+	for i, volume := range volumeList {
+		switch typedVolume := volume.(type) {
+		case string:
+			source, target, hasSource := strings.Cut(typedVolume, ":")
+			if !hasSource {
+				// anonymous volumes are created per container, so they are not shared with the original app
+				continue
+			}
+			clonedSource, err := remapVolumeSource(source, maintainer, appName, newAppName)
+			if err != nil {
+				return nil, err
+			}
+			volumeList[i] = clonedSource + ":" + target
+		case map[string]interface{}:
+			volumeType, _ := typedVolume["type"].(string)
+			source, hasSource := typedVolume["source"].(string)
+			if volumeType == "tmpfs" || (volumeType == "volume" && !hasSource) {
+				continue
+			}
+			if volumeType != "volume" {
+				return nil, fmt.Errorf("volume of type '%s' can not be cloned", volumeType)
+			}
+			clonedSource, err := remapVolumeSource(source, maintainer, appName, newAppName)
+			if err != nil {
+				return nil, err
+			}
+			typedVolume["source"] = clonedSource
+		default:
+			return nil, fmt.Errorf("volume entry '%v' can not be cloned", volume)
+		}
+	}
+	return volumeList, nil
+}
+
+// remapVolumeSource fails for volumes without the prefix of the app, e.g. bind mounts, since the clone would share them with the original app.
+func remapVolumeSource(source, maintainer, appName, newAppName string) (string, error) {
+	if !strings.HasPrefix(source, maintainer+"_"+appName+"_") {
+		return "", fmt.Errorf("volume '%s' is not owned by the app and can not be cloned", source)
+	}
+	return remapAppPrefix(source, maintainer, appName, newAppName), nil
+}
+
+// Dependencies can be given as a list of service names or as a map from service names to conditions.
+func cloneServiceDependencies(dependencies interface{}, appName, newAppName string) (interface{}, error) {
+	switch typedDependencies := dependencies.(type) {
+	case []interface{}:
+		for i, dependency := range typedDependencies {
+			if _, ok := dependency.(string); !ok {
+				return nil, fmt.Errorf("dependency '%v' can not be cloned", dependency)
+			}
+			if dependency == appName {
+				typedDependencies[i] = newAppName
+			}
+		}
+		return typedDependencies, nil
+	case map[string]interface{}:
+		clonedDependencies := make(map[string]interface{})
+		for dependency, condition := range typedDependencies {
+			if dependency == appName {
+				dependency = newAppName
+			}
+			clonedDependencies[dependency] = condition
+		}
+		return clonedDependencies, nil
+	default:
+		return nil, fmt.Errorf("depends_on must be a list or a map")
+	}
+}
+
+func cloneAppConfigYaml(data []byte, appName, newAppName string) ([]byte, error) {
+	var appConfig map[string]interface{}
+	if err := yaml.Unmarshal(data, &appConfig); err != nil {
+		return nil, err
+	}
+	for _, hooksKey := range []string{"pre_backup_hooks", "post_backup_hooks"} {
+		hooks, _ := appConfig[hooksKey].([]interface{})
+		for _, hook := range hooks {
+			hookMap, ok := hook.(map[string]interface{})
+			if ok && hookMap["service"] == appName {
+				hookMap["service"] = newAppName
+			}
+		}
+	}
+	return yaml.Marshal(appConfig)
+}
+
+func remapAppPrefix(name, maintainer, appName, newAppName string) string {
+	prefix := maintainer + "_" + appName + "_"
+	if !strings.HasPrefix(name, prefix) {
+		return name
+	}
+	remainder := strings.TrimPrefix(name, prefix)
+	if remainder == appName {
+		remainder = newAppName
+	}
+	return maintainer + "_" + newAppName + "_" + remainder
+}
+
+// Container names must be unique, so custom names without the prefix of the app are prefixed with the name of the clone.
+func remapContainerName(containerName, maintainer, appName, newAppName string) string {
+	if strings.HasPrefix(containerName, maintainer+"_"+appName+"_") {
+		return remapAppPrefix(containerName, maintainer, appName, newAppName)
+	}
+	if containerName == appName {
+		containerName = newAppName
+	}
+	return maintainer + "_" + newAppName + "_" + containerName
+}
@@ -0,0 +1,133 @@
+package backups
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestRemapAppPrefix(t *testing.T) {
+	assert.Equal(t, "maintainer_clone_data:/data", remapAppPrefix("maintainer_app_data:/data", "maintainer", "app", "clone"))
+	assert.Equal(t, "maintainer_clone_clone", remapAppPrefix("maintainer_app_app", "maintainer", "app", "clone"))
+	assert.Equal(t, "other_app_data", remapAppPrefix("other_app_data", "maintainer", "app", "clone"))
+}
+
+func TestCloneDockerComposeYaml(t *testing.T) {
+	compose := `
+services:
+  app:
+    image: nginx:1.0
+    container_name: maintainer_app_app
+    volumes:
+      - maintainer_app_data:/data
+    depends_on:
+      - db
+  db:
+    image: postgres:1.0
+    container_name: maintainer_app_db
+volumes:
+  maintainer_app_data:
+`
+	clonedData, err := cloneDockerComposeYaml([]byte(compose), "maintainer", "app", "clone")
+	assert.Nil(t, err)
+
+	var cloned struct {
+		Services map[string]struct {
+			ContainerName string   `yaml:"container_name"`
+			Volumes       []string `yaml:"volumes"`
+		} `yaml:"services"`
+		Volumes map[string]interface{} `yaml:"volumes"`
+	}
+	assert.Nil(t, yaml.Unmarshal(clonedData, &cloned))
+	assert.Equal(t, 2, len(cloned.Services))
+	assert.Equal(t, "maintainer_clone_clone", cloned.Services["clone"].ContainerName)
+	assert.Equal(t, "maintainer_clone_data:/data", cloned.Services["clone"].Volumes[0])
+	assert.Equal(t, "maintainer_clone_db", cloned.Services["db"].ContainerName)
+	_, ok := cloned.Volumes["maintainer_clone_data"]
+	assert.True(t, ok)
+}
+
+func TestRemapContainerName(t *testing.T) {
+	assert.Equal(t, "maintainer_clone_db", remapContainerName("maintainer_app_db", "maintainer", "app", "clone"))
+	assert.Equal(t, "maintainer_clone_clone", remapContainerName("app", "maintainer", "app", "clone"))
+	assert.Equal(t, "maintainer_clone_custom-db", remapContainerName("custom-db", "maintainer", "app", "clone"))
+}
+
+func TestCloneDockerComposeYamlWithCustomContainerName(t *testing.T) {
+	compose := `
+services:
+  app:
+    image: nginx:1.0
+    container_name: app
+  db:
+    image: postgres:1.0
+    container_name: custom-db
+`
+	clonedData, err := cloneDockerComposeYaml([]byte(compose), "maintainer", "app", "clone")
+	assert.Nil(t, err)
+
+	var cloned struct {
+		Services map[string]struct {
+			ContainerName string `yaml:"container_name"`
+		} `yaml:"services"`
+	}
+	assert.Nil(t, yaml.Unmarshal(clonedData, &cloned))
+	assert.Equal(t, "maintainer_clone_clone", cloned.Services["clone"].ContainerName)
+	assert.Equal(t, "maintainer_clone_custom-db", cloned.Services["db"].ContainerName)
+}
+
+func TestBuildCloneRestoreCommandOnlyExcludesSourceArchive(t *testing.T) {
+	assert.Equal(t, "restic restore abc123 --target / --exclude '/source/1.0.zip'", buildCloneRestoreCommand("abc123", "1.0"))
+}
+
+func TestCloneDockerComposeYamlWithLongSyntax(t *testing.T) {
+	compose := `
+services:
+  app:
+    image: nginx:1.0
+    volumes:
+      - type: volume
+        source: maintainer_app_data
+        target: /data
+      - type: tmpfs
+        target: /tmp
+  worker:
+    image: nginx:1.0
+    depends_on:
+      app:
+        condition: service_healthy
+volumes:
+  maintainer_app_data:
+`
+	clonedData, err := cloneDockerComposeYaml([]byte(compose), "maintainer", "app", "clone")
+	assert.Nil(t, err)
+
+	var cloned struct {
+		Services map[string]struct {
+			Volumes   []map[string]string    `yaml:"volumes"`
+			DependsOn map[string]interface{} `yaml:"depends_on"`
+		} `yaml:"services"`
+	}
+	assert.Nil(t, yaml.Unmarshal(clonedData, &cloned))
+	assert.Equal(t, "maintainer_clone_data", cloned.Services["clone"].Volumes[0]["source"])
+	assert.Equal(t, "tmpfs", cloned.Services["clone"].Volumes[1]["type"])
+	_, ok := cloned.Services["worker"].DependsOn["clone"]
+	assert.True(t, ok)
+}
+
+func TestCloneDockerComposeYamlFailsForVolumesNotOwnedByApp(t *testing.T) {
+	for _, volume := range []string{
+		"- other_app_data:/data",
+		"- ./data:/data",
+		"- {type: bind, source: /srv/data, target: /data}",
+		"- {type: volume, source: shared_data, target: /data}",
+	} {
+		compose := "services:\n  app:\n    image: nginx:1.0\n    volumes:\n      " + volume + "\n"
+		_, err := cloneDockerComposeYaml([]byte(compose), "maintainer", "app", "clone")
+		assert.NotNil(t, err)
+	}
+
+	compose := "services:\n  app:\n    image: nginx:1.0\nvolumes:\n  maintainer_app_data:\n    external: true\n"
+	_, err := cloneDockerComposeYaml([]byte(compose), "maintainer", "app", "clone")
+	assert.NotNil(t, err)
+}
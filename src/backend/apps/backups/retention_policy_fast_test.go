@@ -6,82 +6,98 @@ import (
 	"time"
 )
 
-func TestIsMaintenanceDue(t *testing.T) {
-	assert.False(t, isMaintenanceCycleDue(false, false))
-	assert.True(t, isMaintenanceCycleDue(false, true))
-	assert.False(t, isMaintenanceCycleDue(true, false))
-	assert.False(t, isMaintenanceCycleDue(true, true))
-}
+func TestIsScheduledRunDue(t *testing.T) {
+	now := time.Date(2025, 3, 10, 6, 10, 0, 0, time.UTC)
+	lastRun := time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)
 
-func TestWasLastMaintenanceCycleExecutedToday(t *testing.T) {
-	// Same day, same year
-	{
-		now := time.Date(2025, 4, 17, 10, 0, 0, 0, time.UTC)
-		last := time.Date(2025, 4, 17, 1, 0, 0, 0, time.UTC)
-		assert.True(t, wasLastMaintenanceCycleExecutedToday(now, last))
+	assertScheduledRunDue := func(expected bool, schedule string, now, lastRun time.Time) {
+		isDue, err := IsScheduledRunDue(schedule, now, lastRun)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, isDue)
 	}
+	assertScheduledRunDue(true, "0 */6 * * *", now, lastRun)
+	assertScheduledRunDue(false, "0 */6 * * *", now, now.Add(-time.Minute))
+	assertScheduledRunDue(false, "0 4 * * *", now, lastRun)
+	assertScheduledRunDue(true, "0 4 * * *", time.Date(2025, 3, 11, 4, 0, 0, 0, time.UTC), lastRun)
+	// runs missed for longer than the grace period are not due on time, but overdue
+	assertScheduledRunDue(false, "0 4 * * *", time.Date(2025, 3, 11, 5, 30, 0, 0, time.UTC), lastRun)
+	assertScheduledRunDue(true, "0 4 * * *", time.Date(2025, 3, 11, 4, 30, 0, 0, time.UTC), UnixEpochStartTime)
 
-	// Different day, same year
-	{
-		now := time.Date(2025, 4, 17, 1, 0, 0, 0, time.UTC)
-		last := time.Date(2025, 4, 16, 20, 0, 0, 0, time.UTC)
-		assert.False(t, wasLastMaintenanceCycleExecutedToday(now, last))
-	}
+	_, err := IsScheduledRunDue("invalid", now, lastRun)
+	assert.NotNil(t, err)
+}
 
-	// Different day, different year
-	{
-		now := time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC)
-		last := time.Date(2025, 1, 1, 0, 30, 0, 0, time.UTC)
-		assert.False(t, wasLastMaintenanceCycleExecutedToday(now, last))
-	}
+func TestIsScheduledRunOverdue(t *testing.T) {
+	lastRun := time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)
 
-	// Same day, different year
-	{
-		now := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
-		last := time.Date(2025, 1, 1, 1, 30, 0, 0, time.UTC)
-		assert.False(t, wasLastMaintenanceCycleExecutedToday(now, last))
+	assertScheduledRunOverdue := func(expected bool, schedule string, now time.Time) {
+		isOverdue, err := IsScheduledRunOverdue(schedule, now, lastRun)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, isOverdue)
 	}
+	assertScheduledRunOverdue(false, "0 4 * * *", time.Date(2025, 3, 11, 3, 0, 0, 0, time.UTC))
+	assertScheduledRunOverdue(false, "0 4 * * *", time.Date(2025, 3, 11, 5, 0, 0, 0, time.UTC))
+	assertScheduledRunOverdue(true, "0 4 * * *", time.Date(2025, 3, 11, 5, 1, 0, 0, time.UTC))
+	assertScheduledRunOverdue(true, "0 4 * * *", time.Date(2025, 3, 20, 3, 0, 0, 0, time.UTC))
+	assertScheduledRunOverdue(false, "0 6 * * 0", time.Date(2025, 3, 11, 5, 1, 0, 0, time.UTC))
+	assertScheduledRunOverdue(true, "0 6 * * 0", time.Date(2025, 3, 16, 7, 1, 0, 0, time.UTC))
+
+	_, err := IsScheduledRunOverdue("invalid", lastRun, lastRun)
+	assert.NotNil(t, err)
 }
 
-func TestIsWithinTimeRangeOfBeingExecutedToday(t *testing.T) {
-	preferredHour := 4
+func TestFindDueMaintenanceTasks(t *testing.T) {
+	maintenanceSettings := GetDefaultMaintenanceSettings()
+	maintenanceSettings.BackupSchedule = "0 */6 * * *"
+	maintenanceSettings.UpdateSchedule = "0 6 * * 0"
+	lastRun := time.Date(2025, 3, 15, 4, 0, 0, 0, time.UTC)
 
-	tests := []struct {
-		name     string
-		nowUTC   time.Time
-		expected bool
-	}{
-		{
-			name:     "Before window",
-			nowUTC:   time.Date(2025, 4, 17, 1, 59, 59, 0, time.UTC),
-			expected: false,
-		},
-		{
-			name:     "At window start",
-			nowUTC:   time.Date(2025, 4, 17, 4, 0, 0, 0, time.UTC),
-			expected: true,
-		},
-		{
-			name:     "Inside window",
-			nowUTC:   time.Date(2025, 4, 17, 4, 30, 0, 0, time.UTC),
-			expected: true,
-		},
-		{
-			name:     "At window end",
-			nowUTC:   time.Date(2025, 4, 17, 5, 0, 0, 0, time.UTC),
-			expected: false,
-		},
-		{
-			name:     "After window",
-			nowUTC:   time.Date(2025, 4, 17, 5, 0, 0, 0, time.UTC),
-			expected: false,
-		},
-	}
+	dueTasks, overdueTasks, err := findDueMaintenanceTasks(maintenanceSettings, time.Date(2025, 3, 15, 6, 0, 0, 0, time.UTC), lastRun)
+	assert.Nil(t, err)
+	assert.Equal(t, dueMaintenanceTasks{backups: true}, dueTasks)
+	assert.True(t, overdueTasks.isEmpty())
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := isWithinTimeRangeOfBeingExecutedToday(tt.nowUTC, preferredHour)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
+	dueTasks, overdueTasks, err = findDueMaintenanceTasks(maintenanceSettings, time.Date(2025, 3, 16, 6, 0, 0, 0, time.UTC), lastRun)
+	assert.Nil(t, err)
+	assert.Equal(t, dueMaintenanceTasks{backups: true, updates: true}, dueTasks)
+	assert.Equal(t, dueMaintenanceTasks{retention: true, repositoryChecks: true}, overdueTasks)
+
+	maintenanceSettings.AreAutoBackupsEnabled = false
+	dueTasks, overdueTasks, err = findDueMaintenanceTasks(maintenanceSettings, time.Date(2025, 3, 16, 4, 0, 0, 0, time.UTC), lastRun)
+	assert.Nil(t, err)
+	assert.Equal(t, dueMaintenanceTasks{retention: true, repositoryChecks: true}, dueTasks)
+	assert.True(t, overdueTasks.isEmpty())
+
+	// the server was down during the scheduled runs
+	dueTasks, overdueTasks, err = findDueMaintenanceTasks(maintenanceSettings, time.Date(2025, 3, 16, 9, 0, 0, 0, time.UTC), lastRun)
+	assert.Nil(t, err)
+	assert.True(t, dueTasks.isEmpty())
+	assert.Equal(t, dueMaintenanceTasks{updates: true, retention: true, repositoryChecks: true}, overdueTasks)
+}
+
+func TestScheduledRunsFollowTheTimezoneAcrossDaylightSavingTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.Nil(t, err)
+
+	// 04:00 in Berlin is 03:00 UTC in winter and 02:00 UTC in summer
+	winterRun := time.Date(2025, 3, 29, 3, 0, 0, 0, time.UTC).In(berlin)
+	summerRun := time.Date(2025, 3, 30, 2, 0, 0, 0, time.UTC).In(berlin)
+	isDue, err := IsScheduledRunDue("0 4 * * *", winterRun, winterRun.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.True(t, isDue)
+	isDue, err = IsScheduledRunDue("0 4 * * *", summerRun, winterRun)
+	assert.Nil(t, err)
+	assert.True(t, isDue)
+	isDue, err = IsScheduledRunDue("0 4 * * *", summerRun.Add(-time.Minute), winterRun)
+	assert.Nil(t, err)
+	assert.False(t, isDue)
+}
+
+func TestMaintenanceSettingsLocation(t *testing.T) {
+	maintenanceSettings := GetDefaultMaintenanceSettings()
+	assert.Equal(t, time.UTC, maintenanceSettings.getLocation())
+	maintenanceSettings.Timezone = "America/New_York"
+	assert.Equal(t, "America/New_York", maintenanceSettings.getLocation().String())
+	maintenanceSettings.Timezone = "Invalid/Zone"
+	assert.Equal(t, time.UTC, maintenanceSettings.getLocation())
 }
@@ -15,7 +15,7 @@ import (
 
 func TestBackupsAreNotAppliedWhenAutoBackupOptionsAreDisabled(t *testing.T) {
 	defer cleanup()
-	app := setupRetentionPolicyTest(t)
+	setupRetentionPolicyTest(t)
 
 	maintenanceSettings, err := GetMaintenanceSettings()
 	assert.Nil(t, err)
@@ -23,7 +23,8 @@ func TestBackupsAreNotAppliedWhenAutoBackupOptionsAreDisabled(t *testing.T) {
 	maintenanceSettings.AreAutoBackupsEnabled = false
 	assert.Nil(t, SetMaintenanceSettings(*maintenanceSettings))
 
-	createBackupsAndConductUpdates(app)
+	now := time.Now().UTC()
+	conductDueMaintenanceTasks(time.Date(now.Year(), now.Month(), now.Day(), 4, 0, 0, 0, time.UTC))
 	assertNoBackupsPresent(t, tools.SampleAppBackupListRequestLocal)
 }
 
@@ -44,7 +45,7 @@ func TestRunRetentionPolicy(t *testing.T) {
 	defer cleanup()
 	app := setupRetentionPolicyTest(t)
 
-	createBackupsAndConductUpdates(app)
+	createBackupsAndConductUpdates(app, []int{tools.LocalBackupRepositoryId}, true, &maintenanceReport{})
 	assert.Nil(t, clients.BackupManager.RunRetentionPolicy())
 	backups, err := clients.BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestLocal)
 	assert.Nil(t, err)
@@ -52,7 +53,7 @@ func TestRunRetentionPolicy(t *testing.T) {
 	assert.Equal(t, tools.AutoBackupDescription, backups[0].Description)
 	assert.Equal(t, tools.SampleAppVersion1Name, backups[0].VersionName)
 
-	createBackupsAndConductUpdates(app)
+	createBackupsAndConductUpdates(app, []int{tools.LocalBackupRepositoryId}, true, &maintenanceReport{})
 	assert.Nil(t, clients.BackupManager.RunRetentionPolicy())
 	backups, err = clients.BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestLocal)
 	assert.Nil(t, err)
@@ -61,7 +62,7 @@ func TestRunRetentionPolicy(t *testing.T) {
 	assert.Equal(t, tools.SampleAppVersion2Name, backups[0].VersionName)
 	oldBackupCreationTime := backups[0].BackupCreationTimestamp
 
-	createBackupsAndConductUpdates(app)
+	createBackupsAndConductUpdates(app, []int{tools.LocalBackupRepositoryId}, true, &maintenanceReport{})
 	assert.Nil(t, clients.BackupManager.RunRetentionPolicy())
 	backups, err = clients.BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestLocal)
 	assert.Nil(t, err)
@@ -91,7 +92,7 @@ func TestRetentionPolicyAppliesToEachAppSeparately(t *testing.T) {
 
 	// The extra minute is needed due to inaccurate saving of that time in the database
 	timeBeforeRunningRetentionPolicy := time.Now().Add(-1 * time.Minute)
-	conductMaintenanceTasks()
+	conductMaintenanceTasks(time.Now(), allMaintenanceTasks)
 
 	lastExecutionTime, err := getLastMaintenanceCycleExecutionDate()
 	assert.Nil(t, err)
@@ -106,6 +107,29 @@ func TestRetentionPolicyAppliesToEachAppSeparately(t *testing.T) {
 	assert.Equal(t, 1, len(ocelotDbBackups))
 }
 
+func TestAppsExcludedFromMaintenanceAreSkipped(t *testing.T) {
+	defer cleanup()
+	app := setupRetentionPolicyTest(t)
+	assert.Nil(t, common.AppRepo.SetMaintenanceOverrides(app.AppId, false, false))
+	excludedApp, err := common.AppRepo.GetApp(app.AppId)
+	assert.Nil(t, err)
+
+	createBackupsAndConductUpdates(*excludedApp, []int{tools.LocalBackupRepositoryId}, true, &maintenanceReport{})
+	assertNoBackupsPresent(t, tools.SampleAppBackupListRequestLocal)
+	excludedApp, err = common.AppRepo.GetApp(app.AppId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.SampleAppVersion1Name, excludedApp.VersionName)
+
+	assert.Nil(t, common.AppRepo.SetMaintenanceOverrides(app.AppId, true, false))
+	excludedApp, err = common.AppRepo.GetApp(app.AppId)
+	assert.Nil(t, err)
+	createBackupsAndConductUpdates(*excludedApp, []int{tools.LocalBackupRepositoryId}, true, &maintenanceReport{})
+	backups, err := clients.BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestLocal)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(backups))
+	assert.Equal(t, tools.SampleAppVersion1Name, backups[0].VersionName)
+}
+
 func TestManualBackupsAreNotAffectedByRetentionPolicy(t *testing.T) {
 	defer cleanup()
 	app := setupRetentionPolicyTest(t)
@@ -137,7 +161,7 @@ func TestThatRetentionHandlesAppsFromSameMaintainerSeparately(t *testing.T) {
 	maintenanceSettings.AreAutoUpdatesEnabled = false
 	maintenanceSettings.AreAutoBackupsEnabled = true
 	assert.Nil(t, SetMaintenanceSettings(*maintenanceSettings))
-	conductMaintenanceTasks()
+	conductMaintenanceTasks(time.Now(), allMaintenanceTasks)
 
 	app1Backups, err := clients.BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestLocal)
 	assert.Nil(t, err)
@@ -162,7 +186,7 @@ func TestRetentionPolicyForRemoteBackupRepository(t *testing.T) {
 	knownHosts, err := ssh.SshClient.GetKnownHosts(remoteRepo.Host, remoteRepo.SshPort)
 	assert.Nil(t, err)
 	remoteRepo.SshKnownHosts = knownHosts
-	assert.Nil(t, ssh.SetRemoteBackupRepository(remoteRepo))
+	assert.Nil(t, saveRemoteRepository(&remoteRepo))
 
 	assert.Nil(t, common.CreateSampleAppInRepo())
 	appId, err := common.AppRepo.GetAppId(tools.SampleMaintainer, tools.SampleApp)
@@ -171,6 +195,7 @@ func TestRetentionPolicyForRemoteBackupRepository(t *testing.T) {
 	backupClient := ProvideBackupClient()
 	assert.Nil(t, backupClient.CreateBackup(appId, tools.AutoBackupDescription))
 	assert.Nil(t, backupClient.CreateBackup(appId, tools.AutoBackupDescription))
+	waitForPendingReplications()
 
 	backups, err := backupClient.ListBackupsOfApp(tools.SampleAppBackupListRequestRemote)
 	assert.Nil(t, err)
@@ -0,0 +1,21 @@
+package backups
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/ssh"
+	"testing"
+)
+
+func TestGetS3ResticCommandEnvs(t *testing.T) {
+	repo := ssh.GetSampleS3RemoteRepo()
+	envs := getS3ResticCommandEnvs(repo)
+	assert.Equal(t, 5, len(envs))
+	assert.Equal(t, "RESTIC_REPOSITORY=s3:http://localhost:9000/backups/ocelot", envs[0])
+	assert.Equal(t, "AWS_DEFAULT_REGION=us-east-1", envs[4])
+
+	repo.S3Prefix = ""
+	repo.S3Region = ""
+	envs = getS3ResticCommandEnvs(repo)
+	assert.Equal(t, 4, len(envs))
+	assert.Equal(t, "RESTIC_REPOSITORY=s3:http://localhost:9000/backups", envs[0])
+}
@@ -15,8 +15,7 @@ var (
 	fileBackupTestAppDockerfile       = "Dockerfile.file_backup_test_app"
 	createCreateSampleappImageCommand = fmt.Sprintf("docker build -t %s -f %s/%s .", fileBackupTestAppImageName, tools.DockerDir, fileBackupTestAppDockerfile)
 
-	resticImageName         = "restic:local"
-	createCreateResticImage = fmt.Sprintf("docker build -t %s -f %s/Dockerfile.restic .", resticImageName, tools.DockerDir)
+	createCreateResticImage = fmt.Sprintf("docker build -t %s -f %s/Dockerfile.restic .", tools.ResticImageName, tools.DockerDir)
 )
 
 var Logger = tools.Logger
@@ -38,21 +37,30 @@ func InitializeBackupsModule() {
 		{Path: tools.BackupsCreatePath, HandlerFunc: CreateBackupHandler, AccessLevel: security.Admin},
 		{Path: tools.BackupsListPath, HandlerFunc: ListBackupsHandler, AccessLevel: security.Admin},
 		{Path: tools.BackupsRestorePath, HandlerFunc: RestoreBackupHandler, AccessLevel: security.Admin},
+		{Path: tools.BackupsClonePath, HandlerFunc: RestoreBackupAsCloneHandler, AccessLevel: security.Admin},
+		{Path: tools.BackupsExportPath, HandlerFunc: ExportBackupHandler, AccessLevel: security.Admin},
+		{Path: tools.BackupsImportPath, HandlerFunc: ImportBackupHandler, AccessLevel: security.Admin},
 		{Path: tools.BackupsDeletePath, HandlerFunc: DeleteBackupHandler, AccessLevel: security.Admin},
 		{Path: tools.BackupsListAppsPath, HandlerFunc: ListAppsOfBackupRepository, AccessLevel: security.Admin},
+		{Path: tools.BackupsFilesListPath, HandlerFunc: ListBackupFilesHandler, AccessLevel: security.Admin},
+		{Path: tools.BackupsFilesDownloadPath, HandlerFunc: DownloadBackupFilesHandler, AccessLevel: security.Admin},
+
+		{Path: tools.SettingsRepositoriesRotatePasswordPath, HandlerFunc: RotateRepositoryPasswordHandler, AccessLevel: security.Admin},
 
 		{Path: tools.SettingsMaintenanceReadPath, HandlerFunc: GetMaintenanceSettingsHandler, AccessLevel: security.Admin},
 		{Path: tools.SettingsMaintenanceSavePath, HandlerFunc: SetMaintenanceSettingsHandler, AccessLevel: security.Admin},
+		{Path: tools.MaintenanceRunsListPath, HandlerFunc: ListMaintenanceRunsHandler, AccessLevel: security.Admin},
+		{Path: tools.MaintenanceRunsStartPath, HandlerFunc: StartMaintenanceRunHandler, AccessLevel: security.Admin},
 	}
 	security.RegisterRoutes(routes)
 }
 
 func PrepareLocalBackupContainer() error {
-	err := tools.AppOperationMutex.TryLock("prepare local backup container")
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "prepare local backup container")
 	if err != nil {
 		return err
 	}
-	defer tools.AppOperationMutex.Unlock()
+	defer tools.AppOperationQueue.Release(ticket)
 
 	checkImage := "docker images -q restic:local"
 	output, err := runCommandWithOutputString(checkImage)
@@ -81,6 +89,23 @@ func WipeDatabaseForTesting() {
 		Logger.Fatal("Database wipe failed: %v", err)
 	}
 
+	_, err = common.DB.Exec("DELETE FROM backup_statistics")
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+
+	_, err = common.DB.Exec("DELETE FROM jobs")
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+
+	_, err = common.DB.Exec("DELETE FROM maintenance_runs")
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+
+	common.WipeBackupRepositories()
+
 	_, err = common.DB.Exec("DELETE FROM users WHERE NOT user_name = 'admin'")
 	if err != nil {
 		Logger.Fatal("Database wipe failed: %v", err)
@@ -0,0 +1,145 @@
+package backups
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/ocelot-cloud/shared/utils"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"strings"
+)
+
+var BackupStatisticsRepo = &BackupStatisticsRepository{}
+
+type BackupStatisticsRepository struct{}
+
+func (r *BackupStatisticsRepository) SaveStatistics(snapshotId string, statistics tools.BackupStatistics) error {
+	_, err := common.DB.Exec(`INSERT INTO backup_statistics (snapshot_id, files_new, files_changed, files_unmodified, data_added, total_files_processed, total_bytes_processed, total_duration_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (snapshot_id) DO NOTHING`,
+		snapshotId, statistics.FilesNew, statistics.FilesChanged, statistics.FilesUnmodified, statistics.DataAdded, statistics.TotalFilesProcessed, statistics.TotalBytesProcessed, statistics.TotalDurationSeconds)
+	if err != nil {
+		Logger.Error("failed to save statistics of backup %s: %v", snapshotId, err)
+		return fmt.Errorf("failed to save backup statistics")
+	}
+	return nil
+}
+
+// GetStatistics returns nil without error if no statistics were recorded for the snapshot, e.g. for backups created before statistics were introduced.
+func (r *BackupStatisticsRepository) GetStatistics(snapshotId string) (*tools.BackupStatistics, error) {
+	var statistics tools.BackupStatistics
+	err := common.DB.QueryRow(`SELECT files_new, files_changed, files_unmodified, data_added, total_files_processed, total_bytes_processed, total_duration_seconds
+		FROM backup_statistics WHERE snapshot_id = $1`, snapshotId).Scan(&statistics.FilesNew, &statistics.FilesChanged, &statistics.FilesUnmodified, &statistics.DataAdded, &statistics.TotalFilesProcessed, &statistics.TotalBytesProcessed, &statistics.TotalDurationSeconds)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		Logger.Error("failed to get statistics of backup %s: %v", snapshotId, err)
+		return nil, fmt.Errorf("failed to get backup statistics")
+	}
+	return &statistics, nil
+}
+
+// GetStatisticsOfSnapshots fetches the statistics of several snapshots with a single query. Snapshots without recorded
+// statistics are missing in the returned map.
+func (r *BackupStatisticsRepository) GetStatisticsOfSnapshots(snapshotIds []string) (map[string]tools.BackupStatistics, error) {
+	rows, err := common.DB.Query(`SELECT snapshot_id, files_new, files_changed, files_unmodified, data_added, total_files_processed, total_bytes_processed, total_duration_seconds
+		FROM backup_statistics WHERE snapshot_id = ANY($1)`, pq.StringArray(snapshotIds))
+	if err != nil {
+		Logger.Error("failed to get statistics of backups: %v", err)
+		return nil, fmt.Errorf("failed to get backup statistics")
+	}
+	defer utils.Close(rows)
+
+	statisticsBySnapshotId := make(map[string]tools.BackupStatistics)
+	for rows.Next() {
+		var snapshotId string
+		var statistics tools.BackupStatistics
+		err = rows.Scan(&snapshotId, &statistics.FilesNew, &statistics.FilesChanged, &statistics.FilesUnmodified, &statistics.DataAdded, &statistics.TotalFilesProcessed, &statistics.TotalBytesProcessed, &statistics.TotalDurationSeconds)
+		if err != nil {
+			Logger.Error("failed to scan statistics of backups: %v", err)
+			return nil, fmt.Errorf("failed to get backup statistics")
+		}
+		statisticsBySnapshotId[snapshotId] = statistics
+	}
+	if err = rows.Err(); err != nil {
+		Logger.Error("failed to read statistics of backups: %v", err)
+		return nil, fmt.Errorf("failed to get backup statistics")
+	}
+	return statisticsBySnapshotId, nil
+}
+
+func (r *BackupStatisticsRepository) DeleteStatistics(snapshotId string) error {
+	_, err := common.DB.Exec("DELETE FROM backup_statistics WHERE snapshot_id = $1", snapshotId)
+	if err != nil {
+		Logger.Error("failed to delete statistics of backup %s: %v", snapshotId, err)
+		return fmt.Errorf("failed to delete backup statistics")
+	}
+	return nil
+}
+
+// With the --json flag, "restic backup" prints one JSON object per line, the last one being the summary of the created snapshot.
+func parseResticBackupSummary(output string) (*ResticBackupSummary, error) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var summary ResticBackupSummary
+		if err := json.Unmarshal([]byte(line), &summary); err != nil {
+			continue
+		}
+		if summary.MessageType == "summary" {
+			return &summary, nil
+		}
+	}
+	return nil, fmt.Errorf("no summary found in restic backup output")
+}
+
+func (s ResticBackupSummary) toBackupStatistics() tools.BackupStatistics {
+	return tools.BackupStatistics{
+		FilesNew:             s.FilesNew,
+		FilesChanged:         s.FilesChanged,
+		FilesUnmodified:      s.FilesUnmodified,
+		DataAdded:            s.DataAdded,
+		TotalFilesProcessed:  s.TotalFilesProcessed,
+		TotalBytesProcessed:  s.TotalBytesProcessed,
+		TotalDurationSeconds: s.TotalDuration,
+	}
+}
+
+// Returns the id of the created snapshot or an empty string if the output contains no summary.
+func saveStatisticsFromResticBackupOutput(output string) string {
+	summary, err := parseResticBackupSummary(output)
+	if err != nil {
+		Logger.Warn("could not extract backup statistics: %v", err)
+		return ""
+	}
+	err = BackupStatisticsRepo.SaveStatistics(summary.SnapshotId, summary.toBackupStatistics())
+	if err != nil {
+		Logger.Warn("could not save backup statistics: %v", err)
+	}
+	return summary.SnapshotId
+}
+
+func parseResticStatus(line string) (*ResticStatus, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return nil, false
+	}
+	var status ResticStatus
+	if err := json.Unmarshal([]byte(line), &status); err != nil || status.MessageType != "status" {
+		return nil, false
+	}
+	return &status, true
+}
+
+func (s ResticStatus) toJobProgress() tools.JobProgress {
+	return tools.JobProgress{
+		PercentDone:      s.PercentDone,
+		BytesDone:        max(s.BytesDone, s.BytesRestored),
+		TotalBytes:       s.TotalBytes,
+		SecondsRemaining: s.SecondsRemaining,
+	}
+}
@@ -0,0 +1,74 @@
+package backups
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"testing"
+)
+
+var sampleResticBackupOutput = `{"message_type":"status","percent_done":0,"total_files":1,"total_bytes":1024}
+{"message_type":"status","percent_done":1,"total_files":3,"files_done":3,"total_bytes":3072,"bytes_done":3072}
+{"message_type":"summary","files_new":2,"files_changed":1,"files_unmodified":4,"dirs_new":0,"dirs_changed":1,"dirs_unmodified":0,"data_blobs":3,"tree_blobs":1,"data_added":2048,"total_files_processed":7,"total_bytes_processed":7168,"total_duration":1.5,"snapshot_id":"cafe0123"}
+`
+
+func TestParseResticBackupSummary(t *testing.T) {
+	summary, err := parseResticBackupSummary(sampleResticBackupOutput)
+	assert.Nil(t, err)
+	assert.Equal(t, "cafe0123", summary.SnapshotId)
+
+	statistics := summary.toBackupStatistics()
+	assert.Equal(t, 2, statistics.FilesNew)
+	assert.Equal(t, 1, statistics.FilesChanged)
+	assert.Equal(t, 4, statistics.FilesUnmodified)
+	assert.Equal(t, int64(2048), statistics.DataAdded)
+	assert.Equal(t, 7, statistics.TotalFilesProcessed)
+	assert.Equal(t, int64(7168), statistics.TotalBytesProcessed)
+	assert.Equal(t, 1.5, statistics.TotalDurationSeconds)
+
+	_, err = parseResticBackupSummary("Fatal: unable to open repository")
+	assert.NotNil(t, err)
+}
+
+func TestBackupStatisticsRepository(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer WipeDatabaseForTesting()
+
+	statistics, err := BackupStatisticsRepo.GetStatistics("cafe0123")
+	assert.Nil(t, err)
+	assert.Nil(t, statistics)
+
+	sampleStatistics := tools.BackupStatistics{FilesNew: 2, DataAdded: 2048, TotalFilesProcessed: 7, TotalBytesProcessed: 7168, TotalDurationSeconds: 1.5}
+	assert.Nil(t, BackupStatisticsRepo.SaveStatistics("cafe0123", sampleStatistics))
+	statistics, err = BackupStatisticsRepo.GetStatistics("cafe0123")
+	assert.Nil(t, err)
+	assert.Equal(t, sampleStatistics, *statistics)
+
+	otherStatistics := tools.BackupStatistics{FilesNew: 1, DataAdded: 512}
+	assert.Nil(t, BackupStatisticsRepo.SaveStatistics("beef4567", otherStatistics))
+	statisticsBySnapshotId, err := BackupStatisticsRepo.GetStatisticsOfSnapshots([]string{"cafe0123", "beef4567", "dead89ab"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(statisticsBySnapshotId))
+	assert.Equal(t, sampleStatistics, statisticsBySnapshotId["cafe0123"])
+	assert.Equal(t, otherStatistics, statisticsBySnapshotId["beef4567"])
+
+	assert.Nil(t, BackupStatisticsRepo.DeleteStatistics("cafe0123"))
+	statistics, err = BackupStatisticsRepo.GetStatistics("cafe0123")
+	assert.Nil(t, err)
+	assert.Nil(t, statistics)
+}
+
+func TestParseResticStatus(t *testing.T) {
+	status, ok := parseResticStatus(`{"message_type":"status","percent_done":0.25,"total_bytes":4096,"bytes_done":1024,"seconds_remaining":12}`)
+	assert.True(t, ok)
+	assert.Equal(t, tools.JobProgress{PercentDone: 0.25, BytesDone: 1024, TotalBytes: 4096, SecondsRemaining: 12}, status.toJobProgress())
+
+	status, ok = parseResticStatus(`{"message_type":"status","percent_done":0.5,"total_bytes":4096,"bytes_restored":2048}`)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2048), status.toJobProgress().BytesDone)
+
+	_, ok = parseResticStatus(`{"message_type":"summary","snapshot_id":"cafe0123"}`)
+	assert.False(t, ok)
+	_, ok = parseResticStatus("unable to open repository")
+	assert.False(t, ok)
+}
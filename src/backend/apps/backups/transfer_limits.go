@@ -0,0 +1,65 @@
+package backups
+
+import (
+	"fmt"
+	"ocelot/backend/repositories"
+	"ocelot/backend/tools"
+	"strconv"
+	"time"
+)
+
+// resticEnvironment contains what restic needs to access a repository. The bandwidth limits are kept apart from the
+// environment variables, since restic only accepts them as command line flags.
+type resticEnvironment struct {
+	variables []string
+	limits    bandwidthLimits
+}
+
+// Bandwidth limits in KiB/s, 0 means unlimited.
+type bandwidthLimits struct {
+	uploadKib   int
+	downloadKib int
+}
+
+func getBandwidthLimits(repository tools.BackupRepository) bandwidthLimits {
+	return bandwidthLimits{uploadKib: repository.UploadLimitKib, downloadKib: repository.DownloadLimitKib}
+}
+
+func (l bandwidthLimits) toResticFlags() string {
+	flags := ""
+	if l.uploadKib > 0 {
+		flags += "--limit-upload " + strconv.Itoa(l.uploadKib) + " "
+	}
+	if l.downloadKib > 0 {
+		flags += "--limit-download " + strconv.Itoa(l.downloadKib) + " "
+	}
+	return flags
+}
+
+func checkTransferWindow(repositoryId int, now time.Time) error {
+	repository, err := repositories.BackupRepositoryRepo.GetRepository(repositoryId)
+	if err != nil {
+		return err
+	}
+	now = now.In(getMaintenanceLocation())
+	if repository.IsTransferAllowedAt(now) {
+		return nil
+	}
+	return fmt.Errorf("backup repository '%s' only accepts uploads between %02d:00 and %02d:00 %s", repository.Name,
+		repository.TransferWindowStartHour, repository.TransferWindowEndHour, now.Location())
+}
+
+// Replications are not urgent, so instead of failing outside the transfer window, they are postponed until it opens.
+func waitForTransferWindow(repositoryId int, stop <-chan struct{}) error {
+	repository, err := repositories.BackupRepositoryRepo.GetRepository(repositoryId)
+	if err != nil {
+		return err
+	}
+	now := tools.Clock.Now().In(getMaintenanceLocation())
+	if repository.IsTransferAllowedAt(now) {
+		return nil
+	}
+	delay := repository.GetNextTransferWindowStart(now).Sub(now)
+	Logger.Info("postponing upload to backup repository '%s' by %s until its transfer window opens", repository.Name, delay.Round(time.Minute))
+	return waitUnlessStopped(delay, stop)
+}
@@ -0,0 +1,44 @@
+package backups
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/tools"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimitsAreAddedToResticCommand(t *testing.T) {
+	command := buildResticContainerCommand("restic copy abc", nil, []string{"app"}, resticEnvironment{}, "")
+	assert.True(t, strings.HasSuffix(command, `sh -c "restic copy abc --tag app "`))
+
+	envs := resticEnvironment{limits: getBandwidthLimits(tools.BackupRepository{UploadLimitKib: 1024, DownloadLimitKib: 4096})}
+	command = buildResticContainerCommand("restic copy abc", nil, []string{"app"}, envs, "")
+	assert.True(t, strings.HasSuffix(command, `sh -c "restic copy abc --limit-upload 1024 --limit-download 4096 --tag app "`))
+}
+
+func TestIsTransferAllowedAt(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 3, 10, hour, 30, 0, 0, time.UTC) }
+	repository := tools.BackupRepository{Type: tools.BackupRepositoryTypeS3, TransferWindowStartHour: 1, TransferWindowEndHour: 5}
+	assert.True(t, repository.IsTransferAllowedAt(at(12)))
+
+	repository.IsTransferWindowEnabled = true
+	assert.False(t, repository.IsTransferAllowedAt(at(0)))
+	assert.True(t, repository.IsTransferAllowedAt(at(1)))
+	assert.True(t, repository.IsTransferAllowedAt(at(4)))
+	assert.False(t, repository.IsTransferAllowedAt(at(5)))
+
+	repository.TransferWindowStartHour, repository.TransferWindowEndHour = 22, 6
+	assert.True(t, repository.IsTransferAllowedAt(at(23)))
+	assert.True(t, repository.IsTransferAllowedAt(at(2)))
+	assert.False(t, repository.IsTransferAllowedAt(at(12)))
+
+	repository.Type = tools.BackupRepositoryTypeLocal
+	assert.True(t, repository.IsTransferAllowedAt(at(12)))
+}
+
+func TestGetNextTransferWindowStart(t *testing.T) {
+	repository := tools.BackupRepository{TransferWindowStartHour: 22}
+	assert.Equal(t, time.Date(2025, 3, 10, 22, 0, 0, 0, time.UTC), repository.GetNextTransferWindowStart(time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2025, 3, 11, 22, 0, 0, 0, time.UTC), repository.GetNextTransferWindowStart(time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC)))
+}
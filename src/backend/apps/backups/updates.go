@@ -8,20 +8,17 @@ import (
 	"ocelot/backend/clients"
 	"ocelot/backend/tools"
 	"strconv"
+	"strings"
 )
 
 func (b *RealBackupManager) UpdateAppVersion(appId int) error {
 	defer cloud.UpdateAppConfigs()
-	err := clients.Apps.StopApp(appId)
-	if err != nil {
-		return err
-	}
-
 	app, err := common.AppRepo.GetApp(appId)
 	if err != nil {
 		return err
 	}
 
+	// the version is selected before the app is stopped, so that an app without an update stays available
 	versions, err := common.StoreClient.GetVersions(strconv.Itoa(appId))
 	if err != nil {
 		return err
@@ -29,47 +26,47 @@ func (b *RealBackupManager) UpdateAppVersion(appId int) error {
 	if len(versions) == 0 {
 		return fmt.Errorf("no versions found for app")
 	}
-	latestVersionInAppStore := getLatestVersion(versions)
-
-	if latestVersionInAppStore.VersionCreationTimestamp.After(app.VersionCreationTimestamp) {
-		Logger.Info("starting update of app %s", app.AppName)
-		err = clients.BackupManager.CreateBackup(appId, tools.AutoBackupDescription)
-		if err != nil {
-			return err
-		}
-		downloadedRepoApp, err := common.DownloadTag(latestVersionInAppStore.Id)
-		if err != nil {
-			return err
-		}
-		err = common.UpsertApp(*downloadedRepoApp)
-		if err != nil {
-			Logger.Error("failed to upsert latest version: %v. Trying to recover old app.", err)
-			err = common.UpsertApp(*app)
-			if err != nil {
-				Logger.Error("failed to recover old app: %v", err)
-				return err
-			}
-			return err
-		}
-		err = clients.Apps.StartApp(appId)
-		if err != nil {
-			return err
-		}
-	} else {
+	updateVersion := tools.SelectUpdateVersion(*app, versions)
+	if updateVersion == nil {
 		msg := clients.GetUpdateErrorString(*app)
 		Logger.Info(msg)
 		return errors.New(msg)
 	}
 
-	return nil
-}
+	tools.AppsUnderMaintenance.Begin(app.AppName, tools.AppMaintenanceReasonUpdate)
+	defer tools.AppsUnderMaintenance.End(app.AppName, tools.AppMaintenanceReasonUpdate)
 
-func getLatestVersion(versions []tools.VersionInfo) tools.VersionInfo {
-	latest := versions[0]
-	for _, version := range versions {
-		if version.VersionCreationTimestamp.After(latest.VersionCreationTimestamp) {
-			latest = version
+	err = clients.Apps.StopApp(appId)
+	if err != nil {
+		return err
+	}
+
+	Logger.Info("starting update of app %s from version %s to %s", app.AppName, app.VersionName, updateVersion.Name)
+	err = clients.BackupManager.CreateBackup(appId, tools.AutoBackupDescription)
+	if err != nil {
+		return err
+	}
+	downloadedRepoApp, err := common.DownloadTag(updateVersion.Id)
+	if err != nil {
+		return err
+	}
+	err = common.UpsertApp(*downloadedRepoApp)
+	if err != nil {
+		Logger.Error("failed to upsert latest version: %v. Trying to recover old app.", err)
+		err = common.UpsertApp(*app)
+		if err != nil {
+			Logger.Error("failed to recover old app: %v", err)
+			return err
 		}
+		return err
 	}
-	return latest
+	err = clients.Apps.StartApp(appId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func isAppAlreadyUpToDateError(err error) bool {
+	return strings.Contains(err.Error(), "can't update app")
 }
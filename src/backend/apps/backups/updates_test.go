@@ -24,13 +24,37 @@ func TestUpdateAppVersion(t *testing.T) {
 	appBackup := performUpdate(t, appId)
 	removeSampleApp(t, appId)
 	restoredVersionInfo, err := clients.BackupManager.RestoreBackup(tools.BackupOperationRequest{
-		BackupId: appBackup.BackupId,
-		IsLocal:  true,
+		BackupId:     appBackup.BackupId,
+		RepositoryId: tools.LocalBackupRepositoryId,
 	})
 	assert.Nil(t, err)
 	assertRestoredBackup(t, restoredVersionInfo)
 }
 
+func TestAppWithoutUpdateWithinPolicyKeepsRunning(t *testing.T) {
+	defer updateUnitCleanup(t)
+	updateUnitSetup(t)
+	downloadedRepoApp, err := common.DownloadTag(tools.SampleAppVersion1Id)
+	assert.Nil(t, err)
+	assert.Nil(t, common.UpsertApp(*downloadedRepoApp))
+	appId, err := common.AppRepo.GetAppId(tools.SampleMaintainer, tools.SampleApp)
+	assert.Nil(t, err)
+	removeAppContainerIfPresent(t)
+	assert.Nil(t, clients.Apps.StartApp(appId))
+	assert.Nil(t, common.AppRepo.SetUpdatePolicy(appId, tools.UpdatePolicyPatch, false))
+
+	err = clients.BackupManager.UpdateAppVersion(appId)
+	assert.NotNil(t, err)
+	assert.True(t, isAppAlreadyUpToDateError(err))
+
+	responseText, err := getContentOfIndexPage()
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(responseText, "this is version 1.0"))
+	appBackups, err := clients.BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestLocal)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(appBackups))
+}
+
 func performUpdate(t *testing.T, appId int) tools.BackupInfo {
 	appBackups, err := clients.BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestLocal)
 	assert.Nil(t, err)
@@ -33,9 +33,8 @@ func (a *RealAppManager) ProxyRequestToTheAppsDockerContainer(w http.ResponseWri
 		return
 	}
 
-	target, err := getTarget(requestHost, r.URL.RequestURI(), hostFromDatabase)
+	appName, err := getAppNameFromRequestHost(requestHost, hostFromDatabase)
 	if err != nil {
-		Logger.Error("Failed to get target: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -57,6 +56,19 @@ func (a *RealAppManager) ProxyRequestToTheAppsDockerContainer(w http.ResponseWri
 		return
 	}
 
+	// the config of an app may not be loaded yet while it is started, so maintenance is checked before the target is looked up
+	if reason, isUnderMaintenance := tools.AppsUnderMaintenance.GetReason(appName); isUnderMaintenance {
+		tools.WriteMaintenancePage(w, appName, reason)
+		return
+	}
+
+	target, err := getTarget(requestHost, r.URL.RequestURI(), hostFromDatabase)
+	if err != nil {
+		Logger.Error("Failed to get target: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	proxy := createProxyRequest(r, *target)
 	proxy.ServeHTTP(w, r)
 }
@@ -83,13 +95,21 @@ func isQuerySecretPresent(r *http.Request) (string, bool, bool) {
 	}
 }
 
+// getAppNameFromRequestHost returns the subdomain of the request, which is the name of the app requests are routed to.
+func getAppNameFromRequestHost(requestHost, hostFromDatabase string) (string, error) {
+	if !strings.HasSuffix(requestHost, hostFromDatabase) {
+		Logger.Error("requestHost %s does not end with %s, but it should have", requestHost, hostFromDatabase)
+		return "", errors.New("internal error")
+	}
+	return strings.TrimSuffix(requestHost, "."+hostFromDatabase), nil
+}
+
 func getTarget(requestHost, path, hostFromDatabase string) (*Target, error) {
 	var target = Target{}
-	if strings.HasSuffix(requestHost, hostFromDatabase) {
-		target.Container = strings.TrimSuffix(requestHost, "."+hostFromDatabase)
-	} else {
-		Logger.Error("requestHost %s does not end with %s, but it should have", requestHost, hostFromDatabase)
-		return nil, errors.New("internal error")
+	var err error
+	target.Container, err = getAppNameFromRequestHost(requestHost, hostFromDatabase)
+	if err != nil {
+		return nil, err
 	}
 
 	appConfig, ok := GetAppConfig(target.Container)
@@ -100,7 +120,6 @@ func getTarget(requestHost, path, hostFromDatabase string) (*Target, error) {
 	target.Port = strconv.Itoa(appConfig.Port)
 	Logger.Debug("AppConfig: %+v\n", appConfig)
 
-	var err error
 	target.URL, err = buildTargetURL(target.Container, target.Port, path)
 	Logger.Debug("proxying to target URL: %s", target.URL)
 	if err != nil {
@@ -131,6 +150,17 @@ func createProxyRequest(originalRequest *http.Request, target Target) *httputil.
 		removeOcelotAuthCookie(newProxyRequest, originalRequest)
 
 	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		// maintenance may have begun after the request was forwarded, or the app may not accept connections yet after being started
+		if reason, isUnderMaintenance := tools.AppsUnderMaintenance.GetReason(target.Container); isUnderMaintenance {
+			tools.WriteMaintenancePage(w, target.Container, reason)
+		} else if tools.AppsUnderMaintenance.IsStartingUp(target.Container) {
+			tools.WriteMaintenancePage(w, target.Container, tools.AppMaintenanceReasonStarting)
+		} else {
+			Logger.Warn("Failed to proxy request to app %s: %v", target.Container, err)
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}
 	return proxy
 }
 
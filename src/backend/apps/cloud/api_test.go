@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"github.com/ocelot-cloud/shared/assert"
 	"github.com/stretchr/testify/require"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"ocelot/backend/tools"
+	"strconv"
 	"testing"
 )
 
@@ -69,6 +71,15 @@ func TestGetTarget(t *testing.T) {
 	assert.Equal(t, "internal error", err.Error())
 }
 
+func TestGetAppNameFromRequestHost(t *testing.T) {
+	appName, err := getAppNameFromRequestHost("gitea.localhost", "localhost")
+	assert.Nil(t, err)
+	assert.Equal(t, "gitea", appName)
+
+	_, err = getAppNameFromRequestHost("gitea.localhost", "localhost2")
+	assert.NotNil(t, err)
+}
+
 func TestCreateProxyRequest(t *testing.T) {
 	targetURL, _ := url.Parse("http://container:8080")
 	target := Target{
@@ -98,3 +109,28 @@ func TestCreateProxyRequest(t *testing.T) {
 	assert.Equal(t, "", r2.Header.Get(tools.OcelotAuthCookieName))
 	assert.Equal(t, 0, len(r2.Cookies()))
 }
+
+func TestProxyServesMaintenancePageWhileAppIsStartingUp(t *testing.T) {
+	originalTracker := tools.AppsUnderMaintenance
+	tools.AppsUnderMaintenance = tools.ProvideAppMaintenanceTracker()
+	t.Cleanup(func() { tools.AppsUnderMaintenance = originalTracker })
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, listener.Close())
+
+	targetURL, _ := url.Parse("http://127.0.0.1:" + port)
+	target := Target{Container: "127.0.0.1", Port: port, URL: targetURL}
+	r := httptest.NewRequest(http.MethodGet, "https://originalhost/path", nil)
+
+	recorder := httptest.NewRecorder()
+	createProxyRequest(r, target).ServeHTTP(recorder, r)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+
+	tools.AppsUnderMaintenance.MarkStarted(target.Container)
+	recorder = httptest.NewRecorder()
+	createProxyRequest(r, target).ServeHTTP(recorder, r)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+}
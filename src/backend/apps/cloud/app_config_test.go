@@ -0,0 +1,40 @@
+package cloud
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"os"
+	"testing"
+)
+
+func TestReadAppConfigWithBackupHooks(t *testing.T) {
+	dir := t.TempDir()
+	appYaml := `port: 3000
+url_path: /api
+pre_backup_hooks:
+  - service: postgres
+    command: pg_dump -U user db > /var/lib/postgresql/data/dump.sql
+post_backup_hooks:
+  - service: postgres
+    command: rm /var/lib/postgresql/data/dump.sql
+`
+	assert.Nil(t, os.WriteFile(dir+"/app.yml", []byte(appYaml), 0600))
+
+	config, err := readAppConfig(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 3000, config.Port)
+	assert.Equal(t, "/api", config.UrlPath)
+	assert.True(t, config.HasBackupHooks())
+	assert.Equal(t, 1, len(config.PreBackupHooks))
+	assert.Equal(t, "postgres", config.PreBackupHooks[0].Service)
+	assert.Equal(t, "pg_dump -U user db > /var/lib/postgresql/data/dump.sql", config.PreBackupHooks[0].Command)
+	assert.Equal(t, 1, len(config.PostBackupHooks))
+	assert.Equal(t, "rm /var/lib/postgresql/data/dump.sql", config.PostBackupHooks[0].Command)
+}
+
+func TestReadAppConfigWithoutAppYaml(t *testing.T) {
+	config, err := readAppConfig(t.TempDir())
+	assert.Nil(t, err)
+	assert.Equal(t, 80, config.Port)
+	assert.Equal(t, "/", config.UrlPath)
+	assert.False(t, config.HasBackupHooks())
+}
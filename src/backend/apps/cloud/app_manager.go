@@ -31,7 +31,6 @@ type RealAppManager struct{}
 var cantHaveTwoAppsWithSameNameRunningAtSameTime = "can't have two apps with the same name running at the same time"
 
 func (r *RealAppManager) StartApp(appId int) error {
-	defer UpdateAppConfigs()
 	app, err := common.AppRepo.GetApp(appId)
 	if err != nil {
 		return err
@@ -44,6 +43,10 @@ func (r *RealAppManager) StartApp(appId int) error {
 		return errors.New(cantHaveTwoAppsWithSameNameRunningAtSameTime)
 	}
 
+	tools.AppsUnderMaintenance.Begin(app.AppName, tools.AppMaintenanceReasonStarting)
+	defer tools.AppsUnderMaintenance.End(app.AppName, tools.AppMaintenanceReasonStarting)
+	// the proxy needs the config of the app to forward requests to it, so it is loaded before the maintenance ends
+	defer UpdateAppConfigs()
 	CreateExternalDockerNetworkAndConnectOcelotCloud(app.Maintainer, app.AppName)
 
 	dockerStackName := app.Maintainer + "_" + app.AppName
@@ -56,6 +59,7 @@ func (r *RealAppManager) StartApp(appId int) error {
 	if err != nil {
 		return err
 	}
+	tools.AppsUnderMaintenance.MarkStarted(app.AppName)
 	return nil
 }
 
@@ -173,6 +177,17 @@ func (r *RealAppManager) StopApp(appId int) error {
 	return nil
 }
 
+func (r *RealAppManager) RunCommandInAppService(appId int, service, command string) error {
+	app, err := common.AppRepo.GetApp(appId)
+	if err != nil {
+		return err
+	}
+
+	dockerStackName := app.Maintainer + "_" + app.AppName
+	cmd := exec.Command("docker", "compose", "-p", dockerStackName, "exec", "-T", service, "sh", "-c", command) // #nosec G204 (CWE-78): Execution as root with variables in subprocess is required by design
+	return extractVersionZipToDirAndDeploy(app.VersionContent, cmd, app.Maintainer, app.AppName)
+}
+
 func extractVersionZipToDir(content []byte) (string, error) {
 	tempDir, err := os.MkdirTemp("", "docker-compose")
 	if err != nil {
@@ -232,8 +247,20 @@ func extractVersionZipToDirAndDeploy(content []byte, command *exec.Cmd, maintain
 }
 
 type AppConfig struct {
-	Port    int    `yaml:"port"`
-	UrlPath string `yaml:"url_path"`
+	Port            int          `yaml:"port"`
+	UrlPath         string       `yaml:"url_path"`
+	PreBackupHooks  []BackupHook `yaml:"pre_backup_hooks"`
+	PostBackupHooks []BackupHook `yaml:"post_backup_hooks"`
+}
+
+// BackupHook is a shell command executed inside a service container of the app, e.g. to dump a database into a volume before it is backed up.
+type BackupHook struct {
+	Service string `yaml:"service"`
+	Command string `yaml:"command"`
+}
+
+func (c AppConfig) HasBackupHooks() bool {
+	return len(c.PreBackupHooks) > 0 || len(c.PostBackupHooks) > 0
 }
 
 var (
@@ -283,6 +310,15 @@ func UpdateAppConfigs() {
 	appConfigs = newAppConfigs
 }
 
+func ReadAppConfigFromVersionContent(content []byte) (*AppConfig, error) {
+	path, err := extractVersionZipToDir(content)
+	if err != nil {
+		return nil, err
+	}
+	defer deletePath(path)
+	return readAppConfig(path)
+}
+
 func deletePath(path string) {
 	if err := os.RemoveAll(path); err != nil {
 		Logger.Error("Failed to delete path: %v", err)
@@ -45,12 +45,17 @@ func convertToAppDtos(apps []tools.RepoApp) []tools.AppDto {
 	for _, app := range apps {
 		appConfig, _ := GetAppConfig(app.AppName)
 		appDto := tools.AppDto{
-			Maintainer:  app.Maintainer,
-			AppName:     app.AppName,
-			VersionName: app.VersionName,
-			AppId:       strconv.Itoa(app.AppId),
-			UrlPath:     appConfig.UrlPath,
-			Status:      getStatus(app.ShouldBeRunning, true),
+			Maintainer:                 app.Maintainer,
+			AppName:                    app.AppName,
+			VersionName:                app.VersionName,
+			AppId:                      strconv.Itoa(app.AppId),
+			UrlPath:                    appConfig.UrlPath,
+			Status:                     getStatus(app.ShouldBeRunning, true),
+			IsAutoBackupEnabled:        app.IsAutoBackupEnabled,
+			IsAutoUpdateEnabled:        app.IsAutoUpdateEnabled,
+			UpdatePolicy:               app.UpdatePolicy,
+			IsPreReleaseChannelEnabled: app.IsPreReleaseChannelEnabled,
+			AvailableUpdateVersionName: getAvailableUpdateVersionName(app),
 		}
 		appDtos = append(appDtos, appDto)
 	}
@@ -58,12 +63,6 @@ func convertToAppDtos(apps []tools.RepoApp) []tools.AppDto {
 }
 
 func AppStartHandler(w http.ResponseWriter, r *http.Request) {
-	err := tools.TryLockAndRespondForError(w, "start app")
-	if err != nil {
-		return
-	}
-	defer tools.AppOperationMutex.Unlock()
-
 	Logger.Debug("Starting app")
 	appIdString, err := validation.ReadBody[tools.NumberString](w, r)
 	if err != nil {
@@ -76,6 +75,12 @@ func AppStartHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to convert app id", http.StatusBadRequest)
 		return
 	}
+	ticket, err := tools.AppOperationQueue.Acquire(common.GetOperationScope(appId), "start app")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer tools.AppOperationQueue.Release(ticket)
 
 	if IsOcelotDbApp(w, appId) {
 		return
@@ -117,11 +122,12 @@ func AppStopHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to convert app id", http.StatusBadRequest)
 		return
 	}
-	err = tools.TryLockAndRespondForError(w, "stop app")
+	ticket, err := tools.AppOperationQueue.Acquire(common.GetOperationScope(appId), "stop app")
 	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
 		return
 	}
-	defer tools.AppOperationMutex.Unlock()
+	defer tools.AppOperationQueue.Release(ticket)
 
 	if IsOcelotDbApp(w, appId) {
 		return
@@ -135,3 +141,90 @@ func AppStopHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+func AppMaintenanceOverridesHandler(w http.ResponseWriter, r *http.Request) {
+	overrides, err := validation.ReadBody[tools.AppMaintenanceOverrides](w, r)
+	if err != nil {
+		return
+	}
+
+	appId, err := strconv.Atoi(overrides.AppId)
+	if err != nil {
+		Logger.Info("Failed to convert app id: %v", err)
+		http.Error(w, "Failed to convert app id", http.StatusBadRequest)
+		return
+	}
+	ticket, err := tools.AppOperationQueue.Acquire(common.GetOperationScope(appId), "set maintenance overrides")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	_, err = common.AppRepo.GetApp(appId)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	err = common.AppRepo.SetMaintenanceOverrides(appId, overrides.IsAutoBackupEnabled, overrides.IsAutoUpdateEnabled)
+	if err != nil {
+		http.Error(w, "Failed to set maintenance overrides", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func AppUpdatePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	updatePolicy, err := validation.ReadBody[tools.AppUpdatePolicy](w, r)
+	if err != nil {
+		return
+	}
+
+	appId, err := strconv.Atoi(updatePolicy.AppId)
+	if err != nil {
+		Logger.Info("Failed to convert app id: %v", err)
+		http.Error(w, "Failed to convert app id", http.StatusBadRequest)
+		return
+	}
+	if !setUpdatePolicy(w, appId, updatePolicy) {
+		return
+	}
+
+	// the cached update availability depends on the policy, so it is checked again right away, but only after the ticket
+	// was released, so that other operations on the app do not wait for the app store
+	app, err := common.AppRepo.GetApp(appId)
+	if err == nil {
+		checkForAvailableUpdate(*app)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// setUpdatePolicy returns false if an error response was written.
+func setUpdatePolicy(w http.ResponseWriter, appId int, updatePolicy *tools.AppUpdatePolicy) bool {
+	ticket, err := tools.AppOperationQueue.Acquire(common.GetOperationScope(appId), "set update policy")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return false
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	_, err = common.AppRepo.GetApp(appId)
+	if err != nil {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return false
+	}
+
+	err = common.AppRepo.SetUpdatePolicy(appId, updatePolicy.UpdatePolicy, updatePolicy.IsPreReleaseChannelEnabled)
+	if err != nil {
+		http.Error(w, "Failed to set update policy", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// CheckForAvailableUpdatesHandler only starts the check, since querying the app store for every app takes too long for a request.
+func CheckForAvailableUpdatesHandler(w http.ResponseWriter, r *http.Request) {
+	go CheckForAvailableUpdates()
+	w.WriteHeader(http.StatusOK)
+}
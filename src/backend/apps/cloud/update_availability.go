@@ -0,0 +1,117 @@
+package cloud
+
+import (
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Querying the app store for every installed app is too slow to be done on every request of the app list, so the
+// available updates are checked periodically in the background and cached.
+const updateAvailabilityCheckInterval = 6 * time.Hour
+
+type availableUpdate struct {
+	// the installed version and the update policy at the time of the check, so that results are ignored once the app was
+	// updated or restored, or its policy was changed
+	currentVersionName         string
+	updatePolicy               tools.UpdatePolicy
+	isPreReleaseChannelEnabled bool
+	versionName                string
+}
+
+var (
+	availableUpdatesMutex sync.Mutex
+	availableUpdates      = map[int]availableUpdate{}
+	// prevents overlapping checks when a refresh is requested while the periodic check is ongoing
+	updateAvailabilityCheckMutex sync.Mutex
+	// closed by StopUpdateAvailabilityChecks to end the periodic checks
+	updateAvailabilityChecksStopped  = make(chan struct{})
+	stopUpdateAvailabilityChecksOnce sync.Once
+	updateAvailabilityChecksLoop     sync.WaitGroup
+)
+
+func StartUpdateAvailabilityChecks() {
+	updateAvailabilityChecksLoop.Add(1)
+	go func() {
+		defer updateAvailabilityChecksLoop.Done()
+		for {
+			CheckForAvailableUpdates()
+			select {
+			case <-tools.Clock.After(updateAvailabilityCheckInterval):
+			case <-updateAvailabilityChecksStopped:
+				return
+			}
+		}
+	}()
+}
+
+// StopUpdateAvailabilityChecks ends the periodic checks and waits until an ongoing check is finished.
+func StopUpdateAvailabilityChecks() {
+	stopUpdateAvailabilityChecksOnce.Do(func() { close(updateAvailabilityChecksStopped) })
+	updateAvailabilityChecksLoop.Wait()
+}
+
+// CheckForAvailableUpdates determines for each installed app the version it would be updated to according to its update
+// policy. If the versions of an app can not be fetched, its previous result is kept.
+func CheckForAvailableUpdates() {
+	updateAvailabilityCheckMutex.Lock()
+	defer updateAvailabilityCheckMutex.Unlock()
+
+	apps, err := common.AppRepo.ListApps()
+	if err != nil {
+		Logger.Error("Failed to list apps for update availability check: %v", err)
+		return
+	}
+
+	installedAppIds := map[int]bool{}
+	for _, app := range apps {
+		installedAppIds[app.AppId] = true
+		checkForAvailableUpdate(app)
+	}
+
+	availableUpdatesMutex.Lock()
+	defer availableUpdatesMutex.Unlock()
+	for appId := range availableUpdates {
+		if !installedAppIds[appId] {
+			delete(availableUpdates, appId)
+		}
+	}
+}
+
+// getAvailableUpdateVersionName returns an empty string if no update is known for the installed version and the current
+// update policy of the app.
+func getAvailableUpdateVersionName(app tools.RepoApp) string {
+	availableUpdatesMutex.Lock()
+	defer availableUpdatesMutex.Unlock()
+	update, ok := availableUpdates[app.AppId]
+	if !ok || update.currentVersionName != app.VersionName || update.updatePolicy != app.UpdatePolicy ||
+		update.isPreReleaseChannelEnabled != app.IsPreReleaseChannelEnabled {
+		return ""
+	}
+	return update.versionName
+}
+
+func checkForAvailableUpdate(app tools.RepoApp) {
+	if common.IsOcelotDbApp(app) {
+		return
+	}
+	versions, err := common.StoreClient.GetVersions(strconv.Itoa(app.AppId))
+	if err != nil {
+		Logger.Warn("Failed to check for updates of app '%s / %s': %v", app.Maintainer, app.AppName, err)
+		return
+	}
+	update := availableUpdate{
+		currentVersionName:         app.VersionName,
+		updatePolicy:               app.UpdatePolicy,
+		isPreReleaseChannelEnabled: app.IsPreReleaseChannelEnabled,
+	}
+	if updateVersion := tools.SelectUpdateVersion(app, versions); updateVersion != nil {
+		update.versionName = updateVersion.Name
+		Logger.Info("Update available for app '%s / %s': %s -> %s", app.Maintainer, app.AppName, app.VersionName, updateVersion.Name)
+	}
+	availableUpdatesMutex.Lock()
+	defer availableUpdatesMutex.Unlock()
+	availableUpdates[app.AppId] = update
+}
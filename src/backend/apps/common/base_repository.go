@@ -30,6 +30,16 @@ func IsOcelotDbApp(app tools.RepoApp) bool {
 	return IsOcelotDb(app.Maintainer, app.AppName)
 }
 
+// GetOperationScope returns the scope of the app operation queue for an app. Operations on the database app affect all other
+// apps, since it is stopped during backups.
+func GetOperationScope(appId int) int {
+	app, err := AppRepo.GetApp(appId)
+	if err == nil && IsOcelotDbApp(*app) {
+		return tools.AllAppsScope
+	}
+	return appId
+}
+
 func IsOcelotDbDto(app tools.AppDto) bool {
 	return IsOcelotDb(app.Maintainer, app.AppName)
 }
@@ -119,6 +129,23 @@ func WipeWholeDatabase() {
 		Logger.Fatal("Database wipe failed: %v", err)
 	}
 
+	_, err = DB.Exec("DELETE FROM backup_statistics")
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+
+	_, err = DB.Exec("DELETE FROM jobs")
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+
+	_, err = DB.Exec("DELETE FROM maintenance_runs")
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+
+	WipeBackupRepositories()
+
 	_, err = DB.Exec(`
 		DELETE FROM apps 
 		WHERE NOT (maintainer = $1 AND app_name = $2)
@@ -127,3 +154,25 @@ func WipeWholeDatabase() {
 		Logger.Fatal("Database wipe failed: %v", err)
 	}
 }
+
+// WipeBackupRepositories deletes all remote backup repositories and resets the local one to its defaults.
+func WipeBackupRepositories() {
+	_, err := DB.Exec("DELETE FROM backup_repositories WHERE repository_id <> $1", tools.LocalBackupRepositoryId)
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+
+	_, err = DB.Exec(`
+		UPDATE backup_repositories 
+		SET is_enabled = true, backup_interval_days = 1, keep_daily = 7, keep_weekly = 4, keep_monthly = 12, last_auto_backup_date = '1970-01-01T00:00:00Z'
+		WHERE repository_id = $1
+	`, tools.LocalBackupRepositoryId)
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+
+	_, err = DB.Exec("SELECT setval(pg_get_serial_sequence('backup_repositories', 'repository_id'), $1)", tools.LocalBackupRepositoryId)
+	if err != nil {
+		Logger.Fatal("Database wipe failed: %v", err)
+	}
+}
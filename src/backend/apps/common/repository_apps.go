@@ -37,7 +37,7 @@ func (n AppRepository) GetAppId(maintainer, app string) (int, error) {
 func (n AppRepository) GetApp(appId int) (*tools.RepoApp, error) {
 	var app tools.RepoApp
 	var versionCreationTimestamp string
-	if err := DB.QueryRow("SELECT app_id, maintainer, app_name, version_name, version_creation_timestamp, version_content, should_be_running FROM apps WHERE app_id = $1", appId).Scan(&app.AppId, &app.Maintainer, &app.AppName, &app.VersionName, &versionCreationTimestamp, &app.VersionContent, &app.ShouldBeRunning); err != nil {
+	if err := DB.QueryRow("SELECT app_id, maintainer, app_name, version_name, version_creation_timestamp, version_content, should_be_running, is_auto_backup_enabled, is_auto_update_enabled, update_policy, is_pre_release_channel_enabled FROM apps WHERE app_id = $1", appId).Scan(&app.AppId, &app.Maintainer, &app.AppName, &app.VersionName, &versionCreationTimestamp, &app.VersionContent, &app.ShouldBeRunning, &app.IsAutoBackupEnabled, &app.IsAutoUpdateEnabled, &app.UpdatePolicy, &app.IsPreReleaseChannelEnabled); err != nil {
 		Logger.Error("failed to get app: %v", err)
 		return nil, fmt.Errorf("failed to get app")
 	}
@@ -52,7 +52,7 @@ func (n AppRepository) GetApp(appId int) (*tools.RepoApp, error) {
 
 func (n AppRepository) ListApps() ([]tools.RepoApp, error) {
 	var apps []tools.RepoApp
-	rows, err := DB.Query("SELECT app_id, maintainer, app_name, version_name, version_creation_timestamp, version_content, should_be_running FROM apps")
+	rows, err := DB.Query("SELECT app_id, maintainer, app_name, version_name, version_creation_timestamp, version_content, should_be_running, is_auto_backup_enabled, is_auto_update_enabled, update_policy, is_pre_release_channel_enabled FROM apps")
 	if err != nil {
 		Logger.Error("failed to list apps: %v", err)
 		return nil, fmt.Errorf("failed to list apps")
@@ -62,7 +62,7 @@ func (n AppRepository) ListApps() ([]tools.RepoApp, error) {
 	for rows.Next() {
 		var app tools.RepoApp
 		var versionCreationTimestamp string
-		if err := rows.Scan(&app.AppId, &app.Maintainer, &app.AppName, &app.VersionName, &versionCreationTimestamp, &app.VersionContent, &app.ShouldBeRunning); err != nil {
+		if err := rows.Scan(&app.AppId, &app.Maintainer, &app.AppName, &app.VersionName, &versionCreationTimestamp, &app.VersionContent, &app.ShouldBeRunning, &app.IsAutoBackupEnabled, &app.IsAutoUpdateEnabled, &app.UpdatePolicy, &app.IsPreReleaseChannelEnabled); err != nil {
 			Logger.Error("failed to scan app: %v", err)
 			return nil, fmt.Errorf("failed to scan app")
 		}
@@ -98,6 +98,22 @@ func (n AppRepository) SetAppShouldBeRunning(appId int, shouldBeRunning bool) er
 	return nil
 }
 
+func (n AppRepository) SetMaintenanceOverrides(appId int, isAutoBackupEnabled, isAutoUpdateEnabled bool) error {
+	if _, err := DB.Exec("UPDATE apps SET is_auto_backup_enabled = $1, is_auto_update_enabled = $2 WHERE app_id = $3", isAutoBackupEnabled, isAutoUpdateEnabled, appId); err != nil {
+		Logger.Error("failed to update maintenance overrides: %v", err)
+		return errors.New("failed to set maintenance overrides")
+	}
+	return nil
+}
+
+func (n AppRepository) SetUpdatePolicy(appId int, updatePolicy tools.UpdatePolicy, isPreReleaseChannelEnabled bool) error {
+	if _, err := DB.Exec("UPDATE apps SET update_policy = $1, is_pre_release_channel_enabled = $2 WHERE app_id = $3", updatePolicy, isPreReleaseChannelEnabled, appId); err != nil {
+		Logger.Error("failed to update update policy: %v", err)
+		return errors.New("failed to set update policy")
+	}
+	return nil
+}
+
 func (n AppRepository) UpdateVersion(appId int, version tools.VersionMetaData) error {
 	var timestamp = version.CreationTimestamp.Format(time.RFC3339)
 	if _, err := DB.Exec("UPDATE apps SET version_name = $1, version_creation_timestamp = $2, version_content = $3 WHERE app_id = $4",
@@ -89,6 +89,46 @@ func TestSetShouldBeRunning(t *testing.T) {
 	assert.True(t, app.ShouldBeRunning)
 }
 
+func TestSetMaintenanceOverrides(t *testing.T) {
+	defer WipeWholeDatabase()
+	appId := createSampleAppAndReturnRepoId(t)
+
+	app, err := AppRepo.GetApp(appId)
+	assert.Nil(t, err)
+	assert.True(t, app.IsAutoBackupEnabled)
+	assert.True(t, app.IsAutoUpdateEnabled)
+
+	assert.Nil(t, AppRepo.SetMaintenanceOverrides(appId, false, true))
+	app, err = AppRepo.GetApp(appId)
+	assert.Nil(t, err)
+	assert.False(t, app.IsAutoBackupEnabled)
+	assert.True(t, app.IsAutoUpdateEnabled)
+
+	assert.Nil(t, AppRepo.SetMaintenanceOverrides(appId, true, false))
+	apps, err := AppRepo.ListApps()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(apps))
+	assert.True(t, apps[0].IsAutoBackupEnabled)
+	assert.False(t, apps[0].IsAutoUpdateEnabled)
+}
+
+func TestSetUpdatePolicy(t *testing.T) {
+	defer WipeWholeDatabase()
+	appId := createSampleAppAndReturnRepoId(t)
+
+	app, err := AppRepo.GetApp(appId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.DefaultUpdatePolicy, app.UpdatePolicy)
+	assert.False(t, app.IsPreReleaseChannelEnabled)
+
+	assert.Nil(t, AppRepo.SetUpdatePolicy(appId, tools.UpdatePolicyPatch, true))
+	apps, err := AppRepo.ListApps()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(apps))
+	assert.Equal(t, tools.UpdatePolicyPatch, apps[0].UpdatePolicy)
+	assert.True(t, apps[0].IsPreReleaseChannelEnabled)
+}
+
 func TestUpdateVersion(t *testing.T) {
 	defer WipeWholeDatabase()
 	appId := createSampleAppAndReturnRepoId(t)
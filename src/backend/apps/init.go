@@ -20,6 +20,9 @@ func InitializeAppsModule() {
 		{Path: tools.AppsListPath, HandlerFunc: cloud.AppListHandler, AccessLevel: security.User},
 		{Path: tools.AppsStartPath, HandlerFunc: cloud.AppStartHandler, AccessLevel: security.Admin},
 		{Path: tools.AppsStopPath, HandlerFunc: cloud.AppStopHandler, AccessLevel: security.Admin},
+		{Path: tools.AppsMaintenancePath, HandlerFunc: cloud.AppMaintenanceOverridesHandler, AccessLevel: security.Admin},
+		{Path: tools.AppsUpdatePolicyPath, HandlerFunc: cloud.AppUpdatePolicyHandler, AccessLevel: security.Admin},
+		{Path: tools.AppsCheckUpdatesPath, HandlerFunc: cloud.CheckForAvailableUpdatesHandler, AccessLevel: security.Admin},
 		{Path: tools.AppsPrunePath, HandlerFunc: backups.AppPruneHandler, AccessLevel: security.Admin},
 		{Path: tools.AppsUpdatePath, HandlerFunc: backups.VersionUpdateHandler, AccessLevel: security.Admin},
 
@@ -0,0 +1,3 @@
+#!/bin/bash
+
+docker compose -f docker-compose.dummy-s3.yml up -d
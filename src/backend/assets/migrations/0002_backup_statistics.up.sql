@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS backup_statistics (
+    snapshot_id TEXT PRIMARY KEY,
+    files_new INTEGER NOT NULL,
+    files_changed INTEGER NOT NULL,
+    files_unmodified INTEGER NOT NULL,
+    data_added BIGINT NOT NULL,
+    total_files_processed INTEGER NOT NULL,
+    total_bytes_processed BIGINT NOT NULL,
+    total_duration_seconds DOUBLE PRECISION NOT NULL
+);
@@ -0,0 +1,54 @@
+CREATE TABLE IF NOT EXISTS backup_repositories (
+    repository_id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    type TEXT NOT NULL,
+    is_enabled BOOLEAN NOT NULL,
+    backup_interval_days INTEGER NOT NULL,
+    keep_daily INTEGER NOT NULL,
+    keep_weekly INTEGER NOT NULL,
+    keep_monthly INTEGER NOT NULL,
+    last_auto_backup_date TEXT NOT NULL DEFAULT '1970-01-01T00:00:00Z',
+    host TEXT NOT NULL DEFAULT '',
+    ssh_port TEXT NOT NULL DEFAULT '',
+    ssh_user TEXT NOT NULL DEFAULT '',
+    ssh_password TEXT NOT NULL DEFAULT '',
+    ssh_known_hosts TEXT NOT NULL DEFAULT '',
+    s3_endpoint TEXT NOT NULL DEFAULT '',
+    s3_bucket TEXT NOT NULL DEFAULT '',
+    s3_prefix TEXT NOT NULL DEFAULT '',
+    s3_region TEXT NOT NULL DEFAULT '',
+    s3_access_key TEXT NOT NULL DEFAULT '',
+    s3_secret_key TEXT NOT NULL DEFAULT '',
+    encryption_password TEXT NOT NULL DEFAULT ''
+);
+
+-- the local repository always has the id 1 and can not be deleted
+INSERT INTO backup_repositories (repository_id, name, type, is_enabled, backup_interval_days, keep_daily, keep_weekly, keep_monthly)
+VALUES (1, 'local', 'local', true, 1, 7, 4, 12)
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('backup_repositories', 'repository_id'), (SELECT MAX(repository_id) FROM backup_repositories));
+
+-- takes over the single remote repository, which was stored in the configs table before
+INSERT INTO backup_repositories (name, type, is_enabled, backup_interval_days, keep_daily, keep_weekly, keep_monthly,
+                                 host, ssh_port, ssh_user, ssh_password, ssh_known_hosts,
+                                 s3_endpoint, s3_bucket, s3_prefix, s3_region, s3_access_key, s3_secret_key, encryption_password)
+SELECT 'remote',
+       COALESCE(NULLIF((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_TYPE'), ''), 'sftp'),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_ENABLED') = 'true', false),
+       1, 7, 4, 12,
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_HOST'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_SSH_PORT'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_SSH_USER'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_SSH_PASSWORD'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_SSH_KNOWN_HOSTS'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_S3_ENDPOINT'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_S3_BUCKET'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_S3_PREFIX'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_S3_REGION'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_S3_ACCESS_KEY'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_S3_SECRET_KEY'), ''),
+       COALESCE((SELECT value FROM configs WHERE key = 'REMOTE_BACKUP_ENCRYPTION_PASSWORD'), '')
+WHERE EXISTS (SELECT 1 FROM configs WHERE key = 'REMOTE_BACKUP_HOST' AND value <> '')
+   OR EXISTS (SELECT 1 FROM configs WHERE key = 'REMOTE_BACKUP_S3_BUCKET' AND value <> '');
+
+DELETE FROM configs WHERE key LIKE 'REMOTE_BACKUP_%';
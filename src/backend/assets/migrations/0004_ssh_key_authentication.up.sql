@@ -0,0 +1 @@
+ALTER TABLE backup_repositories ADD COLUMN IF NOT EXISTS ssh_auth_method TEXT NOT NULL DEFAULT 'password';
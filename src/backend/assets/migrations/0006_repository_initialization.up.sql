@@ -0,0 +1,3 @@
+ALTER TABLE backup_repositories ADD COLUMN IF NOT EXISTS is_initialized BOOLEAN NOT NULL DEFAULT FALSE;
+-- remote repositories which already have an encryption password have most likely been used, so their password must not be overwritten
+UPDATE backup_repositories SET is_initialized = TRUE WHERE type <> 'local' AND encryption_password <> '';
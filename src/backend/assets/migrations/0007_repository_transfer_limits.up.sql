@@ -0,0 +1,5 @@
+ALTER TABLE backup_repositories ADD COLUMN IF NOT EXISTS upload_limit_kib INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE backup_repositories ADD COLUMN IF NOT EXISTS download_limit_kib INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE backup_repositories ADD COLUMN IF NOT EXISTS is_transfer_window_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE backup_repositories ADD COLUMN IF NOT EXISTS transfer_window_start_hour INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE backup_repositories ADD COLUMN IF NOT EXISTS transfer_window_end_hour INTEGER NOT NULL DEFAULT 0;
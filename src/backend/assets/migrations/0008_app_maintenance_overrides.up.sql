@@ -0,0 +1,2 @@
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS is_auto_backup_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS is_auto_update_enabled BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS maintenance_runs (
+    run_id SERIAL PRIMARY KEY,
+    triggered_by TEXT NOT NULL,
+    status TEXT NOT NULL,
+    started_at TEXT NOT NULL,
+    finished_at TEXT NOT NULL DEFAULT '',
+    retention_outcome TEXT NOT NULL DEFAULT '',
+    retention_reason TEXT NOT NULL DEFAULT '',
+    problems_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_run_app_results (
+    result_id SERIAL PRIMARY KEY,
+    run_id INTEGER NOT NULL REFERENCES maintenance_runs (run_id) ON DELETE CASCADE,
+    maintainer TEXT NOT NULL,
+    app_name TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,2 @@
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS update_policy TEXT NOT NULL DEFAULT 'major';
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS is_pre_release_channel_enabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -4,6 +4,7 @@ import (
 	"github.com/ocelot-cloud/shared/assert"
 	"ocelot/backend/apps/common"
 	"testing"
+	"time"
 )
 
 func TestCertManagement(t *testing.T) {
@@ -30,3 +31,12 @@ func TestCertManagement(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, *originalCert, *loadedCert)
 }
+
+func TestGetCertificateInfoOfSelfSignedCert(t *testing.T) {
+	cert, err := GenerateUniversalSelfSignedCert()
+	assert.Nil(t, err)
+	info, err := getCertificateInfo(cert)
+	assert.Nil(t, err)
+	assert.True(t, info.IsSelfSigned)
+	assert.True(t, info.ExpiryDate.After(time.Now().AddDate(0, 11, 0)))
+}
@@ -2,11 +2,13 @@ package certs
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"github.com/ocelot-cloud/shared/validation"
 	"log"
 	"math/big"
@@ -27,10 +29,13 @@ var (
 	Logger      = tools.Logger
 	currentCert *tls.Certificate
 	rwCertMutex sync.RWMutex
+	// set by StartServers, so that they can be shut down gracefully
+	httpServer, tlsServer *http.Server
 )
 
+// StartServers starts the HTTP and the TLS server in the background.
 func StartServers(handler http.Handler) {
-	server := &http.Server{
+	httpServer = &http.Server{
 		Addr:              ":8080",
 		Handler:           handler,
 		ReadHeaderTimeout: 2 * time.Second,
@@ -39,14 +44,27 @@ func StartServers(handler http.Handler) {
 		IdleTimeout:       10 * time.Minute,
 	}
 	go func() {
-		err := server.ListenAndServe()
-		if err != nil {
+		err := httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			Logger.Error("HTTP server failed: %v", err)
 		}
 	}()
 	startTlsServer(handler)
 }
 
+// ShutdownServers stops accepting new connections and waits until active requests are finished or the context expires.
+func ShutdownServers(ctx context.Context) {
+	for _, server := range []*http.Server{httpServer, tlsServer} {
+		if server == nil {
+			continue
+		}
+		err := server.Shutdown(ctx)
+		if err != nil {
+			Logger.Warn("Failed to shut down server on %s gracefully: %v", server.Addr, err)
+		}
+	}
+}
+
 func startTlsServer(handler http.Handler) {
 	isPresent, err := isCertPresent()
 	if err != nil {
@@ -58,7 +76,7 @@ func startTlsServer(handler http.Handler) {
 		currentCert = generateSelfSignedCertAndSaveToDatabase()
 	}
 
-	srv := &http.Server{
+	tlsServer = &http.Server{
 		Addr:    ":8443",
 		Handler: handler,
 		TLSConfig: &tls.Config{
@@ -71,10 +89,12 @@ func startTlsServer(handler http.Handler) {
 		IdleTimeout:       10 * time.Minute,
 		ErrorLog:          log.New(errorLoggerWhichDropsBadCertMessages{}, "", 0),
 	}
-	err = srv.ListenAndServeTLS("", "")
-	if err != nil {
-		Logger.Error("Failed to start tls server: %v", err)
-	}
+	go func() {
+		err := tlsServer.ListenAndServeTLS("", "")
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			Logger.Fatal("Failed to start tls server: %v", err)
+		}
+	}()
 }
 
 type errorLoggerWhichDropsBadCertMessages struct{}
@@ -281,3 +301,34 @@ func ConvertToFullchainPemBytes(clientCert *tls.Certificate) ([]byte, error) {
 	pemData := append(certPEM, keyPEM...)
 	return pemData, nil
 }
+
+type CertificateInfo struct {
+	ExpiryDate   time.Time
+	IsSelfSigned bool
+}
+
+// GetCertificateInfo describes the certificate currently served, so that its expiry can be monitored.
+func GetCertificateInfo() (*CertificateInfo, error) {
+	rwCertMutex.RLock()
+	cert := currentCert
+	rwCertMutex.RUnlock()
+	if cert == nil {
+		var err error
+		cert, err = loadCert()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return getCertificateInfo(cert)
+}
+
+func getCertificateInfo(cert *tls.Certificate) (*CertificateInfo, error) {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	return &CertificateInfo{
+		ExpiryDate:   leaf.NotAfter,
+		IsSelfSigned: bytes.Equal(leaf.RawIssuer, leaf.RawSubject),
+	}, nil
+}
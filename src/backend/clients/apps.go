@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"fmt"
 	"net/http"
 	"ocelot/backend/apps/common"
 	"ocelot/backend/tools"
@@ -11,6 +12,7 @@ var Apps AppManager
 type AppManager interface {
 	StartApp(appId int) error
 	StopApp(appId int) error
+	RunCommandInAppService(appId int, service, command string) error
 	ProxyRequestToTheAppsDockerContainer(w http.ResponseWriter, r *http.Request)
 }
 
@@ -40,6 +42,17 @@ func (m *MockAppManager) StopApp(appId int) error {
 	return nil
 }
 
+func (m *MockAppManager) RunCommandInAppService(appId int, service, command string) error {
+	app, err := common.AppRepo.GetApp(appId)
+	if err != nil {
+		return err
+	}
+	if !app.ShouldBeRunning {
+		return fmt.Errorf("can't run command in service '%s' since the app is not running", service)
+	}
+	return nil
+}
+
 func (m *MockAppManager) ProxyRequestToTheAppsDockerContainer(w http.ResponseWriter, r *http.Request) {
 	appId, err := common.AppRepo.GetAppId(tools.SampleMaintainer, tools.SampleApp)
 	if err != nil {
@@ -1,13 +1,16 @@
 package clients
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"ocelot/backend/apps/common"
+	"ocelot/backend/repositories"
 	"ocelot/backend/security"
-	"ocelot/backend/ssh"
 	"ocelot/backend/tools"
-	"time"
+	"strconv"
 )
 
 var (
@@ -16,21 +19,31 @@ var (
 
 type BackupManagerInterface interface {
 	CreateBackup(appId int, description tools.BackupDescription) error
-	DeleteBackup(backupId string, isLocalBackup bool) error
+	CreateBackupInRepositories(appId int, description tools.BackupDescription, repositoryIds []int) error
+	CreateBackupWithProgress(appId int, description tools.BackupDescription, progress tools.ProgressReporter) error
+	DeleteBackup(backupId string, repositoryId int) error
 	RestoreBackup(backupRestoreRequest tools.BackupOperationRequest) (*tools.RestoredVersionInfo, error)
+	RestoreBackupWithProgress(backupRestoreRequest tools.BackupOperationRequest, progress tools.ProgressReporter) (*tools.RestoredVersionInfo, error)
+	RestoreBackupAsClone(request tools.BackupCloneRequest) (*tools.RestoredVersionInfo, error)
+	ExportBackup(w http.ResponseWriter, request tools.BackupExportRequest) error
+	ImportBackup(archive io.Reader, password string) error
 	UpdateAppVersion(appId int) error
 	PruneApp(appId int) error
 
 	ListBackupsOfApp(request tools.BackupListRequest) ([]tools.BackupInfo, error)
-	ListAppsInBackupRepo(isLocalBackup bool) ([]tools.MaintainerAndApp, error)
+	ListAppsInBackupRepo(repositoryId int) ([]tools.MaintainerAndApp, error)
+	ListBackupFiles(request tools.BackupFileRequest) ([]tools.BackupFileInfo, error)
+	DownloadBackupFiles(w http.ResponseWriter, request tools.BackupFileRequest) error
 	RunRetentionPolicy() error
+	CheckRepository(repositoryId int) error
+	RotateRepositoryPassword(repositoryId int, newPassword string) error
 }
 
 type backupFullInfo struct {
 	backupInfo     tools.BackupInfo
 	versionContent []byte
 	users          []tools.UserFullInfo
-	isLocal        bool
+	repositoryId   int
 }
 
 type MockBackupManager struct {
@@ -47,29 +60,29 @@ func (m *MockBackupManager) UpdateAppVersion(appId int) error {
 	if app.Maintainer != tools.SampleMaintainer || app.AppName != tools.SampleApp {
 		return fmt.Errorf("mock can only update sample app")
 	}
-	if app.VersionName == tools.SampleAppVersion1Name {
-		err = BackupManager.CreateBackup(appId, tools.AutoBackupDescription)
-		if err != nil {
-			return err
-		}
-		versionMetaData := tools.VersionMetaData{
-			Name:              tools.SampleAppVersion2Name,
-			CreationTimestamp: time.Now().UTC(),
-			Content:           tools.GetSampleAppContent(),
-		}
-		err = common.AppRepo.UpdateVersion(appId, versionMetaData)
-		if err != nil {
-			return err
-		}
-		return nil
-	} else {
+	versions, err := common.StoreClient.GetVersions(strconv.Itoa(appId))
+	if err != nil {
+		return err
+	}
+	updateVersion := tools.SelectUpdateVersion(*app, versions)
+	if updateVersion == nil {
 		msg := GetUpdateErrorString(*app)
 		return errors.New(msg)
 	}
+	err = BackupManager.CreateBackup(appId, tools.AutoBackupDescription)
+	if err != nil {
+		return err
+	}
+	versionMetaData := tools.VersionMetaData{
+		Name:              updateVersion.Name,
+		CreationTimestamp: updateVersion.VersionCreationTimestamp,
+		Content:           tools.GetSampleAppContent(),
+	}
+	return common.AppRepo.UpdateVersion(appId, versionMetaData)
 }
 
 func GetUpdateErrorString(app tools.RepoApp) string {
-	return fmt.Sprintf("can't update app '%s / %s' because the latest version '%s' is already installed", app.Maintainer, app.AppName, app.VersionName)
+	return fmt.Sprintf("can't update app '%s / %s' because no newer version than '%s' is available within its update policy", app.Maintainer, app.AppName, app.VersionName)
 }
 
 func (m *MockBackupManager) PruneApp(appId int) error {
@@ -77,6 +90,27 @@ func (m *MockBackupManager) PruneApp(appId int) error {
 }
 
 func (m *MockBackupManager) CreateBackup(appId int, description tools.BackupDescription) error {
+	enabledRepositories, err := repositories.BackupRepositoryRepo.ListEnabledRepositories()
+	if err != nil {
+		return err
+	}
+	var repositoryIds []int
+	for _, repository := range enabledRepositories {
+		repositoryIds = append(repositoryIds, repository.Id)
+	}
+	return m.CreateBackupInRepositories(appId, description, repositoryIds)
+}
+
+func (m *MockBackupManager) CreateBackupWithProgress(appId int, description tools.BackupDescription, progress tools.ProgressReporter) error {
+	err := m.CreateBackup(appId, description)
+	if err != nil {
+		return err
+	}
+	progress.Report(tools.JobProgress{PercentDone: 1})
+	return nil
+}
+
+func (m *MockBackupManager) CreateBackupInRepositories(appId int, description tools.BackupDescription, repositoryIds []int) error {
 	app, err := common.AppRepo.GetApp(appId)
 	if err != nil {
 		return err
@@ -88,13 +122,18 @@ func (m *MockBackupManager) CreateBackup(appId int, description tools.BackupDesc
 		VersionName:              app.VersionName,
 		VersionCreationTimestamp: app.VersionCreationTimestamp,
 		Description:              description,
-		BackupCreationTimestamp:  time.Now().UTC(),
+		BackupCreationTimestamp:  tools.Clock.Now().UTC(),
+		Statistics: &tools.BackupStatistics{
+			FilesNew:            1,
+			DataAdded:           int64(len(app.VersionContent)),
+			TotalFilesProcessed: 1,
+			TotalBytesProcessed: int64(len(app.VersionContent)),
+		},
 	}
 	backupIdSource++
 	backup := backupFullInfo{
 		backupInfo:     newBackup,
 		versionContent: app.VersionContent,
-		isLocal:        true,
 	}
 
 	if common.IsOcelotDbApp(*app) {
@@ -105,31 +144,27 @@ func (m *MockBackupManager) CreateBackup(appId int, description tools.BackupDesc
 		backup.users = users
 	}
 
-	m.Backups = append(m.Backups, backup)
-	isRemoteBackupEnabled, err := ssh.IsRemoteBackupEnabled()
-	if err != nil {
-		return err
-	} else if isRemoteBackupEnabled {
-		backup.isLocal = false
+	for _, repositoryId := range repositoryIds {
+		backup.repositoryId = repositoryId
 		m.Backups = append(m.Backups, backup)
 	}
-	return err
+	return nil
 }
 
 func (m *MockBackupManager) ListBackupsOfApp(backupListRequest tools.BackupListRequest) ([]tools.BackupInfo, error) {
 	var appBackups []tools.BackupInfo
 	for _, backup := range m.Backups {
-		if backup.backupInfo.Maintainer == backupListRequest.Maintainer && backup.backupInfo.AppName == backupListRequest.AppName && backup.isLocal == backupListRequest.IsLocal {
+		if backup.backupInfo.Maintainer == backupListRequest.Maintainer && backup.backupInfo.AppName == backupListRequest.AppName && backup.repositoryId == backupListRequest.RepositoryId {
 			appBackups = append(appBackups, backup.backupInfo)
 		}
 	}
 	return appBackups, nil
 }
 
-func (m *MockBackupManager) DeleteBackup(backupId string, isLocalBackup bool) error {
+func (m *MockBackupManager) DeleteBackup(backupId string, repositoryId int) error {
 	var wasBackupFound = false
 	for i, backup := range m.Backups {
-		if backup.backupInfo.BackupId == backupId && backup.isLocal == isLocalBackup {
+		if backup.backupInfo.BackupId == backupId && backup.repositoryId == repositoryId {
 			wasBackupFound = true
 			m.Backups = append(m.Backups[:i], m.Backups[i+1:]...)
 		}
@@ -144,7 +179,7 @@ func (m *MockBackupManager) DeleteBackup(backupId string, isLocalBackup bool) er
 func (m *MockBackupManager) RestoreBackup(request tools.BackupOperationRequest) (*tools.RestoredVersionInfo, error) {
 	var backup *backupFullInfo
 	for _, currentBackup := range m.Backups {
-		if currentBackup.backupInfo.BackupId == request.BackupId && currentBackup.isLocal == request.IsLocal {
+		if currentBackup.backupInfo.BackupId == request.BackupId && currentBackup.repositoryId == request.RepositoryId {
 			backup = &currentBackup
 		}
 	}
@@ -190,10 +225,10 @@ func (m *MockBackupManager) RestoreBackup(request tools.BackupOperationRequest)
 	return restoredVersionInfo, nil
 }
 
-func (m *MockBackupManager) ListAppsInBackupRepo(isLocalBackup bool) ([]tools.MaintainerAndApp, error) {
+func (m *MockBackupManager) ListAppsInBackupRepo(repositoryId int) ([]tools.MaintainerAndApp, error) {
 	var allFoundApps []tools.MaintainerAndApp
 	for _, backup := range m.Backups {
-		if backup.isLocal == isLocalBackup {
+		if backup.repositoryId == repositoryId {
 			allFoundApps = append(allFoundApps, tools.MaintainerAndApp{
 				Maintainer: backup.backupInfo.Maintainer,
 				AppName:    backup.backupInfo.AppName,
@@ -203,7 +238,152 @@ func (m *MockBackupManager) ListAppsInBackupRepo(isLocalBackup bool) ([]tools.Ma
 	return tools.FindUniqueMaintainerAndAppNamePairs(allFoundApps), nil
 }
 
+func (m *MockBackupManager) RestoreBackupWithProgress(request tools.BackupOperationRequest, progress tools.ProgressReporter) (*tools.RestoredVersionInfo, error) {
+	restoredVersionInfo, err := m.RestoreBackup(request)
+	if err != nil {
+		return nil, err
+	}
+	progress.Report(tools.JobProgress{PercentDone: 1})
+	return restoredVersionInfo, nil
+}
+
+func (m *MockBackupManager) RestoreBackupAsClone(request tools.BackupCloneRequest) (*tools.RestoredVersionInfo, error) {
+	backup := m.findBackup(request.BackupId, request.RepositoryId)
+	if backup == nil {
+		return nil, fmt.Errorf("backup id does not exist")
+	}
+	if backup.backupInfo.AppName == request.NewAppName {
+		return nil, fmt.Errorf("clone must have a different name than the original app")
+	}
+	if _, err := common.AppRepo.GetAppId(backup.backupInfo.Maintainer, request.NewAppName); err == nil {
+		return nil, fmt.Errorf("app with name of clone already exists")
+	}
+
+	clonedApp := tools.RepoApp{
+		Maintainer:               backup.backupInfo.Maintainer,
+		AppName:                  request.NewAppName,
+		VersionName:              backup.backupInfo.VersionName,
+		VersionCreationTimestamp: backup.backupInfo.VersionCreationTimestamp,
+		VersionContent:           backup.versionContent,
+		ShouldBeRunning:          true,
+	}
+	err := common.AppRepo.CreateApp(clonedApp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tools.RestoredVersionInfo{
+		Maintainer:     clonedApp.Maintainer,
+		AppName:        clonedApp.AppName,
+		VersionName:    clonedApp.VersionName,
+		VersionContent: clonedApp.VersionContent,
+	}, nil
+}
+
+// The mock archive is not encrypted, it just contains the password to simulate a failing import when using a wrong one.
+type mockBackupArchive struct {
+	Password       string
+	BackupInfo     tools.BackupInfo
+	VersionContent []byte
+}
+
+func (m *MockBackupManager) ExportBackup(w http.ResponseWriter, request tools.BackupExportRequest) error {
+	backup := m.findBackup(request.BackupId, request.RepositoryId)
+	if backup == nil {
+		return fmt.Errorf("backup id does not exist")
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="backup.ocelotbackup"`)
+	return json.NewEncoder(w).Encode(mockBackupArchive{
+		Password:       request.Password,
+		BackupInfo:     backup.backupInfo,
+		VersionContent: backup.versionContent,
+	})
+}
+
+func (m *MockBackupManager) ImportBackup(archive io.Reader, password string) error {
+	var mockArchive mockBackupArchive
+	if err := json.NewDecoder(archive).Decode(&mockArchive); err != nil {
+		return fmt.Errorf("file is not a backup archive")
+	}
+	if mockArchive.Password != password {
+		return fmt.Errorf("archive is corrupted or password is wrong")
+	}
+
+	backupInfo := mockArchive.BackupInfo
+	backupInfo.BackupId = fmt.Sprintf("%064d", backupIdSource)
+	backupIdSource++
+	m.Backups = append(m.Backups, backupFullInfo{
+		backupInfo:     backupInfo,
+		versionContent: mockArchive.VersionContent,
+		repositoryId:   tools.LocalBackupRepositoryId,
+	})
+	return nil
+}
+
+var mockBackupFileContent = "hello"
+
+func (m *MockBackupManager) findBackup(backupId string, repositoryId int) *backupFullInfo {
+	for _, backup := range m.Backups {
+		if backup.backupInfo.BackupId == backupId && backup.repositoryId == repositoryId {
+			return &backup
+		}
+	}
+	return nil
+}
+
+// The mock pretends that each backup contains a single volume with a single file.
+func (m *MockBackupManager) ListBackupFiles(request tools.BackupFileRequest) ([]tools.BackupFileInfo, error) {
+	backup := m.findBackup(request.BackupId, request.RepositoryId)
+	if backup == nil {
+		return nil, fmt.Errorf("backup id does not exist")
+	}
+	volumeName := backup.backupInfo.Maintainer + "_" + backup.backupInfo.AppName + "_data"
+	switch request.Path {
+	case "", "/":
+		return []tools.BackupFileInfo{{Name: volumeName, Path: "/" + volumeName, Type: "dir"}}, nil
+	case "/" + volumeName:
+		return []tools.BackupFileInfo{{Name: "hello.txt", Path: "/" + volumeName + "/hello.txt", Type: "file", Size: int64(len(mockBackupFileContent))}}, nil
+	default:
+		return nil, fmt.Errorf("path does not exist in backup")
+	}
+}
+
+func (m *MockBackupManager) DownloadBackupFiles(w http.ResponseWriter, request tools.BackupFileRequest) error {
+	backup := m.findBackup(request.BackupId, request.RepositoryId)
+	if backup == nil {
+		return fmt.Errorf("backup id does not exist")
+	}
+	if request.Path != "/"+backup.backupInfo.Maintainer+"_"+backup.backupInfo.AppName+"_data/hello.txt" {
+		return fmt.Errorf("only the sample file can be downloaded from mock backups")
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="hello.txt"`)
+	tools.WriteResponse(w, mockBackupFileContent)
+	return nil
+}
+
 func (m *MockBackupManager) RunRetentionPolicy() error {
 	// only needed for real backup manager
 	return nil
 }
+
+func (m *MockBackupManager) CheckRepository(repositoryId int) error {
+	// only needed for real backup manager
+	return nil
+}
+
+func (m *MockBackupManager) RotateRepositoryPassword(repositoryId int, newPassword string) error {
+	repository, err := repositories.BackupRepositoryRepo.GetRepository(repositoryId)
+	if err != nil {
+		return err
+	}
+	if repository.IsLocal() {
+		return fmt.Errorf("the password of the local backup repository can not be changed")
+	}
+	err = repositories.BackupRepositoryRepo.SetEncryptionPassword(repositoryId, newPassword)
+	if err != nil {
+		return err
+	}
+	return repositories.BackupRepositoryRepo.SetRepositoryInitialized(repositoryId, true)
+}
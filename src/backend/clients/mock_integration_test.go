@@ -6,6 +6,7 @@ import (
 	"github.com/ocelot-cloud/shared/assert"
 	"net/http/httptest"
 	"ocelot/backend/apps/common"
+	"ocelot/backend/repositories"
 	"ocelot/backend/security"
 	"ocelot/backend/ssh"
 	"ocelot/backend/tools"
@@ -81,8 +82,8 @@ func TestSampleAppBackupCreationAndRestoration(t *testing.T) {
 	assert.True(t, time.Now().UTC().Add(+1*time.Second).After(backup.BackupCreationTimestamp))
 
 	restoredBackupInfo, err := BackupManager.RestoreBackup(tools.BackupOperationRequest{
-		BackupId: backup.BackupId,
-		IsLocal:  true,
+		BackupId:     backup.BackupId,
+		RepositoryId: tools.LocalBackupRepositoryId,
 	})
 	assert.Nil(t, err)
 	assert.Equal(t, tools.SampleMaintainer, restoredBackupInfo.Maintainer)
@@ -94,7 +95,7 @@ func TestSampleAppBackupCreationAndRestoration(t *testing.T) {
 	assert.Nil(t, err)
 	assertAppRunning(t, appId, true)
 
-	assert.Nil(t, BackupManager.DeleteBackup(backup.BackupId, true))
+	assert.Nil(t, BackupManager.DeleteBackup(backup.BackupId, tools.LocalBackupRepositoryId))
 	backups, err = BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestLocal)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(backups))
@@ -127,15 +128,15 @@ func TestPostgresBackupCreationAndRestoration(t *testing.T) {
 	assert.True(t, time.Now().UTC().Add(+1*time.Second).After(backup.BackupCreationTimestamp))
 
 	restoredBackupInfo, err := BackupManager.RestoreBackup(tools.BackupOperationRequest{
-		BackupId: backup.BackupId,
-		IsLocal:  true,
+		BackupId:     backup.BackupId,
+		RepositoryId: tools.LocalBackupRepositoryId,
 	})
 	assert.Nil(t, err)
 	assert.Equal(t, tools.OcelotDbMaintainer, restoredBackupInfo.Maintainer)
 	assert.Equal(t, tools.OcelotDbAppName, restoredBackupInfo.AppName)
 	assert.Equal(t, common.PostgresVersion, restoredBackupInfo.VersionName)
 
-	assert.Nil(t, BackupManager.DeleteBackup(backup.BackupId, true))
+	assert.Nil(t, BackupManager.DeleteBackup(backup.BackupId, tools.LocalBackupRepositoryId))
 	_, err = common.AppRepo.GetAppId(tools.OcelotDbMaintainer, tools.OcelotDbAppName)
 	assert.Nil(t, err)
 
@@ -238,8 +239,8 @@ func TestRestorationRecreatesUsersFromBackup(t *testing.T) {
 	backup := backups[0]
 
 	_, err = BackupManager.RestoreBackup(tools.BackupOperationRequest{
-		BackupId: backup.BackupId,
-		IsLocal:  true,
+		BackupId:     backup.BackupId,
+		RepositoryId: tools.LocalBackupRepositoryId,
 	})
 	assert.Nil(t, err)
 
@@ -267,29 +268,36 @@ func TestLocalVsRemoteBackup(t *testing.T) {
 	remoteBackups, err := BackupManager.ListBackupsOfApp(tools.SampleAppBackupListRequestRemote)
 	assert.Nil(t, err)
 	remoteBackup := remoteBackups[0]
-	assert.Nil(t, BackupManager.DeleteBackup(remoteBackup.BackupId, false))
+	assert.Nil(t, BackupManager.DeleteBackup(remoteBackup.BackupId, tools.SampleRemoteBackupRepositoryId))
 	assertNumberOfBackups(t, tools.SampleAppBackupListRequestRemote, 2, 0)
 }
 
+func TestCreateBackupInSelectedRepositories(t *testing.T) {
+	defer cleanup()
+	assert.Nil(t, common.AppRepo.CreateApp(common.GetSampleAppInfo()))
+	appId, err := common.AppRepo.GetAppId(tools.SampleMaintainer, tools.SampleApp)
+	assert.Nil(t, err)
+	enableRemoteBackupRepo(t)
+
+	assert.Nil(t, BackupManager.CreateBackupInRepositories(appId, tools.AutoBackupDescription, []int{tools.SampleRemoteBackupRepositoryId}))
+	assertNumberOfBackups(t, tools.SampleAppBackupListRequestRemote, 0, 1)
+	assert.Nil(t, BackupManager.CreateBackupInRepositories(appId, tools.AutoBackupDescription, []int{tools.LocalBackupRepositoryId}))
+	assertNumberOfBackups(t, tools.SampleAppBackupListRequestRemote, 1, 1)
+}
+
 func enableRemoteBackupRepo(t *testing.T) {
-	err := ssh.SetRemoteBackupRepository(tools.RemoteBackupRepository{
-		IsEnabled:          true,
-		Host:               "",
-		SshPort:            "",
-		SshUser:            "",
-		SshPassword:        "",
-		EncryptionPassword: "",
-	})
+	repositoryId, err := repositories.BackupRepositoryRepo.CreateRepository(ssh.GetSampleRemoteRepo())
 	assert.Nil(t, err)
+	assert.Equal(t, tools.SampleRemoteBackupRepositoryId, repositoryId)
 }
 
 func assertNumberOfBackups(t *testing.T, sampleAppBackupRequest tools.BackupListRequest, expectedLocalNumber, expectedRemoteNumber int) {
-	sampleAppBackupRequest.IsLocal = true
+	sampleAppBackupRequest.RepositoryId = tools.LocalBackupRepositoryId
 	backups, err := BackupManager.ListBackupsOfApp(sampleAppBackupRequest)
 	assert.Nil(t, err)
 	assert.Equal(t, expectedLocalNumber, len(backups))
 
-	sampleAppBackupRequest.IsLocal = false
+	sampleAppBackupRequest.RepositoryId = tools.SampleRemoteBackupRepositoryId
 	backups, err = BackupManager.ListBackupsOfApp(sampleAppBackupRequest)
 	assert.Nil(t, err)
 	assert.Equal(t, expectedRemoteNumber, len(backups))
@@ -309,17 +317,17 @@ func TestListAppsInBackupRepoMock(t *testing.T) {
 	appId, err := common.AppRepo.GetAppId(tools.SampleMaintainer, tools.SampleApp)
 	assert.Nil(t, err)
 
-	repo, err := BackupManager.ListAppsInBackupRepo(true)
+	repo, err := BackupManager.ListAppsInBackupRepo(tools.LocalBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(repo))
 
 	assert.Nil(t, BackupManager.CreateBackup(appId, tools.ManualBackupDescription))
-	repo, err = BackupManager.ListAppsInBackupRepo(true)
+	repo, err = BackupManager.ListAppsInBackupRepo(tools.LocalBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(repo))
 	assert.Equal(t, repo[0].Maintainer, tools.SampleMaintainer)
 	assert.Equal(t, repo[0].AppName, tools.SampleApp)
-	repo, err = BackupManager.ListAppsInBackupRepo(false)
+	repo, err = BackupManager.ListAppsInBackupRepo(tools.SampleRemoteBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(repo))
 
@@ -333,14 +341,14 @@ func TestListAppsInBackupRepoMock(t *testing.T) {
 
 	assert.Nil(t, BackupManager.CreateBackup(app2Id, tools.ManualBackupDescription))
 
-	repo, err = BackupManager.ListAppsInBackupRepo(true)
+	repo, err = BackupManager.ListAppsInBackupRepo(tools.LocalBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(repo))
 	assert.Equal(t, repo[0].Maintainer, tools.SampleMaintainer)
 	assert.Equal(t, repo[0].AppName, tools.SampleApp)
 	assert.Equal(t, repo[1].Maintainer, tools.SampleMaintainer)
 	assert.Equal(t, repo[1].AppName, tools.SampleApp+"2")
-	repo, err = BackupManager.ListAppsInBackupRepo(false)
+	repo, err = BackupManager.ListAppsInBackupRepo(tools.SampleRemoteBackupRepositoryId)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(repo))
 	assert.Equal(t, repo[0].Maintainer, tools.SampleMaintainer)
@@ -355,7 +363,7 @@ func TestBackupListContainOnlyAppSpecificBackups(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.Nil(t, BackupManager.CreateBackup(appId, tools.ManualBackupDescription))
-	apps, err := BackupManager.ListBackupsOfApp(tools.BackupListRequest{Maintainer: app1.Maintainer, AppName: app1.AppName, IsLocal: true})
+	apps, err := BackupManager.ListBackupsOfApp(tools.BackupListRequest{Maintainer: app1.Maintainer, AppName: app1.AppName, RepositoryId: tools.LocalBackupRepositoryId})
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(apps))
 
@@ -365,7 +373,7 @@ func TestBackupListContainOnlyAppSpecificBackups(t *testing.T) {
 	app2Id, err := common.AppRepo.GetAppId(tools.SampleMaintainer, tools.SampleApp+"2")
 	assert.Nil(t, err)
 	assert.Nil(t, BackupManager.CreateBackup(app2Id, tools.ManualBackupDescription))
-	apps2, err := BackupManager.ListBackupsOfApp(tools.BackupListRequest{Maintainer: app2.Maintainer, AppName: app2.AppName, IsLocal: true})
+	apps2, err := BackupManager.ListBackupsOfApp(tools.BackupListRequest{Maintainer: app2.Maintainer, AppName: app2.AppName, RepositoryId: tools.LocalBackupRepositoryId})
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(apps))
 	assert.Equal(t, 1, len(apps2))
@@ -15,6 +15,8 @@ import (
 	"ocelot/backend/apps/common"
 	"ocelot/backend/certs"
 	"ocelot/backend/clients"
+	"ocelot/backend/recovery"
+	"ocelot/backend/repositories"
 	"ocelot/backend/ssh"
 	"ocelot/backend/tools"
 	"reflect"
@@ -167,10 +169,10 @@ func TestManualBackup(t *testing.T) {
 	_, err := client.installSampleApp("2.0")
 	assert.Nil(t, err)
 
-	appBackups := client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, true)
+	appBackups := client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.LocalBackupRepositoryId)
 	assert.Equal(t, 0, len(appBackups))
 	client.createSampleAppBackup()
-	appBackups = client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, true)
+	appBackups = client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.LocalBackupRepositoryId)
 	assert.Equal(t, 1, len(appBackups))
 
 	backup := appBackups[0]
@@ -181,6 +183,12 @@ func TestManualBackup(t *testing.T) {
 	assert.Equal(t, tools.SampleAppVersion2CreationTimestamp, backup.VersionCreationTimestamp)
 	assert.True(t, backup.BackupCreationTimestamp.Before(time.Now()))
 	assert.True(t, backup.BackupCreationTimestamp.After(time.Now().Add(-1*time.Minute)))
+
+	jobs, err := client.listJobs()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(jobs))
+	assert.Equal(t, "create backup", jobs[0].Operation)
+	assert.Equal(t, tools.JobStatusSucceeded, jobs[0].Status)
 }
 
 func TestStartingAndStoppingApps(t *testing.T) {
@@ -197,6 +205,86 @@ func TestStartingAndStoppingApps(t *testing.T) {
 	assert.Equal(t, "Available", sampleApp.Status)
 }
 
+func TestAppMaintenanceOverrides(t *testing.T) {
+	client := getClientAndLogin(t)
+	defer client.wipeData()
+	_, err := client.installSampleApp("2.0")
+	assert.Nil(t, err)
+	sampleApp := client.getInstalledSampleApp()
+	assert.True(t, sampleApp.IsAutoBackupEnabled)
+	assert.True(t, sampleApp.IsAutoUpdateEnabled)
+
+	overrides := tools.AppMaintenanceOverrides{AppId: sampleApp.AppId, IsAutoBackupEnabled: false, IsAutoUpdateEnabled: true}
+	assert.Nil(t, client.setAppMaintenanceOverrides(overrides))
+	sampleApp = client.getInstalledSampleApp()
+	assert.False(t, sampleApp.IsAutoBackupEnabled)
+	assert.True(t, sampleApp.IsAutoUpdateEnabled)
+
+	overrides.AppId = "12345"
+	err = client.setAppMaintenanceOverrides(overrides)
+	assert.NotNil(t, err)
+	assert.Equal(t, utils.GetErrMsg(404, "App not found"), err.Error())
+}
+
+func TestAppUpdatePolicy(t *testing.T) {
+	client := getClientAndLogin(t)
+	defer client.wipeData()
+	installedSampleApp, err := client.installSampleApp("1.0")
+	assert.Nil(t, err)
+	assert.Equal(t, tools.DefaultUpdatePolicy, installedSampleApp.UpdatePolicy)
+	assert.False(t, installedSampleApp.IsPreReleaseChannelEnabled)
+
+	updatePolicy := tools.AppUpdatePolicy{AppId: installedSampleApp.AppId, UpdatePolicy: tools.UpdatePolicyMinor}
+	assert.Nil(t, client.setAppUpdatePolicy(updatePolicy))
+	installedSampleApp = client.getInstalledSampleApp()
+	assert.Equal(t, tools.UpdatePolicyMinor, installedSampleApp.UpdatePolicy)
+
+	// version 2.0 is a major update, so it is not allowed by the policy
+	err = client.updateApp(installedSampleApp.AppId)
+	assert.NotNil(t, err)
+	expectedAppInfo := tools.RepoApp{Maintainer: tools.SampleMaintainer, AppName: tools.SampleApp, VersionName: "1.0"}
+	assert.True(t, strings.Contains(err.Error(), clients.GetUpdateErrorString(expectedAppInfo)))
+
+	updatePolicy.UpdatePolicy = "latest"
+	assert.NotNil(t, client.setAppUpdatePolicy(updatePolicy))
+
+	updatePolicy.UpdatePolicy = tools.UpdatePolicyMajor
+	assert.Nil(t, client.setAppUpdatePolicy(updatePolicy))
+	assert.Nil(t, client.updateApp(installedSampleApp.AppId))
+	assert.Equal(t, "2.0", client.getInstalledSampleApp().VersionName)
+
+	updatePolicy.AppId = "12345"
+	err = client.setAppUpdatePolicy(updatePolicy)
+	assert.NotNil(t, err)
+	assert.Equal(t, utils.GetErrMsg(404, "App not found"), err.Error())
+}
+
+func TestAvailableUpdates(t *testing.T) {
+	client := getClientAndLogin(t)
+	defer client.wipeData()
+	installedSampleApp, err := client.installSampleApp("1.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "", installedSampleApp.AvailableUpdateVersionName)
+
+	client.checkForAvailableUpdates()
+	client.waitForAvailableUpdateOfSampleApp("2.0")
+
+	// changing the policy checks the app again right away
+	updatePolicy := tools.AppUpdatePolicy{AppId: installedSampleApp.AppId, UpdatePolicy: tools.UpdatePolicyMinor}
+	assert.Nil(t, client.setAppUpdatePolicy(updatePolicy))
+	assert.Equal(t, "", client.getInstalledSampleApp().AvailableUpdateVersionName)
+	updatePolicy.UpdatePolicy = tools.UpdatePolicyMajor
+	assert.Nil(t, client.setAppUpdatePolicy(updatePolicy))
+	assert.Equal(t, "2.0", client.getInstalledSampleApp().AvailableUpdateVersionName)
+
+	// the cached result refers to the previously installed version, so it is not shown anymore after the update
+	assert.Nil(t, client.updateApp(installedSampleApp.AppId))
+	assert.Equal(t, "", client.getInstalledSampleApp().AvailableUpdateVersionName)
+	client.checkForAvailableUpdates()
+	time.Sleep(time.Second)
+	assert.Equal(t, "", client.getInstalledSampleApp().AvailableUpdateVersionName)
+}
+
 func TestUpdatesAndPreUpdateBackupCreation(t *testing.T) {
 	client := getClientAndLogin(t)
 	defer client.wipeData()
@@ -209,7 +297,7 @@ func TestUpdatesAndPreUpdateBackupCreation(t *testing.T) {
 	time.Sleep(1 * time.Second)
 	assert.Nil(t, client.assertContent("this is version 1.0"))
 
-	appBackups := client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, true)
+	appBackups := client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.LocalBackupRepositoryId)
 	assert.Equal(t, 0, len(appBackups))
 
 	assert.Nil(t, client.updateApp(installedSampleApp.AppId))
@@ -217,7 +305,7 @@ func TestUpdatesAndPreUpdateBackupCreation(t *testing.T) {
 	assert.Equal(t, "2.0", installedSampleApp.VersionName)
 	assert.Nil(t, client.assertContent("this is version 2.0"))
 
-	appBackups = client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, true)
+	appBackups = client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.LocalBackupRepositoryId)
 	assert.Equal(t, 1, len(appBackups))
 	backup := appBackups[0]
 	assert.Equal(t, "1.0", backup.VersionName)
@@ -226,7 +314,7 @@ func TestUpdatesAndPreUpdateBackupCreation(t *testing.T) {
 	assert.Equal(t, tools.SampleApp, backup.AppName)
 	assert.Equal(t, tools.SampleMaintainer, backup.Maintainer)
 
-	client.restoreBackup(backup.BackupId, true)
+	client.restoreBackup(backup.BackupId, tools.LocalBackupRepositoryId)
 	installedSampleApp = client.getInstalledSampleApp()
 	assert.Equal(t, "1.0", installedSampleApp.VersionName)
 	assert.Nil(t, client.assertContent("this is version 1.0"))
@@ -274,24 +362,24 @@ func TestPostgresAppOperations(t *testing.T) {
 	assert.Equal(t, tools.OcelotDbAppName, postgresApp.AppName)
 	assert.Equal(t, common.PostgresVersion, postgresApp.VersionName)
 
-	backupInfos := client.listAppBackups(postgresApp.Maintainer, postgresApp.AppName, true)
+	backupInfos := client.listAppBackups(postgresApp.Maintainer, postgresApp.AppName, tools.LocalBackupRepositoryId)
 	for _, backupInfo := range backupInfos {
-		client.deleteBackup(backupInfo.BackupId, true)
+		client.deleteBackup(backupInfo.BackupId, tools.LocalBackupRepositoryId)
 	}
 
-	backupInfos = client.listAppBackups(postgresApp.Maintainer, postgresApp.AppName, true)
+	backupInfos = client.listAppBackups(postgresApp.Maintainer, postgresApp.AppName, tools.LocalBackupRepositoryId)
 	assert.Equal(t, 0, len(backupInfos))
 	client.createBackup(postgresApp.AppId)
-	backupInfos = client.listAppBackups(postgresApp.Maintainer, postgresApp.AppName, true)
+	backupInfos = client.listAppBackups(postgresApp.Maintainer, postgresApp.AppName, tools.LocalBackupRepositoryId)
 	assert.Equal(t, 1, len(backupInfos))
 	backup := backupInfos[0]
-	defer client.deleteBackup(backup.BackupId, true)
+	defer client.deleteBackup(backup.BackupId, tools.LocalBackupRepositoryId)
 
 	assert.Equal(t, 2, len(client.getUsers()))
 	client.deleteUserIfPresent()
 	assert.Equal(t, 1, len(client.getUsers()))
 
-	client.restoreBackup(backup.BackupId, true)
+	client.restoreBackup(backup.BackupId, tools.LocalBackupRepositoryId)
 	assert.Equal(t, 2, len(client.getUsers()))
 }
 
@@ -340,17 +428,42 @@ func TestHostSettings(t *testing.T) {
 	assert.Equal(t, "localhost2", host)
 }
 
-func TestSshConfigsSetting(t *testing.T) {
+func TestBackupRepositoriesSetting(t *testing.T) {
 	client := getClientAndLogin(t)
 	defer client.wipeData()
-	initialConfigs, err := client.readSshConfigs()
+	backupRepositories, err := client.listBackupRepositories()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(backupRepositories))
+	assert.True(t, backupRepositories[0].IsLocal())
+
+	repository := ssh.GetSampleRemoteRepo()
+	repositoryId, err := client.createBackupRepository(repository)
 	assert.Nil(t, err)
-	reflect.DeepEqual(initialConfigs, ssh.GetSampleRemoteRepo())
+	assert.Equal(t, tools.SampleRemoteBackupRepositoryId, repositoryId)
+	repository.Id = repositoryId
+	_, err = client.createBackupRepository(repository)
+	assert.NotNil(t, err)
 
-	client.saveSshConfigs(ssh.GetSampleRemoteRepo())
-	configs, err := client.readSshConfigs()
+	// secrets are not sent to the browser and submitting them empty keeps the stored ones
+	repository.BackupIntervalDays = 7
+	assert.Nil(t, client.updateBackupRepository(repositories.WithoutSecrets(repository)))
+	backupRepositories, err = client.listBackupRepositories()
 	assert.Nil(t, err)
-	reflect.DeepEqual(configs, ssh.GetSampleRemoteRepo())
+	assert.Equal(t, 2, len(backupRepositories))
+	assert.True(t, reflect.DeepEqual(repositories.WithoutSecrets(repository), backupRepositories[1]))
+
+	// the repository was not used yet, so its encryption password can still be overwritten
+	repository.EncryptionPassword = "other-restic-password"
+	assert.Nil(t, client.updateBackupRepository(repository))
+	assert.NotNil(t, client.rotateRepositoryPassword(tools.LocalBackupRepositoryId, "new-restic-password"))
+
+	repository.BackupIntervalDays = 0
+	assert.NotNil(t, client.updateBackupRepository(repository))
+	assert.NotNil(t, client.deleteBackupRepository(tools.LocalBackupRepositoryId))
+	assert.Nil(t, client.deleteBackupRepository(repositoryId))
+	backupRepositories, err = client.listBackupRepositories()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(backupRepositories))
 }
 
 func TestRemoteBackups(t *testing.T) {
@@ -377,12 +490,22 @@ func TestRemoteBackups(t *testing.T) {
 	client.createBackup(appId)
 	assertBackupNumbers(client, 2, 1)
 
-	backupInfos := client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, false)
+	backupInfos := client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.SampleRemoteBackupRepositoryId)
 	assert.Equal(t, 1, len(backupInfos))
 	backup := backupInfos[0]
 
-	client.restoreBackup(backup.BackupId, false)
-	client.deleteBackup(backup.BackupId, false)
+	backupRepositories, err := client.listBackupRepositories()
+	assert.Nil(t, err)
+	remoteRepository := backupRepositories[1]
+	remoteRepository.EncryptionPassword = "new-restic-password"
+	err = client.updateBackupRepository(remoteRepository)
+	assert.NotNil(t, err)
+	assert.Equal(t, utils.GetErrMsg(409, repositories.EncryptionPasswordOverwriteError), err.Error())
+	assert.Nil(t, client.rotateRepositoryPassword(remoteRepository.Id, "new-restic-password"))
+	assertBackupNumbers(client, 2, 1)
+
+	client.restoreBackup(backup.BackupId, tools.SampleRemoteBackupRepositoryId)
+	client.deleteBackup(backup.BackupId, tools.SampleRemoteBackupRepositoryId)
 	assertBackupNumbers(client, 2, 0)
 }
 
@@ -393,15 +516,17 @@ func enableRemoteBackupRepo(client *CloudClient) {
 	assert.True(client.t, strings.Contains(knownHosts, "[localhost]:2222"))
 	repo.SshKnownHosts = knownHosts
 	assert.Nil(client.t, client.testSshAccess(repo))
-	client.saveSshConfigs(repo)
+	repositoryId, err := client.createBackupRepository(repo)
+	assert.Nil(client.t, err)
+	assert.Equal(client.t, tools.SampleRemoteBackupRepositoryId, repositoryId)
 }
 
 func assertBackupNumbers(client *CloudClient, expectedLocalBackups, expectedRemoteBackups int) {
 	tools.Logger.Info("Asserting local backup number")
-	appBackups := client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, true)
+	appBackups := client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.LocalBackupRepositoryId)
 	assert.Equal(client.t, expectedLocalBackups, len(appBackups))
 	tools.Logger.Info("Asserting remote backup number")
-	appBackups = client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, false)
+	appBackups = client.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.SampleRemoteBackupRepositoryId)
 	assert.Equal(client.t, expectedRemoteBackups, len(appBackups))
 }
 
@@ -441,13 +566,31 @@ func TestCookieExpirationDateRenewal(t *testing.T) {
 	client := getClientAndLogin(t)
 	defer client.wipeData()
 
+	now := client.advanceClock(0)
 	cookieExpiration1 := client.checkAuth().CookieExpirationDate
-	assert.True(t, cookieExpiration1.After(time.Now().AddDate(0, 0, 29)))
-	assert.True(t, cookieExpiration1.Before(time.Now().AddDate(0, 0, 31)))
+	assert.True(t, cookieExpiration1.After(now.AddDate(0, 0, 29)))
+	assert.True(t, cookieExpiration1.Before(now.AddDate(0, 0, 31)))
 
-	time.Sleep(1 * time.Second)
+	client.advanceClock(time.Hour)
 	cookieExpiration2 := client.checkAuth().CookieExpirationDate
-	assert.True(t, cookieExpiration1.Before(cookieExpiration2))
+	// the mocked clock also follows the real time, which passes between the requests
+	renewal := cookieExpiration2.Sub(cookieExpiration1)
+	assert.True(t, renewal >= time.Hour && renewal < time.Hour+time.Minute)
+}
+
+func TestCookieExpires(t *testing.T) {
+	if tools.Profile != tools.DOCKER_TEST {
+		t.Skip()
+		return
+	}
+	client := getClientAndLogin(t)
+	defer client.wipeData()
+	client.checkAuth()
+
+	client.advanceClock(tools.CookieExpirationTime + time.Minute)
+	_, err := client.parent.DoRequest(tools.CheckAuthPath, nil, "")
+	assert.NotNil(t, err)
+	assert.Nil(t, client.login())
 }
 
 func TestStopStackNotExisting(t *testing.T) {
@@ -546,14 +689,45 @@ func TestAppListingInBackupRepo(t *testing.T) {
 }
 
 func assertAppsNumbersInBackupRepo(cloud *CloudClient, expectedAppNumberInLocalBackupRepo, expectedAppNumberInRemoteBackupRepo int) {
-	apps, err := cloud.listAppsInBackupRepo(true)
+	apps, err := cloud.listAppsInBackupRepo(tools.LocalBackupRepositoryId)
 	assert.Nil(cloud.t, err)
 	assert.Equal(cloud.t, expectedAppNumberInLocalBackupRepo, len(apps))
-	apps, err = cloud.listAppsInBackupRepo(false)
+	apps, err = cloud.listAppsInBackupRepo(tools.SampleRemoteBackupRepositoryId)
 	assert.Nil(cloud.t, err)
 	assert.Equal(cloud.t, expectedAppNumberInRemoteBackupRepo, len(apps))
 }
 
+func TestDisasterRecovery(t *testing.T) {
+	cloud := getClientAndLogin(t)
+	defer cloud.wipeData()
+	_, err := cloud.installSampleApp("2.0")
+	assert.Nil(t, err)
+	cloud.createSampleAppBackup()
+
+	assert.False(t, cloud.getRecoveryStatus().IsRecoveryPossible)
+	_, err = cloud.startRecovery(tools.LocalBackupRepositoryId)
+	assert.Equal(t, utils.GetErrMsg(409, recovery.RecoveryNotPossibleError), err.Error())
+
+	assert.Nil(t, cloud.pruneApp(cloud.getInstalledSampleApp().AppId))
+	assert.Nil(t, cloud.getInstalledSampleApp())
+	assert.True(t, cloud.getRecoveryStatus().IsRecoveryPossible)
+
+	responseBody, err := cloud.startRecovery(tools.LocalBackupRepositoryId)
+	assert.Nil(t, err)
+	cloud.waitForJobToSucceed(responseBody)
+
+	sampleApp := cloud.getInstalledSampleApp()
+	assert.NotNil(t, sampleApp)
+	assert.Equal(t, "2.0", sampleApp.VersionName)
+	assert.False(t, cloud.getRecoveryStatus().IsRecoveryPossible)
+
+	summary := cloud.getRecoverySummary()
+	assert.Equal(t, 1, len(summary.Apps))
+	assert.Equal(t, tools.SampleApp, summary.Apps[0].AppName)
+	assert.True(t, summary.Apps[0].IsRestored)
+	assert.Equal(t, "", summary.Apps[0].Error)
+}
+
 func TestAdminCantDeleteHisOwnAccount(t *testing.T) {
 	cloud := getClientAndLogin(t)
 	defer cloud.wipeData()
@@ -570,21 +744,119 @@ func TestMaintenanceSettings(t *testing.T) {
 	defer cloud.wipeData()
 
 	maintenanceSettingsFromServer := cloud.getMaintenanceSettings()
-	assert.Equal(t, 4, maintenanceSettingsFromServer.PreferredMaintenanceHour)
-	assert.Equal(t, true, maintenanceSettingsFromServer.AreAutoBackupsEnabled)
-	assert.Equal(t, true, maintenanceSettingsFromServer.AreAutoUpdatesEnabled)
+	assert.Equal(t, backups.GetDefaultMaintenanceSettings(), maintenanceSettingsFromServer)
 
 	newMaintenanceSettings := backups.MaintenanceSettings{
-		AreAutoBackupsEnabled:    false,
-		AreAutoUpdatesEnabled:    false,
-		PreferredMaintenanceHour: 7,
+		AreAutoBackupsEnabled:   false,
+		AreAutoUpdatesEnabled:   false,
+		BackupSchedule:          "0 */6 * * *",
+		UpdateSchedule:          "0 3 * * 0",
+		RetentionSchedule:       "30 4 * * *",
+		RepositoryCheckSchedule: "0 5 1 * *",
+		Timezone:                "Europe/Berlin",
+		CatchUpJitterMinutes:    30,
 	}
 	cloud.setMaintenanceSettings(newMaintenanceSettings)
 
 	maintenanceSettingsFromServer = cloud.getMaintenanceSettings()
-	assert.Equal(t, 7, maintenanceSettingsFromServer.PreferredMaintenanceHour)
-	assert.Equal(t, false, maintenanceSettingsFromServer.AreAutoBackupsEnabled)
-	assert.Equal(t, false, maintenanceSettingsFromServer.AreAutoUpdatesEnabled)
+	assert.Equal(t, newMaintenanceSettings, maintenanceSettingsFromServer)
+
+	newMaintenanceSettings.Timezone = "Mars/Olympus_Mons"
+	_, err := cloud.parent.DoRequest(tools.SettingsMaintenanceSavePath, newMaintenanceSettings, "")
+	assert.NotNil(t, err)
+	newMaintenanceSettings.Timezone = "Europe/Berlin"
+	newMaintenanceSettings.UpdateSchedule = "0 3 * * 8"
+	_, err = cloud.parent.DoRequest(tools.SettingsMaintenanceSavePath, newMaintenanceSettings, "")
+	assert.NotNil(t, err)
+	newMaintenanceSettings.UpdateSchedule = "0 3 * * 0"
+	newMaintenanceSettings.CatchUpJitterMinutes = -1
+	_, err = cloud.parent.DoRequest(tools.SettingsMaintenanceSavePath, newMaintenanceSettings, "")
+	assert.NotNil(t, err)
+}
+
+func TestMaintenanceRunHistory(t *testing.T) {
+	cloud := getClientAndLogin(t)
+	defer cloud.wipeData()
+	_, err := cloud.installSampleApp("2.0")
+	assert.Nil(t, err)
+
+	runs, err := cloud.listMaintenanceRuns()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(runs))
+
+	runId, err := cloud.startMaintenanceRun()
+	assert.Nil(t, err)
+	run := cloud.waitForMaintenanceRunToFinish(runId)
+	assert.Equal(t, tools.MaintenanceRunTriggerManual, run.TriggeredBy)
+	assert.Equal(t, tools.MaintenanceRunStatusSucceeded, run.Status)
+	assert.Equal(t, tools.MaintenanceOutcomeSucceeded, run.RetentionOutcome)
+	assert.Equal(t, 0, run.ProblemsCount)
+
+	var sampleAppOutcomes []tools.MaintenanceOutcome
+	for _, result := range run.AppResults {
+		if result.AppName == tools.SampleApp {
+			sampleAppOutcomes = append(sampleAppOutcomes, result.Outcome)
+		}
+	}
+	assert.Equal(t, []tools.MaintenanceOutcome{tools.MaintenanceOutcomeSkipped, tools.MaintenanceOutcomeBackedUp}, sampleAppOutcomes)
+	assert.Equal(t, 1, len(cloud.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.LocalBackupRepositoryId)))
+}
+
+func TestMaintenanceAgentConductsScheduledCycles(t *testing.T) {
+	cloud := getClientAndLogin(t)
+	defer cloud.wipeData()
+	_, err := cloud.installSampleApp("2.0")
+	assert.Nil(t, err)
+	settings := cloud.getMaintenanceSettings()
+	settings.CatchUpJitterMinutes = 0
+	cloud.setMaintenanceSettings(settings)
+
+	// the data wipe resets the date of the last maintenance cycle, so the agent evaluates the schedules from its start on
+	cloud.startMaintenanceAgent()
+	runs := cloud.waitForFinishedMaintenanceRunsWhileAdvancingClock(1, 24*time.Hour)
+	assert.Equal(t, 1, len(cloud.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.LocalBackupRepositoryId)))
+
+	runs = cloud.waitForFinishedMaintenanceRunsWhileAdvancingClock(2, 24*time.Hour)
+	for _, run := range runs {
+		assert.Equal(t, tools.MaintenanceRunTriggerSchedule, run.TriggeredBy)
+		assert.Equal(t, tools.MaintenanceRunStatusSucceeded, run.Status)
+	}
+	assert.True(t, runs[0].StartedAt.Sub(runs[1].StartedAt) >= 24*time.Hour)
+	assert.Equal(t, 2, len(cloud.listAppBackups(tools.SampleMaintainer, tools.SampleApp, tools.LocalBackupRepositoryId)))
+}
+
+func TestNotificationSettings(t *testing.T) {
+	cloud := getClientAndLogin(t)
+	defer cloud.wipeData()
+
+	notificationSettingsFromServer := cloud.getNotificationSettings()
+	assert.False(t, notificationSettingsFromServer.IsEmailEnabled)
+	assert.False(t, notificationSettingsFromServer.IsWebhookEnabled)
+
+	err := cloud.setNotificationSettings(tools.NotificationSettings{IsEmailEnabled: true})
+	assert.NotNil(t, err)
+
+	newNotificationSettings := tools.NotificationSettings{
+		IsEmailEnabled:                 true,
+		SmtpHost:                       "smtp.example.com",
+		SmtpPort:                       "587",
+		SmtpUser:                       "ocelot@example.com",
+		SmtpPassword:                   "smtp-password",
+		SenderAddress:                  "ocelot@example.com",
+		Recipients:                     []string{"admin@example.com"},
+		IsWebhookEnabled:               true,
+		WebhookUrl:                     "https://hooks.example.com/services/abc",
+		AreMaintenanceSummariesEnabled: true,
+	}
+	assert.Nil(t, cloud.setNotificationSettings(newNotificationSettings))
+	notificationSettingsFromServer = cloud.getNotificationSettings()
+	// the smtp password is never sent back to the browser
+	expectedNotificationSettings := newNotificationSettings
+	expectedNotificationSettings.SmtpPassword = ""
+	assert.Equal(t, expectedNotificationSettings, notificationSettingsFromServer)
+
+	assert.Nil(t, cloud.setNotificationSettings(notificationSettingsFromServer))
+	assert.Equal(t, expectedNotificationSettings, cloud.getNotificationSettings())
 }
 
 func TestNullOriginHeaderIsAllowed(t *testing.T) {
@@ -14,6 +14,7 @@ import (
 	"ocelot/backend/tools"
 	"os"
 	"testing"
+	"time"
 )
 
 var logger = tools.Logger
@@ -167,15 +168,16 @@ func (c *CloudClient) createSampleAppBackup() {
 	if sampleApp == nil {
 		c.t.Fatal("No sample app found")
 	}
-	_, err = c.parent.DoRequest(tools.BackupsCreatePath, tools.NumberString{Value: sampleApp.AppId}, "")
+	responseBody, err = c.parent.DoRequest(tools.BackupsCreatePath, tools.NumberString{Value: sampleApp.AppId}, "")
 	assert.Nil(c.t, err)
+	c.waitForJobToSucceed(responseBody)
 }
 
-func (c *CloudClient) listAppBackups(maintainer, appName string, isLocal bool) []tools.BackupInfo {
+func (c *CloudClient) listAppBackups(maintainer, appName string, repositoryId int) []tools.BackupInfo {
 	backupListRequest := tools.BackupListRequest{
-		Maintainer: maintainer,
-		AppName:    appName,
-		IsLocal:    isLocal,
+		Maintainer:   maintainer,
+		AppName:      appName,
+		RepositoryId: repositoryId,
 	}
 	responseBody, err := c.parent.DoRequest(tools.BackupsListPath, backupListRequest, "")
 	assert.Nil(c.t, err)
@@ -218,9 +220,10 @@ func (c *CloudClient) updateApp(appId string) error {
 	return err
 }
 
-func (c *CloudClient) restoreBackup(backupId string, isLocal bool) {
-	_, err := c.parent.DoRequest(tools.BackupsRestorePath, tools.BackupOperationRequest{BackupId: backupId, IsLocal: isLocal}, "")
+func (c *CloudClient) restoreBackup(backupId string, repositoryId int) {
+	responseBody, err := c.parent.DoRequest(tools.BackupsRestorePath, tools.BackupOperationRequest{BackupId: backupId, RepositoryId: repositoryId}, "")
 	assert.Nil(c.t, err)
+	c.waitForJobToSucceed(responseBody)
 }
 
 func (c *CloudClient) assertContent(expectedContent string) error {
@@ -360,43 +363,108 @@ func (c *CloudClient) getNormalUser(users []security.UserDto) (*security.UserDto
 }
 
 func (c *CloudClient) createBackup(appId string) {
-	_, err := c.parent.DoRequest(tools.BackupsCreatePath, tools.NumberString{Value: appId}, "")
+	responseBody, err := c.parent.DoRequest(tools.BackupsCreatePath, tools.NumberString{Value: appId}, "")
 	assert.Nil(c.t, err)
+	c.waitForJobToSucceed(responseBody)
+}
+
+func (c *CloudClient) waitForJobToSucceed(jobCreationResponseBody []byte) {
+	var jobCreationResponse tools.JobCreationResponse
+	assert.Nil(c.t, json.Unmarshal(jobCreationResponseBody, &jobCreationResponse))
+	for i := 0; i < 600; i++ {
+		// requests may fail temporarily while the database app itself is backed up or restored
+		job, err := c.getJob(jobCreationResponse.JobId)
+		if err == nil && job.IsFinished() {
+			assert.Equal(c.t, tools.JobStatusSucceeded, job.Status)
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.t.Fatal("job did not finish in time")
+}
+
+func (c *CloudClient) getJob(jobId int) (*tools.Job, error) {
+	responseBody, err := c.parent.DoRequest(tools.JobsStatusPath, tools.JobRequest{JobId: jobId}, "")
+	if err != nil {
+		return nil, err
+	}
+	var job tools.Job
+	err = json.Unmarshal(responseBody, &job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (c *CloudClient) listJobs() ([]tools.Job, error) {
+	responseBody, err := c.parent.DoRequest(tools.JobsListPath, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var jobs []tools.Job
+	err = json.Unmarshal(responseBody, &jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
 }
 
-func (c *CloudClient) deleteBackup(backupId string, isLocal bool) {
+func (c *CloudClient) deleteBackup(backupId string, repositoryId int) {
 	deleteBackupRequest := tools.BackupOperationRequest{
-		BackupId: backupId,
-		IsLocal:  isLocal,
+		BackupId:     backupId,
+		RepositoryId: repositoryId,
 	}
 	_, err := c.parent.DoRequest(tools.BackupsDeletePath, deleteBackupRequest, "")
 	assert.Nil(c.t, err)
 }
 
-func (c *CloudClient) readSshConfigs() (*tools.RemoteBackupRepository, error) {
-	responseBody, err := c.parent.DoRequest(tools.SettingsSshReadPath, nil, "")
+func (c *CloudClient) listBackupRepositories() ([]tools.BackupRepository, error) {
+	responseBody, err := c.parent.DoRequest(tools.SettingsRepositoriesListPath, nil, "")
 	if err != nil {
 		return nil, err
 	}
-	var remoteRepo tools.RemoteBackupRepository
-	err = json.Unmarshal(responseBody, &remoteRepo)
+	var repositories []tools.BackupRepository
+	err = json.Unmarshal(responseBody, &repositories)
 	if err != nil {
 		return nil, err
 	}
-	return &remoteRepo, nil
+	return repositories, nil
 }
 
-func (c *CloudClient) saveSshConfigs(repository tools.RemoteBackupRepository) {
-	_, err := c.parent.DoRequest(tools.SettingsSshSavePath, repository, "")
-	assert.Nil(c.t, err)
+func (c *CloudClient) createBackupRepository(repository tools.BackupRepository) (int, error) {
+	responseBody, err := c.parent.DoRequest(tools.SettingsRepositoriesCreatePath, repository, "")
+	if err != nil {
+		return 0, err
+	}
+	var repositoryRequest tools.BackupRepositoryRequest
+	err = json.Unmarshal(responseBody, &repositoryRequest)
+	if err != nil {
+		return 0, err
+	}
+	return repositoryRequest.RepositoryId, nil
+}
+
+func (c *CloudClient) updateBackupRepository(repository tools.BackupRepository) error {
+	_, err := c.parent.DoRequest(tools.SettingsRepositoriesUpdatePath, repository, "")
+	return err
+}
+
+func (c *CloudClient) rotateRepositoryPassword(repositoryId int, newPassword string) error {
+	_, err := c.parent.DoRequest(tools.SettingsRepositoriesRotatePasswordPath, tools.RepositoryPasswordRotationRequest{RepositoryId: repositoryId, NewPassword: newPassword}, "")
+	return err
+}
+
+func (c *CloudClient) deleteBackupRepository(repositoryId int) error {
+	_, err := c.parent.DoRequest(tools.SettingsRepositoriesDeletePath, tools.BackupRepositoryRequest{RepositoryId: repositoryId}, "")
+	return err
 }
 
-func (c *CloudClient) testSshAccess(repo tools.RemoteBackupRepository) error {
+func (c *CloudClient) testSshAccess(repo tools.BackupRepository) error {
 	_, err := c.parent.DoRequest(tools.SettingsSshTestAccessPath, repo, "")
 	return err
 }
 
-func (c *CloudClient) getKnownHosts(repo tools.RemoteBackupRepository) string {
+func (c *CloudClient) getKnownHosts(repo tools.BackupRepository) string {
 	responseBody, err := c.parent.DoRequest(tools.SettingsSshKnownHostsPath, repo, "")
 	assert.Nil(c.t, err)
 	var knownHostsWrapper tools.KnownHostsString
@@ -419,8 +487,34 @@ func (c *CloudClient) stopApp(appId string) error {
 	return err
 }
 
-func (c *CloudClient) listAppsInBackupRepo(isLocal bool) ([]tools.MaintainerAndApp, error) {
-	responseBody, err := c.parent.DoRequest(tools.BackupsListAppsPath, tools.SingleBool{Value: isLocal}, "")
+func (c *CloudClient) setAppMaintenanceOverrides(overrides tools.AppMaintenanceOverrides) error {
+	_, err := c.parent.DoRequest(tools.AppsMaintenancePath, overrides, "")
+	return err
+}
+
+func (c *CloudClient) setAppUpdatePolicy(updatePolicy tools.AppUpdatePolicy) error {
+	_, err := c.parent.DoRequest(tools.AppsUpdatePolicyPath, updatePolicy, "")
+	return err
+}
+
+func (c *CloudClient) checkForAvailableUpdates() {
+	_, err := c.parent.DoRequest(tools.AppsCheckUpdatesPath, nil, "")
+	assert.Nil(c.t, err)
+}
+
+// The check runs in the background, so its result is awaited.
+func (c *CloudClient) waitForAvailableUpdateOfSampleApp(expectedVersionName string) {
+	for i := 0; i < 100; i++ {
+		if c.getInstalledSampleApp().AvailableUpdateVersionName == expectedVersionName {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.t.Fatalf("available update of sample app did not become '%s'", expectedVersionName)
+}
+
+func (c *CloudClient) listAppsInBackupRepo(repositoryId int) ([]tools.MaintainerAndApp, error) {
+	responseBody, err := c.parent.DoRequest(tools.BackupsListAppsPath, tools.BackupRepositoryRequest{RepositoryId: repositoryId}, "")
 	if err != nil {
 		return nil, err
 	}
@@ -446,6 +540,106 @@ func (c *CloudClient) setMaintenanceSettings(settings backups.MaintenanceSetting
 	assert.Nil(c.t, err)
 }
 
+func (c *CloudClient) startMaintenanceRun() (int, error) {
+	responseBody, err := c.parent.DoRequest(tools.MaintenanceRunsStartPath, nil, "")
+	if err != nil {
+		return 0, err
+	}
+	var response tools.MaintenanceRunCreationResponse
+	err = json.Unmarshal(responseBody, &response)
+	if err != nil {
+		return 0, err
+	}
+	return response.RunId, nil
+}
+
+func (c *CloudClient) listMaintenanceRuns() ([]tools.MaintenanceRun, error) {
+	responseBody, err := c.parent.DoRequest(tools.MaintenanceRunsListPath, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var runs []tools.MaintenanceRun
+	err = json.Unmarshal(responseBody, &runs)
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (c *CloudClient) waitForMaintenanceRunToFinish(runId int) tools.MaintenanceRun {
+	for i := 0; i < 600; i++ {
+		// requests may fail temporarily while the database app itself is backed up
+		runs, err := c.listMaintenanceRuns()
+		if err == nil {
+			for _, run := range runs {
+				if run.Id == runId && run.FinishedAt != nil {
+					return run
+				}
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.t.Fatal("maintenance run did not finish in time")
+	return tools.MaintenanceRun{}
+}
+
+// waitForFinishedMaintenanceRuns returns the runs, most recent first, as soon as the expected number of runs is finished.
+func (c *CloudClient) waitForFinishedMaintenanceRuns(expectedCount int) []tools.MaintenanceRun {
+	return c.waitForFinishedMaintenanceRunsWhileAdvancingClock(expectedCount, 0)
+}
+
+// The agent may not wait for the clock yet when it is advanced, so the clock is advanced repeatedly until the expected run started.
+func (c *CloudClient) waitForFinishedMaintenanceRunsWhileAdvancingClock(expectedCount int, clockStep time.Duration) []tools.MaintenanceRun {
+	for i := 0; i < 600; i++ {
+		runs, err := c.listMaintenanceRuns()
+		if clockStep > 0 && err == nil && len(runs) < expectedCount && i%20 == 0 {
+			c.advanceClock(clockStep)
+		}
+		if err == nil {
+			finishedCount := 0
+			for _, run := range runs {
+				if run.FinishedAt != nil {
+					finishedCount++
+				}
+			}
+			if finishedCount == expectedCount && len(runs) == expectedCount {
+				return runs
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.t.Fatalf("%d maintenance runs did not finish in time", expectedCount)
+	return nil
+}
+
+func (c *CloudClient) advanceClock(duration time.Duration) time.Time {
+	responseBody, err := c.parent.DoRequest(tools.TestingClockAdvancePath, tools.ClockAdvanceRequest{Seconds: int(duration.Seconds())}, "")
+	assert.Nil(c.t, err)
+	var response tools.ClockResponse
+	err = json.Unmarshal(responseBody, &response)
+	assert.Nil(c.t, err)
+	return response.Now
+}
+
+func (c *CloudClient) startMaintenanceAgent() {
+	_, err := c.parent.DoRequest(tools.TestingMaintenanceAgentStartPath, nil, "")
+	assert.Nil(c.t, err)
+}
+
+func (c *CloudClient) getNotificationSettings() tools.NotificationSettings {
+	responseBody, err := c.parent.DoRequest(tools.SettingsNotificationsReadPath, nil, "")
+	assert.Nil(c.t, err)
+	var notificationSettings tools.NotificationSettings
+	err = json.Unmarshal(responseBody, &notificationSettings)
+	assert.Nil(c.t, err)
+	return notificationSettings
+}
+
+func (c *CloudClient) setNotificationSettings(settings tools.NotificationSettings) error {
+	_, err := c.parent.DoRequest(tools.SettingsNotificationsSavePath, settings, "")
+	return err
+}
+
 func (c *CloudClient) changePassword(newPassword string) error {
 	_, err := c.parent.DoRequest(tools.ChangePasswordPath, tools.PasswordString{Value: newPassword}, "")
 	return err
@@ -487,3 +681,25 @@ func AssertCorsHeaders(t *testing.T, expectedAllowOrigin, expectedAllowMethods,
 	allowCredentials := resp.Header.Get("Access-Control-Allow-Credentials")
 	assert.Equal(t, expectedAllowCredentials, allowCredentials)
 }
+
+func (c *CloudClient) getRecoveryStatus() tools.RecoveryStatus {
+	responseBody, err := c.parent.DoRequest(tools.RecoveryStatusPath, nil, "")
+	assert.Nil(c.t, err)
+	var recoveryStatus tools.RecoveryStatus
+	err = json.Unmarshal(responseBody, &recoveryStatus)
+	assert.Nil(c.t, err)
+	return recoveryStatus
+}
+
+func (c *CloudClient) startRecovery(repositoryId int) ([]byte, error) {
+	return c.parent.DoRequest(tools.RecoveryStartPath, tools.BackupRepositoryRequest{RepositoryId: repositoryId}, "")
+}
+
+func (c *CloudClient) getRecoverySummary() tools.RecoverySummary {
+	responseBody, err := c.parent.DoRequest(tools.RecoverySummaryPath, nil, "")
+	assert.Nil(c.t, err)
+	var recoverySummary tools.RecoverySummary
+	err = json.Unmarshal(responseBody, &recoverySummary)
+	assert.Nil(c.t, err)
+	return recoverySummary
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"github.com/ocelot-cloud/shared/utils"
+	"github.com/ocelot-cloud/shared/validation"
+	"net/http"
+	"ocelot/backend/tools"
+)
+
+func JobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	jobRequest, err := validation.ReadBody[tools.JobRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	job, err := GetJob(jobRequest.JobId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	utils.SendJsonResponse(w, job)
+}
+
+func ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	jobs, err := ListJobs()
+	if err != nil {
+		http.Error(w, "Failed to list jobs", http.StatusInternalServerError)
+		return
+	}
+	utils.SendJsonResponse(w, jobs)
+}
+
+func ListQueuedOperationsHandler(w http.ResponseWriter, r *http.Request) {
+	utils.SendJsonResponse(w, tools.AppOperationQueue.ListOperations())
+}
@@ -0,0 +1,147 @@
+package jobs
+
+import (
+	"ocelot/backend/tools"
+	"sync"
+	"time"
+)
+
+var (
+	// restic reports its status several times per second, so progress is only persisted once per interval
+	progressPersistInterval = time.Second
+
+	runningJobs      sync.WaitGroup
+	runningJobsMutex sync.Mutex
+	// Queued and running jobs are also kept in memory, since the database may be temporarily unavailable or replaced while
+	// the database app itself is backed up or restored.
+	runningJobsById      = make(map[int]*tools.Job)
+	ticketsOfRunningJobs = make(map[int]*tools.OperationTicket)
+)
+
+// StartJob records a job and executes the operation in the background as soon as the ticket of the app operation queue is
+// its turn. The ticket is released when the job is finished. The returned job id can be used to poll the job status.
+func StartJob(ticket *tools.OperationTicket, appId int, run func(progress tools.ProgressReporter) error) (int, error) {
+	jobId, err := JobRepo.CreateJob(ticket.Operation, appId)
+	if err != nil {
+		tools.AppOperationQueue.Release(ticket)
+		return 0, err
+	}
+	job, err := JobRepo.GetJob(jobId)
+	if err != nil {
+		tools.AppOperationQueue.Release(ticket)
+		return 0, err
+	}
+
+	runningJobsMutex.Lock()
+	runningJobsById[jobId] = job
+	ticketsOfRunningJobs[jobId] = ticket
+	runningJobsMutex.Unlock()
+
+	runningJobs.Add(1)
+	go func() {
+		defer runningJobs.Done()
+		executeJob(*job, ticket, run)
+	}()
+	return jobId, nil
+}
+
+func executeJob(job tools.Job, ticket *tools.OperationTicket, run func(progress tools.ProgressReporter) error) {
+	err := ticket.Wait()
+	if err == nil {
+		Logger.Info("starting job %d: %s", job.Id, job.Operation)
+		setJobRunning(job.Id)
+		err = run(newPersistingProgressReporter(job.Id))
+	}
+	tools.AppOperationQueue.Release(ticket)
+
+	runningJobsMutex.Lock()
+	job = *runningJobsById[job.Id]
+	delete(runningJobsById, job.Id)
+	delete(ticketsOfRunningJobs, job.Id)
+	runningJobsMutex.Unlock()
+
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	if err != nil {
+		Logger.Error("job %d (%s) failed: %v", job.Id, job.Operation, err)
+		job.Status = tools.JobStatusFailed
+		job.Error = err.Error()
+	} else {
+		Logger.Info("job %d (%s) succeeded", job.Id, job.Operation)
+		job.Status = tools.JobStatusSucceeded
+		job.Progress.PercentDone = 1
+		job.Progress.SecondsRemaining = 0
+	}
+	_ = JobRepo.SaveFinishedJob(job)
+	_ = JobRepo.FailInterruptedJobs(getRunningJobIds())
+}
+
+func setJobRunning(jobId int) {
+	runningJobsMutex.Lock()
+	runningJobsById[jobId].Status = tools.JobStatusRunning
+	runningJobsMutex.Unlock()
+	_ = JobRepo.SetJobRunning(jobId)
+}
+
+func newPersistingProgressReporter(jobId int) tools.ProgressReporter {
+	var lastPersistTime time.Time
+	return func(progress tools.JobProgress) {
+		runningJobsMutex.Lock()
+		runningJobsById[jobId].Progress = progress
+		runningJobsMutex.Unlock()
+
+		if time.Since(lastPersistTime) < progressPersistInterval {
+			return
+		}
+		lastPersistTime = time.Now()
+		_ = JobRepo.UpdateProgress(jobId, progress)
+	}
+}
+
+func GetJob(jobId int) (*tools.Job, error) {
+	runningJobsMutex.Lock()
+	defer runningJobsMutex.Unlock()
+	if runningJob, isRunning := runningJobsById[jobId]; isRunning {
+		job := withQueuePosition(*runningJob)
+		return &job, nil
+	}
+	return JobRepo.GetJob(jobId)
+}
+
+// must be called while holding runningJobsMutex
+func withQueuePosition(job tools.Job) tools.Job {
+	if job.Status == tools.JobStatusQueued {
+		job.QueuePosition = tools.AppOperationQueue.Position(ticketsOfRunningJobs[job.Id])
+	}
+	return job
+}
+
+// ListJobs lists the most recent jobs, with the progress of running jobs taken from memory.
+func ListJobs() ([]tools.Job, error) {
+	jobs, err := JobRepo.ListJobs()
+	if err != nil {
+		return nil, err
+	}
+	runningJobsMutex.Lock()
+	defer runningJobsMutex.Unlock()
+	for i, job := range jobs {
+		if runningJob, isRunning := runningJobsById[job.Id]; isRunning {
+			jobs[i] = withQueuePosition(*runningJob)
+		}
+	}
+	return jobs, nil
+}
+
+func getRunningJobIds() []int {
+	runningJobsMutex.Lock()
+	defer runningJobsMutex.Unlock()
+	var jobIds []int
+	for jobId := range runningJobsById {
+		jobIds = append(jobIds, jobId)
+	}
+	return jobIds
+}
+
+func WaitForRunningJobs() {
+	runningJobs.Wait()
+}
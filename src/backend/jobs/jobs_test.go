@@ -0,0 +1,145 @@
+//go:build fast
+
+package jobs
+
+import (
+	"fmt"
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	common.InitializeDatabase(false, false)
+	common.WipeWholeDatabase()
+	defer common.WipeWholeDatabase()
+	m.Run()
+}
+
+func enqueue(t *testing.T, scope int, operation string) *tools.OperationTicket {
+	ticket, err := tools.AppOperationQueue.Enqueue(scope, operation)
+	assert.Nil(t, err)
+	return ticket
+}
+
+func TestSuccessfulJob(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	proceed := make(chan struct{})
+	jobId, err := StartJob(enqueue(t, 5, "sample operation"), 5, func(progress tools.ProgressReporter) error {
+		progress.Report(tools.JobProgress{PercentDone: 0.5, BytesDone: 50, TotalBytes: 100, SecondsRemaining: 3})
+		<-proceed
+		return nil
+	})
+	assert.Nil(t, err)
+
+	job := waitForProgress(t, jobId)
+	assert.Equal(t, tools.JobStatusRunning, job.Status)
+	assert.Equal(t, "sample operation", job.Operation)
+	assert.Equal(t, 5, job.AppId)
+	assert.Equal(t, int64(50), job.Progress.BytesDone)
+	assert.Nil(t, job.FinishedAt)
+
+	close(proceed)
+	WaitForRunningJobs()
+	job, err = GetJob(jobId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.JobStatusSucceeded, job.Status)
+	assert.Equal(t, 1.0, job.Progress.PercentDone)
+	assert.NotNil(t, job.FinishedAt)
+}
+
+func waitForProgress(t *testing.T, jobId int) *tools.Job {
+	for i := 0; i < 100; i++ {
+		job, err := GetJob(jobId)
+		assert.Nil(t, err)
+		if job.Progress.PercentDone > 0 {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("job did not report progress")
+	return nil
+}
+
+func TestFailedJob(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	jobId, err := StartJob(enqueue(t, tools.AllAppsScope, "sample operation"), 0, func(progress tools.ProgressReporter) error {
+		return fmt.Errorf("sample error")
+	})
+	assert.Nil(t, err)
+	WaitForRunningJobs()
+
+	job, err := GetJob(jobId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.JobStatusFailed, job.Status)
+	assert.Equal(t, "sample error", job.Error)
+
+	jobs, err := ListJobs()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(jobs))
+	assert.Equal(t, jobId, jobs[0].Id)
+}
+
+func TestQueuedJob(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	blockingTicket, err := tools.AppOperationQueue.Acquire(5, "blocking operation")
+	assert.Nil(t, err)
+	jobId, err := StartJob(enqueue(t, 5, "sample operation"), 5, func(progress tools.ProgressReporter) error {
+		return nil
+	})
+	assert.Nil(t, err)
+
+	job, err := GetJob(jobId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.JobStatusQueued, job.Status)
+	assert.Equal(t, 1, job.QueuePosition)
+
+	tools.AppOperationQueue.Release(blockingTicket)
+	WaitForRunningJobs()
+	job, err = GetJob(jobId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.JobStatusSucceeded, job.Status)
+	assert.Equal(t, 0, job.QueuePosition)
+}
+
+func TestFailInterruptedJobs(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	interruptedJobId, err := JobRepo.CreateJob("interrupted operation", 0)
+	assert.Nil(t, err)
+	runningJobId, err := JobRepo.CreateJob("running operation", 0)
+	assert.Nil(t, err)
+
+	assert.Nil(t, JobRepo.FailInterruptedJobs([]int{runningJobId}))
+	job, err := JobRepo.GetJob(interruptedJobId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.JobStatusFailed, job.Status)
+	job, err = JobRepo.GetJob(runningJobId)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.JobStatusQueued, job.Status)
+}
+
+func TestSaveFinishedJobWhichIsMissingInDatabase(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	jobId, err := JobRepo.CreateJob("sample operation", 0)
+	assert.Nil(t, err)
+	job, err := JobRepo.GetJob(jobId)
+	assert.Nil(t, err)
+
+	_, err = common.DB.Exec("DELETE FROM jobs")
+	assert.Nil(t, err)
+	now := time.Now().UTC()
+	job.Id = jobId + 10
+	job.Status = tools.JobStatusSucceeded
+	job.FinishedAt = &now
+	assert.Nil(t, JobRepo.SaveFinishedJob(*job))
+
+	savedJob, err := JobRepo.GetJob(job.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, tools.JobStatusSucceeded, savedJob.Status)
+
+	newJobId, err := JobRepo.CreateJob("sample operation", 0)
+	assert.Nil(t, err)
+	assert.True(t, newJobId > job.Id)
+}
@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"time"
+)
+
+var (
+	Logger  = tools.Logger
+	JobRepo = &JobRepository{}
+)
+
+// only the most recent jobs are listed, since older ones are of no interest and would only slow down the UI
+const maxListedJobs = 100
+
+type JobRepository struct{}
+
+const jobColumns = `job_id, operation, app_id, status, percent_done, bytes_done, total_bytes, seconds_remaining, error, created_at, finished_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row rowScanner) (*tools.Job, error) {
+	var job tools.Job
+	var createdAt, finishedAt string
+	err := row.Scan(&job.Id, &job.Operation, &job.AppId, &job.Status, &job.Progress.PercentDone, &job.Progress.BytesDone,
+		&job.Progress.TotalBytes, &job.Progress.SecondsRemaining, &job.Error, &createdAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if finishedAt != "" {
+		finishedAtTime, err := time.Parse(time.RFC3339, finishedAt)
+		if err != nil {
+			return nil, err
+		}
+		job.FinishedAt = &finishedAtTime
+	}
+	return &job, nil
+}
+
+func (r *JobRepository) CreateJob(operation string, appId int) (int, error) {
+	var jobId int
+	err := common.DB.QueryRow("INSERT INTO jobs (operation, app_id, status, created_at) VALUES ($1, $2, $3, $4) RETURNING job_id",
+		operation, appId, tools.JobStatusQueued, time.Now().UTC().Format(time.RFC3339)).Scan(&jobId)
+	if err != nil {
+		Logger.Error("failed to create job: %v", err)
+		return 0, fmt.Errorf("failed to create job")
+	}
+	return jobId, nil
+}
+
+func (r *JobRepository) SetJobRunning(jobId int) error {
+	_, err := common.DB.Exec("UPDATE jobs SET status = $1 WHERE job_id = $2", tools.JobStatusRunning, jobId)
+	if err != nil {
+		Logger.Error("failed to set job %d running: %v", jobId, err)
+		return fmt.Errorf("failed to update job status")
+	}
+	return nil
+}
+
+func (r *JobRepository) UpdateProgress(jobId int, progress tools.JobProgress) error {
+	_, err := common.DB.Exec("UPDATE jobs SET percent_done = $1, bytes_done = $2, total_bytes = $3, seconds_remaining = $4 WHERE job_id = $5",
+		progress.PercentDone, progress.BytesDone, progress.TotalBytes, progress.SecondsRemaining, jobId)
+	if err != nil {
+		Logger.Error("failed to update progress of job %d: %v", jobId, err)
+		return fmt.Errorf("failed to update job progress")
+	}
+	return nil
+}
+
+// SaveFinishedJob inserts the job if it does not exist, which happens after restoring a backup of the database app.
+func (r *JobRepository) SaveFinishedJob(job tools.Job) error {
+	finishedAt := ""
+	if job.FinishedAt != nil {
+		finishedAt = job.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	_, err := common.DB.Exec(`INSERT INTO jobs (`+jobColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (job_id) DO UPDATE SET status = $4, percent_done = $5, bytes_done = $6, total_bytes = $7, seconds_remaining = $8, error = $9, finished_at = $11`,
+		job.Id, job.Operation, job.AppId, job.Status, job.Progress.PercentDone, job.Progress.BytesDone, job.Progress.TotalBytes,
+		job.Progress.SecondsRemaining, job.Error, job.CreatedAt.UTC().Format(time.RFC3339), finishedAt)
+	if err != nil {
+		Logger.Error("failed to save finished job %d: %v", job.Id, err)
+		return fmt.Errorf("failed to save finished job")
+	}
+
+	// the sequence must never hand out the id of a job that was inserted explicitly
+	_, err = common.DB.Exec(`SELECT setval(pg_get_serial_sequence('jobs', 'job_id'), GREATEST((SELECT MAX(job_id) FROM jobs), (SELECT last_value FROM jobs_job_id_seq)))`)
+	if err != nil {
+		Logger.Error("failed to update job id sequence: %v", err)
+		return fmt.Errorf("failed to save finished job")
+	}
+	return nil
+}
+
+func (r *JobRepository) GetJob(jobId int) (*tools.Job, error) {
+	row := common.DB.QueryRow("SELECT "+jobColumns+" FROM jobs WHERE job_id = $1", jobId)
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("job does not exist")
+	} else if err != nil {
+		Logger.Error("failed to get job %d: %v", jobId, err)
+		return nil, fmt.Errorf("failed to get job")
+	}
+	return job, nil
+}
+
+func (r *JobRepository) ListJobs() ([]tools.Job, error) {
+	rows, err := common.DB.Query("SELECT "+jobColumns+" FROM jobs ORDER BY job_id DESC LIMIT $1", maxListedJobs)
+	if err != nil {
+		Logger.Error("failed to list jobs: %v", err)
+		return nil, fmt.Errorf("failed to list jobs")
+	}
+	defer rows.Close()
+
+	var jobs []tools.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			Logger.Error("failed to scan job: %v", err)
+			return nil, fmt.Errorf("failed to list jobs")
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, nil
+}
+
+// Jobs are executed in goroutines of the backend process, so jobs marked as queued or running in the database, which are not
+// executed at the moment, were interrupted by a restart of the backend or restored from a backup of the database app.
+func (r *JobRepository) FailInterruptedJobs(runningJobIds []int) error {
+	runningJobIdArray := pq.Int64Array{}
+	for _, jobId := range runningJobIds {
+		runningJobIdArray = append(runningJobIdArray, int64(jobId))
+	}
+	_, err := common.DB.Exec("UPDATE jobs SET status = $1, error = $2, finished_at = $3 WHERE status IN ($4, $5) AND job_id <> ALL($6)",
+		tools.JobStatusFailed, "interrupted", time.Now().UTC().Format(time.RFC3339), tools.JobStatusQueued, tools.JobStatusRunning, runningJobIdArray)
+	if err != nil {
+		Logger.Error("failed to mark interrupted jobs as failed: %v", err)
+		return fmt.Errorf("failed to mark interrupted jobs as failed")
+	}
+	return nil
+}
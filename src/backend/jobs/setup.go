@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"ocelot/backend/security"
+	"ocelot/backend/tools"
+)
+
+func InitializeJobsModule() {
+	err := JobRepo.FailInterruptedJobs(nil)
+	if err != nil {
+		Logger.Error("could not clean up interrupted jobs: %v", err)
+	}
+
+	routes := []security.Route{
+		{Path: tools.JobsStatusPath, HandlerFunc: JobStatusHandler, AccessLevel: security.Admin},
+		{Path: tools.JobsListPath, HandlerFunc: ListJobsHandler, AccessLevel: security.Admin},
+		{Path: tools.JobsQueuePath, HandlerFunc: ListQueuedOperationsHandler, AccessLevel: security.Admin},
+	}
+	security.RegisterRoutes(routes)
+}
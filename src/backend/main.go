@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"github.com/ocelot-cloud/shared/utils"
+	"github.com/ocelot-cloud/shared/validation"
 	"github.com/spf13/cobra"
 	"net/http"
 	"ocelot/backend/apps"
@@ -9,12 +11,19 @@ import (
 	"ocelot/backend/apps/cloud"
 	"ocelot/backend/certs"
 	"ocelot/backend/clients"
+	"ocelot/backend/jobs"
+	"ocelot/backend/notifications"
+	"ocelot/backend/recovery"
+	"ocelot/backend/repositories"
 	"ocelot/backend/security"
 	"ocelot/backend/settings"
 	"ocelot/backend/setup"
 	"ocelot/backend/ssh"
 	"ocelot/backend/tools"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 var Logger = tools.Logger
@@ -65,21 +74,75 @@ func runServer() {
 	tools.LogGlobalVariables()
 
 	setClients()
-	addWipeEndpointIfTestingProfileIsEnabled()
+	addTestingEndpointsIfTestingProfileIsEnabled()
 
 	settings.InitializeSettingsModule()
 	ssh.InitializeSshModule()
+	repositories.InitializeRepositoriesModule()
+	jobs.InitializeJobsModule()
+	notifications.InitializeNotificationsModule()
 	apps.InitializeAppsModule()
 	security.InitializeUserModule()
 	backups.InitializeBackupsModule()
+	recovery.InitializeRecoveryModule()
 	backups.StartMaintenanceAgent()
+	cloud.StartUpdateAvailabilityChecks()
 	setup.InitializeApplication()
+
+	waitForShutdownSignal()
+	shutDownGracefully()
+}
+
+// Operations like restores delete volumes before restoring them, so they should not be interrupted by a shutdown. Docker
+// kills the container if it does not stop in time, so the stop timeout of the container must be long enough, e.g. via
+// "docker stop --time 300".
+const (
+	runningOperationsShutdownTimeout = 4 * time.Minute
+	serverShutdownTimeout            = 10 * time.Second
+)
+
+func waitForShutdownSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	receivedSignal := <-signals
+	Logger.Info("Received signal %s, shutting down", receivedSignal)
 }
 
-func addWipeEndpointIfTestingProfileIsEnabled() {
+// The operation queue is closed first, so that no new operations are started. Then running operations are waited for before
+// the servers are shut down, since synchronous operations answer their requests only when they are finished.
+func shutDownGracefully() {
+	tools.AppOperationQueue.Close()
+	backgroundTasksStopped := make(chan struct{})
+	go func() {
+		// the background tasks give up waiting for queued operations once the queue is closed, but finish running ones
+		backups.StopMaintenanceAgent()
+		backups.StopReplications()
+		cloud.StopUpdateAvailabilityChecks()
+		close(backgroundTasksStopped)
+	}()
+
+	if !tools.AppOperationQueue.WaitForRunningOperations(runningOperationsShutdownTimeout) {
+		Logger.Warn("Shutting down although operations are still running: %v", tools.AppOperationQueue.ListOperations())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	certs.ShutdownServers(ctx)
+
+	select {
+	case <-backgroundTasksStopped:
+	case <-ctx.Done():
+		Logger.Warn("Background tasks did not stop in time")
+	}
+	Logger.Info("Shutdown completed")
+}
+
+func addTestingEndpointsIfTestingProfileIsEnabled() {
 	if tools.Config.OpenDataWipeEndpoint {
 		security.RegisterRoutes([]security.Route{
 			{Path: tools.WipePath, HandlerFunc: TestWipeHandler, AccessLevel: security.Anonymous},
+			{Path: tools.TestingClockAdvancePath, HandlerFunc: TestClockAdvanceHandler, AccessLevel: security.Anonymous},
+			{Path: tools.TestingMaintenanceAgentStartPath, HandlerFunc: TestMaintenanceAgentStartHandler, AccessLevel: security.Anonymous},
 		})
 	}
 }
@@ -95,6 +158,31 @@ func setClients() {
 	clients.BackupManager = backups.ProvideBackupClient()
 }
 
+// The maintenance agent is stopped, so that a cycle started by an earlier test can not interfere with the next one. The mocked
+// clock is not reset, since time only moves forward.
 func TestWipeHandler(w http.ResponseWriter, r *http.Request) {
+	backups.StopMaintenanceAgent()
 	backups.WipeDatabaseForTesting()
 }
+
+func TestClockAdvanceHandler(w http.ResponseWriter, r *http.Request) {
+	request, err := validation.ReadBody[tools.ClockAdvanceRequest](w, r)
+	if err != nil {
+		return
+	}
+	clock, ok := tools.Clock.(*tools.MockClock)
+	if !ok {
+		http.Error(w, "the clock can only be advanced if it is mocked", http.StatusBadRequest)
+		return
+	}
+	if request.Seconds < 0 {
+		http.Error(w, "the clock can not be turned back", http.StatusBadRequest)
+		return
+	}
+	now := clock.Advance(time.Duration(request.Seconds) * time.Second)
+	utils.SendJsonResponse(w, tools.ClockResponse{Now: now})
+}
+
+func TestMaintenanceAgentStartHandler(w http.ResponseWriter, r *http.Request) {
+	backups.StartMaintenanceAgentLoop()
+}
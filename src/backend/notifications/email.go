@@ -0,0 +1,114 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"ocelot/backend/tools"
+	"strings"
+	"time"
+)
+
+const implicitTlsSmtpPort = "465"
+
+var smtpTimeout = 30 * time.Second
+
+// Port 465 expects TLS from the start, all other ports are upgraded via STARTTLS if the server offers it. Authentication is
+// refused by net/smtp on unencrypted connections to remote hosts, so credentials are never sent in plain text.
+func sendEmail(notificationSettings tools.NotificationSettings, notification Notification) error {
+	client, err := connectToSmtpServer(notificationSettings.SmtpHost, notificationSettings.SmtpPort)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if notificationSettings.SmtpPort != implicitTlsSmtpPort {
+		if isStartTlsSupported, _ := client.Extension("STARTTLS"); isStartTlsSupported {
+			err = client.StartTLS(&tls.Config{ServerName: notificationSettings.SmtpHost, MinVersion: tls.VersionTLS12})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if notificationSettings.SmtpUser != "" {
+		err = client.Auth(smtp.PlainAuth("", notificationSettings.SmtpUser, notificationSettings.SmtpPassword, notificationSettings.SmtpHost))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(notificationSettings.SenderAddress)
+	if err != nil {
+		return err
+	}
+	for _, recipient := range notificationSettings.Recipients {
+		err = client.Rcpt(recipient)
+		if err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(buildEmail(notificationSettings.SenderAddress, notificationSettings.Recipients, notification))
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func connectToSmtpServer(host, port string) (*smtp.Client, error) {
+	address := net.JoinHostPort(host, port)
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	var conn net.Conn
+	var err error
+	if port == implicitTlsSmtpPort {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = conn.SetDeadline(time.Now().Add(smtpTimeout))
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func buildEmail(sender string, recipients []string, notification Notification) []byte {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", sender)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", getEmailSubject(notification))
+	fmt.Fprintf(&message, "Date: %s\r\n", notification.Timestamp.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	body := notification.Message
+	if notification.Host != "" {
+		body += "\n\nHost: " + notification.Host
+	}
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	message.WriteString("\r\n")
+	return message.Bytes()
+}
+
+func getEmailSubject(notification Notification) string {
+	return "[ocelot-cloud] " + strings.ReplaceAll(notification.Subject, "\n", " ")
+}
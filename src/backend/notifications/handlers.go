@@ -0,0 +1,75 @@
+package notifications
+
+import (
+	"github.com/ocelot-cloud/shared/utils"
+	"github.com/ocelot-cloud/shared/validation"
+	"net/http"
+	"ocelot/backend/tools"
+)
+
+func GetNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	notificationSettings, err := GetNotificationSettings()
+	if err != nil {
+		Logger.Error("Error getting notification settings: %v", err)
+		http.Error(w, "Error getting notification settings", http.StatusInternalServerError)
+		return
+	}
+	notificationSettings.SmtpPassword = ""
+	utils.SendJsonResponse(w, notificationSettings)
+}
+
+func SaveNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	notificationSettings, err := validation.ReadBody[tools.NotificationSettings](w, r)
+	if err != nil {
+		return
+	}
+	if !keepStoredSmtpPasswordAndRespondForError(w, notificationSettings) {
+		return
+	}
+
+	err = validateNotificationSettings(*notificationSettings)
+	if err != nil {
+		Logger.Info("Invalid notification settings: %v", err)
+		http.Error(w, "Invalid notification settings: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = SaveNotificationSettings(*notificationSettings)
+	if err != nil {
+		Logger.Error("Error saving notification settings: %v", err)
+		http.Error(w, "Error saving notification settings", http.StatusInternalServerError)
+		return
+	}
+	notificationSettings.SmtpPassword = ""
+	utils.SendJsonResponse(w, notificationSettings)
+}
+
+func SendTestNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	notificationSettings, err := validation.ReadBody[tools.NotificationSettings](w, r)
+	if err != nil {
+		return
+	}
+	if !keepStoredSmtpPasswordAndRespondForError(w, notificationSettings) {
+		return
+	}
+
+	err = SendTestNotification(*notificationSettings)
+	if err != nil {
+		Logger.Info("Test notification failed: %v", err)
+		http.Error(w, "Test notification failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// The stored SMTP password is never sent to the browser, so it is submitted empty unless it is changed.
+func keepStoredSmtpPasswordAndRespondForError(w http.ResponseWriter, notificationSettings *tools.NotificationSettings) bool {
+	storedNotificationSettings, err := GetNotificationSettings()
+	if err != nil {
+		Logger.Error("Error getting notification settings: %v", err)
+		http.Error(w, "Error getting notification settings", http.StatusInternalServerError)
+		return false
+	}
+	keepStoredSmtpPassword(notificationSettings, *storedNotificationSettings)
+	return true
+}
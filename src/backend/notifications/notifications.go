@@ -0,0 +1,149 @@
+package notifications
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ocelot/backend/settings"
+	"ocelot/backend/tools"
+	"time"
+)
+
+type Event string
+
+const (
+	EventMaintenanceSummary    Event = "maintenance_summary"
+	EventBackupFailed          Event = "backup_failed"
+	EventUpdateFailed          Event = "update_failed"
+	EventReplicationFailed     Event = "replication_failed"
+	EventCertificateExpiry     Event = "certificate_expiry"
+	EventRepositoryCheckFailed Event = "repository_check_failed"
+	EventMaintenanceFailed     Event = "maintenance_failed"
+	EventTest                  Event = "test"
+)
+
+var notificationSettingsKeyword settings.ConfigFieldKey = "NOTIFICATION_SETTINGS"
+
+// Notification is sent as email and is also the JSON body posted to the webhook.
+type Notification struct {
+	Event     Event     `json:"event"`
+	Host      string    `json:"host"`
+	Subject   string    `json:"subject"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Notify sends a notification through all enabled channels. Failures are only logged, since there is no one to report them to.
+func Notify(event Event, subject, message string) {
+	notificationSettings, err := GetNotificationSettings()
+	if err != nil {
+		Logger.Error("Error getting notification settings: %v", err)
+		return
+	}
+	if event == EventMaintenanceSummary && !notificationSettings.AreMaintenanceSummariesEnabled {
+		return
+	}
+	err = send(*notificationSettings, newNotification(event, subject, message))
+	if err != nil {
+		Logger.Error("Error sending notification '%s': %v", subject, err)
+	}
+}
+
+// SendTestNotification uses the given settings instead of the saved ones, so that they can be tried out before saving them.
+func SendTestNotification(notificationSettings tools.NotificationSettings) error {
+	err := validateNotificationSettings(notificationSettings)
+	if err != nil {
+		return err
+	}
+	if !notificationSettings.IsEmailEnabled && !notificationSettings.IsWebhookEnabled {
+		return fmt.Errorf("no notification channel is enabled")
+	}
+	return send(notificationSettings, newNotification(EventTest, "Test notification", "Notifications are configured correctly."))
+}
+
+func newNotification(event Event, subject, message string) Notification {
+	host, err := settings.ConfigsRepo.GetValue(settings.CONFIG_HOST)
+	if err != nil {
+		host = ""
+	}
+	return Notification{
+		Event:     event,
+		Host:      host,
+		Subject:   subject,
+		Message:   message,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+func send(notificationSettings tools.NotificationSettings, notification Notification) error {
+	var errs []error
+	if notificationSettings.IsEmailEnabled {
+		err := sendEmail(notificationSettings, notification)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	if notificationSettings.IsWebhookEnabled {
+		err := sendWebhook(notificationSettings.WebhookUrl, notification)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if len(errs) == 0 && (notificationSettings.IsEmailEnabled || notificationSettings.IsWebhookEnabled) {
+		Logger.Info("sent notification: %s", notification.Subject)
+	}
+	return errors.Join(errs...)
+}
+
+func GetNotificationSettings() (*tools.NotificationSettings, error) {
+	value, err := settings.ConfigsRepo.GetValue(notificationSettingsKeyword)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &tools.NotificationSettings{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var notificationSettings tools.NotificationSettings
+	err = json.Unmarshal([]byte(value), &notificationSettings)
+	if err != nil {
+		Logger.Error("notification settings could not be parsed: %v", err)
+		return nil, fmt.Errorf("notification settings could not be parsed")
+	}
+	return &notificationSettings, nil
+}
+
+// keepStoredSmtpPassword keeps the stored password if no new one is submitted. Without a user no password is used, so it is dropped.
+func keepStoredSmtpPassword(submitted *tools.NotificationSettings, stored tools.NotificationSettings) {
+	if submitted.SmtpUser == "" {
+		submitted.SmtpPassword = ""
+	} else if submitted.SmtpPassword == "" {
+		submitted.SmtpPassword = stored.SmtpPassword
+	}
+}
+
+func SaveNotificationSettings(notificationSettings tools.NotificationSettings) error {
+	err := validateNotificationSettings(notificationSettings)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(notificationSettings)
+	if err != nil {
+		return err
+	}
+	return settings.ConfigsRepo.SetConfigField(notificationSettingsKeyword, string(value))
+}
+
+func validateNotificationSettings(notificationSettings tools.NotificationSettings) error {
+	if notificationSettings.IsEmailEnabled {
+		if notificationSettings.SmtpHost == "" || notificationSettings.SmtpPort == "" {
+			return fmt.Errorf("smtp host and port must be set")
+		}
+		if notificationSettings.SenderAddress == "" || len(notificationSettings.Recipients) == 0 {
+			return fmt.Errorf("sender address and at least one recipient must be set")
+		}
+	}
+	if notificationSettings.IsWebhookEnabled && notificationSettings.WebhookUrl == "" {
+		return fmt.Errorf("webhook url must be set")
+	}
+	return nil
+}
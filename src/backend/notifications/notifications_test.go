@@ -0,0 +1,185 @@
+package notifications
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/ocelot-cloud/shared/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"ocelot/backend/tools"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSmtpServer is a minimal local stand-in for an SMTP server which accepts every mail and records it.
+type fakeSmtpServer struct {
+	listener   net.Listener
+	sender     string
+	recipients []string
+	data       string
+	received   chan struct{}
+}
+
+func startFakeSmtpServer(t *testing.T) *fakeSmtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := &fakeSmtpServer{listener: listener, received: make(chan struct{})}
+	go server.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return server
+}
+
+func (s *fakeSmtpServer) port() string {
+	return strings.Split(s.listener.Addr().String(), ":")[1]
+}
+
+func (s *fakeSmtpServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost fake smtp")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.sender = strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.recipients = append(s.recipients, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 bye")
+			close(s.received)
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func getSampleNotification() Notification {
+	return Notification{
+		Event:     EventBackupFailed,
+		Host:      "ocelot.example.com",
+		Subject:   "Backup of app gitea failed",
+		Message:   "restic exited with status 1",
+		Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestSendEmail(t *testing.T) {
+	server := startFakeSmtpServer(t)
+	notificationSettings := tools.NotificationSettings{
+		IsEmailEnabled: true,
+		SmtpHost:       "localhost",
+		SmtpPort:       server.port(),
+		SenderAddress:  "ocelot@example.com",
+		Recipients:     []string{"admin@example.com", "ops@example.com"},
+	}
+
+	assert.Nil(t, send(notificationSettings, getSampleNotification()))
+	select {
+	case <-server.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("mail was not received")
+	}
+	assert.Equal(t, "ocelot@example.com", server.sender)
+	assert.Equal(t, []string{"admin@example.com", "ops@example.com"}, server.recipients)
+	assert.True(t, strings.Contains(server.data, "Subject: [ocelot-cloud] Backup of app gitea failed\r\n"))
+	assert.True(t, strings.Contains(server.data, "To: admin@example.com, ops@example.com\r\n"))
+	assert.True(t, strings.Contains(server.data, "\r\n\r\nrestic exited with status 1\r\n\r\nHost: ocelot.example.com\r\n"))
+}
+
+func TestSendEmailFailsWhenServerIsUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	port := strings.Split(listener.Addr().String(), ":")[1]
+	assert.Nil(t, listener.Close())
+
+	notificationSettings := tools.NotificationSettings{IsEmailEnabled: true, SmtpHost: "localhost", SmtpPort: port, SenderAddress: "ocelot@example.com", Recipients: []string{"admin@example.com"}}
+	err = send(notificationSettings, getSampleNotification())
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "email: "))
+}
+
+func TestSendWebhook(t *testing.T) {
+	var receivedNotification Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&receivedNotification))
+	}))
+	defer server.Close()
+
+	notificationSettings := tools.NotificationSettings{IsWebhookEnabled: true, WebhookUrl: server.URL}
+	assert.Nil(t, send(notificationSettings, getSampleNotification()))
+	assert.Equal(t, getSampleNotification(), receivedNotification)
+}
+
+func TestSendWebhookFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notificationSettings := tools.NotificationSettings{IsWebhookEnabled: true, WebhookUrl: server.URL}
+	err := send(notificationSettings, getSampleNotification())
+	assert.NotNil(t, err)
+	assert.Equal(t, "webhook: webhook responded with status code 500", err.Error())
+}
+
+func TestNothingIsSentWhenNoChannelIsEnabled(t *testing.T) {
+	assert.Nil(t, send(tools.NotificationSettings{}, getSampleNotification()))
+}
+
+func TestValidateNotificationSettings(t *testing.T) {
+	assert.Nil(t, validateNotificationSettings(tools.NotificationSettings{}))
+	assert.NotNil(t, validateNotificationSettings(tools.NotificationSettings{IsEmailEnabled: true}))
+	assert.NotNil(t, validateNotificationSettings(tools.NotificationSettings{IsEmailEnabled: true, SmtpHost: "localhost", SmtpPort: "25"}))
+	assert.Nil(t, validateNotificationSettings(tools.NotificationSettings{IsEmailEnabled: true, SmtpHost: "localhost", SmtpPort: "25", SenderAddress: "a@b.cd", Recipients: []string{"c@d.ef"}}))
+	assert.NotNil(t, validateNotificationSettings(tools.NotificationSettings{IsWebhookEnabled: true}))
+	assert.Nil(t, validateNotificationSettings(tools.NotificationSettings{IsWebhookEnabled: true, WebhookUrl: "https://hooks.example.com/abc"}))
+}
+
+func TestKeepStoredSmtpPassword(t *testing.T) {
+	stored := tools.NotificationSettings{SmtpUser: "ocelot", SmtpPassword: "stored-password"}
+
+	submitted := tools.NotificationSettings{SmtpUser: "ocelot"}
+	keepStoredSmtpPassword(&submitted, stored)
+	assert.Equal(t, "stored-password", submitted.SmtpPassword)
+
+	submitted = tools.NotificationSettings{SmtpUser: "ocelot", SmtpPassword: "new-password"}
+	keepStoredSmtpPassword(&submitted, stored)
+	assert.Equal(t, "new-password", submitted.SmtpPassword)
+
+	submitted = tools.NotificationSettings{}
+	keepStoredSmtpPassword(&submitted, stored)
+	assert.Equal(t, "", submitted.SmtpPassword)
+}
@@ -0,0 +1,32 @@
+//go:build fast
+
+package notifications
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	common.InitializeDatabase(false, false)
+	common.WipeWholeDatabase()
+	defer common.WipeWholeDatabase()
+	m.Run()
+}
+
+func TestNotificationSettingsStorage(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	notificationSettings, err := GetNotificationSettings()
+	assert.Nil(t, err)
+	assert.Equal(t, tools.NotificationSettings{}, *notificationSettings)
+
+	newNotificationSettings := tools.NotificationSettings{IsWebhookEnabled: true, WebhookUrl: "https://hooks.example.com/abc", AreMaintenanceSummariesEnabled: true}
+	assert.Nil(t, SaveNotificationSettings(newNotificationSettings))
+	notificationSettings, err = GetNotificationSettings()
+	assert.Nil(t, err)
+	assert.Equal(t, newNotificationSettings, *notificationSettings)
+
+	assert.NotNil(t, SaveNotificationSettings(tools.NotificationSettings{IsWebhookEnabled: true}))
+}
@@ -0,0 +1,17 @@
+package notifications
+
+import (
+	"ocelot/backend/security"
+	"ocelot/backend/tools"
+)
+
+var Logger = tools.Logger
+
+func InitializeNotificationsModule() {
+	routes := []security.Route{
+		{Path: tools.SettingsNotificationsReadPath, HandlerFunc: GetNotificationSettingsHandler, AccessLevel: security.Admin},
+		{Path: tools.SettingsNotificationsSavePath, HandlerFunc: SaveNotificationSettingsHandler, AccessLevel: security.Admin},
+		{Path: tools.SettingsNotificationsTestPath, HandlerFunc: SendTestNotificationHandler, AccessLevel: security.Admin},
+	}
+	security.RegisterRoutes(routes)
+}
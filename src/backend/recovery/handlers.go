@@ -0,0 +1,58 @@
+package recovery
+
+import (
+	"github.com/ocelot-cloud/shared/utils"
+	"github.com/ocelot-cloud/shared/validation"
+	"net/http"
+	"ocelot/backend/jobs"
+	"ocelot/backend/tools"
+)
+
+func RecoveryStatusHandler(w http.ResponseWriter, r *http.Request) {
+	isRecoveryPossible, err := IsRecoveryPossible()
+	if err != nil {
+		Logger.Error("Error checking whether recovery is possible: %v", err)
+		http.Error(w, "Error checking whether recovery is possible", http.StatusInternalServerError)
+		return
+	}
+	utils.SendJsonResponse(w, tools.RecoveryStatus{IsRecoveryPossible: isRecoveryPossible})
+}
+
+func StartRecoveryHandler(w http.ResponseWriter, r *http.Request) {
+	request, err := validation.ReadBody[tools.BackupRepositoryRequest](w, r)
+	if err != nil {
+		return
+	}
+	isRecoveryPossible, err := IsRecoveryPossible()
+	if err != nil {
+		http.Error(w, "Error checking whether recovery is possible", http.StatusInternalServerError)
+		return
+	} else if !isRecoveryPossible {
+		http.Error(w, RecoveryNotPossibleError, http.StatusConflict)
+		return
+	}
+
+	ticket, err := tools.AppOperationQueue.Enqueue(tools.AllAppsScope, "disaster recovery")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	jobId, err := jobs.StartJob(ticket, 0, func(progress tools.ProgressReporter) error {
+		return RecoverFromRepository(request.RepositoryId, progress)
+	})
+	if err != nil {
+		http.Error(w, "Error starting recovery", http.StatusInternalServerError)
+		return
+	}
+	utils.SendJsonResponse(w, tools.JobCreationResponse{JobId: jobId})
+}
+
+func RecoverySummaryHandler(w http.ResponseWriter, r *http.Request) {
+	summary, err := GetRecoverySummary()
+	if err != nil {
+		Logger.Info("Error getting recovery summary: %v", err)
+		http.Error(w, "No recovery summary found", http.StatusNotFound)
+		return
+	}
+	utils.SendJsonResponse(w, summary)
+}
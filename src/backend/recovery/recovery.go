@@ -0,0 +1,236 @@
+package recovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/clients"
+	"ocelot/backend/repositories"
+	"ocelot/backend/settings"
+	"ocelot/backend/tools"
+	"time"
+)
+
+const RecoveryNotPossibleError = "recovery is only possible on a fresh installation without installed apps"
+
+var recoverySummaryKeyword settings.ConfigFieldKey = "LAST_RECOVERY_SUMMARY"
+
+// IsRecoveryPossible only allows a recovery on a fresh installation, since restoring the database app replaces all data,
+// including the apps, users and backup repositories.
+func IsRecoveryPossible() (bool, error) {
+	apps, err := common.AppRepo.ListApps()
+	if err != nil {
+		return false, err
+	}
+	for _, app := range apps {
+		if !common.IsOcelotDbApp(app) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// RecoverFromRepository restores the latest backup of each app found in the repository. The database app is restored first,
+// so that the other apps are restored on top of the app list, users and settings of the old installation.
+func RecoverFromRepository(repositoryId int, progress tools.ProgressReporter) error {
+	isRecoveryPossible, err := IsRecoveryPossible()
+	if err != nil {
+		return err
+	} else if !isRecoveryPossible {
+		return fmt.Errorf(RecoveryNotPossibleError)
+	}
+	repository, err := repositories.BackupRepositoryRepo.GetRepository(repositoryId)
+	if err != nil {
+		return err
+	}
+
+	summary := tools.RecoverySummary{RepositoryName: repository.Name, StartedAt: time.Now().UTC()}
+	latestBackups, err := findLatestBackupOfEachApp(repositoryId)
+	if err != nil {
+		return err
+	}
+	if len(latestBackups) == 0 {
+		return fmt.Errorf("no backups found in backup repository '%s'", repository.Name)
+	}
+
+	for i, backup := range latestBackups {
+		result := restoreBackup(backup, repositoryId)
+		summary.Apps = append(summary.Apps, result)
+		if common.IsOcelotDb(backup.Maintainer, backup.AppName) {
+			if !result.IsRestored {
+				return fmt.Errorf("restoring the database app failed, so no other apps were restored: %s", result.Error)
+			}
+			repositoryId, err = findRepositoryInRestoredDatabase(*repository)
+			if err != nil {
+				return err
+			}
+		}
+		progress.Report(tools.JobProgress{PercentDone: float64(i+1) / float64(len(latestBackups))})
+	}
+
+	missingApps, err := findAppsWithoutBackup(latestBackups)
+	if err != nil {
+		return err
+	}
+	summary.Apps = append(summary.Apps, missingApps...)
+	summary.FinishedAt = time.Now().UTC()
+	err = saveRecoverySummary(summary)
+	if err != nil {
+		return err
+	}
+
+	failedApps := countFailedApps(summary)
+	if failedApps > 0 {
+		return fmt.Errorf("%d of %d apps could not be recovered", failedApps, len(summary.Apps))
+	}
+	Logger.Info("recovered %d apps from backup repository '%s'", len(summary.Apps), repository.Name)
+	return nil
+}
+
+// The returned backups are ordered so that the database app comes first.
+func findLatestBackupOfEachApp(repositoryId int) ([]tools.BackupInfo, error) {
+	apps, err := clients.BackupManager.ListAppsInBackupRepo(repositoryId)
+	if err != nil {
+		return nil, err
+	}
+	var latestBackups []tools.BackupInfo
+	for _, app := range apps {
+		backups, err := clients.BackupManager.ListBackupsOfApp(tools.BackupListRequest{
+			Maintainer:   app.Maintainer,
+			AppName:      app.AppName,
+			RepositoryId: repositoryId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		latestBackup := findLatestBackup(backups)
+		if latestBackup == nil {
+			continue
+		}
+		if common.IsOcelotDb(latestBackup.Maintainer, latestBackup.AppName) {
+			latestBackups = append([]tools.BackupInfo{*latestBackup}, latestBackups...)
+		} else {
+			latestBackups = append(latestBackups, *latestBackup)
+		}
+	}
+	return latestBackups, nil
+}
+
+func findLatestBackup(backups []tools.BackupInfo) *tools.BackupInfo {
+	var latestBackup *tools.BackupInfo
+	for i, backup := range backups {
+		if latestBackup == nil || backup.BackupCreationTimestamp.After(latestBackup.BackupCreationTimestamp) {
+			latestBackup = &backups[i]
+		}
+	}
+	return latestBackup
+}
+
+func restoreBackup(backup tools.BackupInfo, repositoryId int) tools.AppRecoveryResult {
+	result := tools.AppRecoveryResult{
+		Maintainer:              backup.Maintainer,
+		AppName:                 backup.AppName,
+		BackupId:                backup.BackupId,
+		BackupCreationTimestamp: backup.BackupCreationTimestamp,
+	}
+	Logger.Info("recovering app %s / %s from backup %s", backup.Maintainer, backup.AppName, backup.BackupId)
+	_, err := clients.BackupManager.RestoreBackup(tools.BackupOperationRequest{BackupId: backup.BackupId, RepositoryId: repositoryId})
+	if err != nil {
+		Logger.Error("recovering app %s / %s failed: %v", backup.Maintainer, backup.AppName, err)
+		result.Error = err.Error()
+	} else {
+		result.IsRestored = true
+	}
+	return result
+}
+
+// The restored database contains the backup repositories of the old installation, which may have different ids or an
+// outdated encryption password. The connection settings used for the recovery are known to work, so they take precedence.
+func findRepositoryInRestoredDatabase(repository tools.BackupRepository) (int, error) {
+	if repository.IsLocal() {
+		return tools.LocalBackupRepositoryId, nil
+	}
+	existingRepository, err := repositories.BackupRepositoryRepo.FindRepositoryWithSameLocation(repository)
+	if err != nil {
+		return 0, err
+	}
+	if existingRepository == nil {
+		repository.Id = 0
+		return repositories.BackupRepositoryRepo.CreateRepository(repository)
+	}
+
+	updatedRepository := *existingRepository
+	updatedRepository.SshPort = repository.SshPort
+	updatedRepository.SshAuthMethod = repository.SshAuthMethod
+	updatedRepository.SshPassword = repository.SshPassword
+	updatedRepository.SshKnownHosts = repository.SshKnownHosts
+	updatedRepository.S3Region = repository.S3Region
+	updatedRepository.S3AccessKey = repository.S3AccessKey
+	updatedRepository.S3SecretKey = repository.S3SecretKey
+	updatedRepository.EncryptionPassword = repository.EncryptionPassword
+	err = repositories.BackupRepositoryRepo.UpdateRepository(updatedRepository)
+	if err != nil {
+		return 0, err
+	}
+	return updatedRepository.Id, nil
+}
+
+// Apps which are known to the restored database but have no backup in the repository are reported, since their data is lost.
+func findAppsWithoutBackup(latestBackups []tools.BackupInfo) ([]tools.AppRecoveryResult, error) {
+	apps, err := common.AppRepo.ListApps()
+	if err != nil {
+		return nil, err
+	}
+	var appsWithoutBackup []tools.AppRecoveryResult
+	for _, app := range apps {
+		if common.IsOcelotDbApp(app) || hasBackup(app, latestBackups) {
+			continue
+		}
+		appsWithoutBackup = append(appsWithoutBackup, tools.AppRecoveryResult{
+			Maintainer: app.Maintainer,
+			AppName:    app.AppName,
+			Error:      "no backup found in backup repository",
+		})
+	}
+	return appsWithoutBackup, nil
+}
+
+func hasBackup(app tools.RepoApp, backups []tools.BackupInfo) bool {
+	for _, backup := range backups {
+		if backup.Maintainer == app.Maintainer && backup.AppName == app.AppName {
+			return true
+		}
+	}
+	return false
+}
+
+func countFailedApps(summary tools.RecoverySummary) int {
+	failedApps := 0
+	for _, app := range summary.Apps {
+		if !app.IsRestored {
+			failedApps++
+		}
+	}
+	return failedApps
+}
+
+func saveRecoverySummary(summary tools.RecoverySummary) error {
+	value, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return settings.ConfigsRepo.SetConfigField(recoverySummaryKeyword, string(value))
+}
+
+func GetRecoverySummary() (*tools.RecoverySummary, error) {
+	value, err := settings.ConfigsRepo.GetValue(recoverySummaryKeyword)
+	if err != nil {
+		return nil, err
+	}
+	var summary tools.RecoverySummary
+	err = json.Unmarshal([]byte(value), &summary)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
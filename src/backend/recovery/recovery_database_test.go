@@ -0,0 +1,107 @@
+//go:build fast
+
+package recovery
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/clients"
+	"ocelot/backend/repositories"
+	"ocelot/backend/tools"
+	"testing"
+	"time"
+)
+
+// recordingBackupManager records the restores and simulates that restoring the database app replaces the backup
+// repositories with the ones of the old installation.
+type recordingBackupManager struct {
+	clients.BackupManagerInterface
+	backups                 []tools.BackupInfo
+	restoredRepositoryIds   map[string]int
+	restoredAppNames        []string
+	restoreOldRepositoryIds func()
+}
+
+func (m *recordingBackupManager) ListAppsInBackupRepo(repositoryId int) ([]tools.MaintainerAndApp, error) {
+	var apps []tools.MaintainerAndApp
+	for _, backup := range m.backups {
+		apps = append(apps, tools.MaintainerAndApp{Maintainer: backup.Maintainer, AppName: backup.AppName})
+	}
+	return apps, nil
+}
+
+func (m *recordingBackupManager) ListBackupsOfApp(request tools.BackupListRequest) ([]tools.BackupInfo, error) {
+	var backups []tools.BackupInfo
+	for _, backup := range m.backups {
+		if backup.Maintainer == request.Maintainer && backup.AppName == request.AppName {
+			backups = append(backups, backup)
+		}
+	}
+	return backups, nil
+}
+
+func (m *recordingBackupManager) RestoreBackup(request tools.BackupOperationRequest) (*tools.RestoredVersionInfo, error) {
+	for _, backup := range m.backups {
+		if backup.BackupId != request.BackupId {
+			continue
+		}
+		m.restoredAppNames = append(m.restoredAppNames, backup.AppName)
+		m.restoredRepositoryIds[backup.AppName] = request.RepositoryId
+		if common.IsOcelotDb(backup.Maintainer, backup.AppName) {
+			m.restoreOldRepositoryIds()
+		}
+	}
+	return &tools.RestoredVersionInfo{}, nil
+}
+
+func TestRecoveryRestoresDatabaseFirstAndRemapsRepository(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer common.WipeWholeDatabase()
+	originalBackupManager := clients.BackupManager
+	defer func() { clients.BackupManager = originalBackupManager }()
+
+	repository := repositories.GetDefaultRemoteRepository("recovery", tools.BackupRepositoryTypeSftp)
+	repository.Host = "backup.example.com"
+	repository.SshPort = "22"
+	repository.SshUser = "backup"
+	repository.SshPassword = "ssh-password"
+	repository.SshKnownHosts = "sample-value"
+	repository.EncryptionPassword = "current-password"
+	recoveryRepositoryId, err := repositories.BackupRepositoryRepo.CreateRepository(repository)
+	assert.Nil(t, err)
+
+	var oldRepositoryId int
+	now := time.Now()
+	backupManager := &recordingBackupManager{
+		// the app backup comes first, so the test fails if the database app is not restored first
+		backups: []tools.BackupInfo{
+			{BackupId: "app-backup", Maintainer: "maintainer", AppName: "app", BackupCreationTimestamp: now},
+			{BackupId: "db-backup", Maintainer: tools.OcelotDbMaintainer, AppName: tools.OcelotDbAppName, BackupCreationTimestamp: now},
+		},
+		restoredRepositoryIds: map[string]int{},
+		restoreOldRepositoryIds: func() {
+			assert.Nil(t, repositories.BackupRepositoryRepo.DeleteRepository(recoveryRepositoryId))
+			filler := repositories.GetDefaultRemoteRepository("other", tools.BackupRepositoryTypeSftp)
+			filler.Host = "other.example.com"
+			_, err := repositories.BackupRepositoryRepo.CreateRepository(filler)
+			assert.Nil(t, err)
+			oldRepository := repository
+			oldRepository.Name = "old-name"
+			oldRepository.EncryptionPassword = "outdated-password"
+			oldRepositoryId, err = repositories.BackupRepositoryRepo.CreateRepository(oldRepository)
+			assert.Nil(t, err)
+		},
+	}
+	clients.BackupManager = backupManager
+
+	assert.Nil(t, RecoverFromRepository(recoveryRepositoryId, nil))
+	assert.Equal(t, []string{tools.OcelotDbAppName, "app"}, backupManager.restoredAppNames)
+	assert.Equal(t, recoveryRepositoryId, backupManager.restoredRepositoryIds[tools.OcelotDbAppName])
+	assert.NotEqual(t, recoveryRepositoryId, oldRepositoryId)
+	assert.Equal(t, oldRepositoryId, backupManager.restoredRepositoryIds["app"])
+
+	remappedRepository, err := repositories.BackupRepositoryRepo.GetRepository(oldRepositoryId)
+	assert.Nil(t, err)
+	assert.Equal(t, "old-name", remappedRepository.Name)
+	assert.Equal(t, "current-password", remappedRepository.EncryptionPassword)
+}
@@ -0,0 +1,35 @@
+package recovery
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/tools"
+	"testing"
+	"time"
+)
+
+func TestFindLatestBackup(t *testing.T) {
+	assert.Nil(t, findLatestBackup(nil))
+
+	now := time.Now()
+	backups := []tools.BackupInfo{
+		{BackupId: "1", BackupCreationTimestamp: now.Add(-2 * time.Hour)},
+		{BackupId: "2", BackupCreationTimestamp: now},
+		{BackupId: "3", BackupCreationTimestamp: now.Add(-time.Hour)},
+	}
+	assert.Equal(t, "2", findLatestBackup(backups).BackupId)
+}
+
+func TestCountFailedApps(t *testing.T) {
+	summary := tools.RecoverySummary{Apps: []tools.AppRecoveryResult{
+		{AppName: "a", IsRestored: true},
+		{AppName: "b", Error: "restore failed"},
+		{AppName: "c", Error: "no backup found in backup repository"},
+	}}
+	assert.Equal(t, 2, countFailedApps(summary))
+}
+
+func TestHasBackup(t *testing.T) {
+	backups := []tools.BackupInfo{{Maintainer: "maintainer", AppName: "app"}}
+	assert.True(t, hasBackup(tools.RepoApp{Maintainer: "maintainer", AppName: "app"}, backups))
+	assert.False(t, hasBackup(tools.RepoApp{Maintainer: "maintainer", AppName: "other"}, backups))
+}
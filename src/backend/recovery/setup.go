@@ -0,0 +1,17 @@
+package recovery
+
+import (
+	"ocelot/backend/security"
+	"ocelot/backend/tools"
+)
+
+var Logger = tools.Logger
+
+func InitializeRecoveryModule() {
+	routes := []security.Route{
+		{Path: tools.RecoveryStatusPath, HandlerFunc: RecoveryStatusHandler, AccessLevel: security.Admin},
+		{Path: tools.RecoveryStartPath, HandlerFunc: StartRecoveryHandler, AccessLevel: security.Admin},
+		{Path: tools.RecoverySummaryPath, HandlerFunc: RecoverySummaryHandler, AccessLevel: security.Admin},
+	}
+	security.RegisterRoutes(routes)
+}
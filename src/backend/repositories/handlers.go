@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"github.com/ocelot-cloud/shared/utils"
+	"github.com/ocelot-cloud/shared/validation"
+	"net/http"
+	"ocelot/backend/tools"
+)
+
+func ListRepositoriesHandler(w http.ResponseWriter, r *http.Request) {
+	repositories, err := BackupRepositoryRepo.ListRepositories()
+	if err != nil {
+		Logger.Error("Failed to list backup repositories: %v", err)
+		http.Error(w, "Failed to list backup repositories", http.StatusInternalServerError)
+		return
+	}
+	for i := range repositories {
+		repositories[i] = WithoutSecrets(repositories[i])
+	}
+	utils.SendJsonResponse(w, repositories)
+}
+
+func CreateRepositoryHandler(w http.ResponseWriter, r *http.Request) {
+	repository, err := validation.ReadBody[tools.BackupRepository](w, r)
+	if err != nil {
+		return
+	}
+	if repository.IsLocal() {
+		http.Error(w, "there can only be one local backup repository", http.StatusBadRequest)
+		return
+	}
+	if err = ValidateRepository(*repository); err != nil {
+		Logger.Info("invalid backup repository: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "create backup repository")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	repositoryId, err := BackupRepositoryRepo.CreateRepository(*repository)
+	if err != nil {
+		http.Error(w, "Failed to create backup repository", http.StatusBadRequest)
+		return
+	}
+	utils.SendJsonResponse(w, tools.BackupRepositoryRequest{RepositoryId: repositoryId})
+}
+
+func UpdateRepositoryHandler(w http.ResponseWriter, r *http.Request) {
+	repository, err := validation.ReadBody[tools.BackupRepository](w, r)
+	if err != nil {
+		return
+	}
+	if repository.IsLocal() != (repository.Id == tools.LocalBackupRepositoryId) {
+		http.Error(w, "type of the local backup repository can not be changed", http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "update backup repository")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	existingRepository, err := BackupRepositoryRepo.GetRepository(repository.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	KeepStoredSecrets(repository, *existingRepository)
+	if err = ValidateRepository(*repository); err != nil {
+		Logger.Info("invalid backup repository: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	isSameLocation := hasSameLocation(*existingRepository, *repository)
+	if isSameLocation && repository.EncryptionPassword != existingRepository.EncryptionPassword {
+		isInitialized, err := BackupRepositoryRepo.IsRepositoryInitialized(repository.Id)
+		if err != nil {
+			http.Error(w, "Failed to update backup repository", http.StatusInternalServerError)
+			return
+		}
+		if isInitialized {
+			http.Error(w, EncryptionPasswordOverwriteError, http.StatusConflict)
+			return
+		}
+	}
+
+	err = BackupRepositoryRepo.UpdateRepository(*repository)
+	if err != nil {
+		http.Error(w, "Failed to update backup repository", http.StatusBadRequest)
+		return
+	}
+	if !isSameLocation {
+		err = BackupRepositoryRepo.SetRepositoryInitialized(repository.Id, false)
+		if err != nil {
+			http.Error(w, "Failed to update backup repository", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func DeleteRepositoryHandler(w http.ResponseWriter, r *http.Request) {
+	request, err := validation.ReadBody[tools.BackupRepositoryRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	ticket, err := tools.AppOperationQueue.Acquire(tools.AllAppsScope, "delete backup repository")
+	if err != nil {
+		http.Error(w, "Backend is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer tools.AppOperationQueue.Release(ticket)
+
+	err = BackupRepositoryRepo.DeleteRepository(request.RepositoryId)
+	if err != nil {
+		Logger.Info("Failed to delete backup repository: %v", err)
+		http.Error(w, "Failed to delete backup repository", http.StatusBadRequest)
+		return
+	}
+}
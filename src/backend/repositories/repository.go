@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"time"
+)
+
+var (
+	Logger               = tools.Logger
+	BackupRepositoryRepo = &BackupRepositoryRepository{}
+)
+
+type BackupRepositoryRepository struct{}
+
+const repositoryColumns = `repository_id, name, type, is_enabled, backup_interval_days, keep_daily, keep_weekly, keep_monthly,
+	host, ssh_port, ssh_user, ssh_auth_method, ssh_password, ssh_known_hosts,
+	s3_endpoint, s3_bucket, s3_prefix, s3_region, s3_access_key, s3_secret_key, encryption_password,
+	upload_limit_kib, download_limit_kib, is_transfer_window_enabled, transfer_window_start_hour, transfer_window_end_hour`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRepository(row rowScanner) (*tools.BackupRepository, error) {
+	var r tools.BackupRepository
+	err := row.Scan(&r.Id, &r.Name, &r.Type, &r.IsEnabled, &r.BackupIntervalDays, &r.KeepDaily, &r.KeepWeekly, &r.KeepMonthly,
+		&r.Host, &r.SshPort, &r.SshUser, &r.SshAuthMethod, &r.SshPassword, &r.SshKnownHosts,
+		&r.S3Endpoint, &r.S3Bucket, &r.S3Prefix, &r.S3Region, &r.S3AccessKey, &r.S3SecretKey, &r.EncryptionPassword,
+		&r.UploadLimitKib, &r.DownloadLimitKib, &r.IsTransferWindowEnabled, &r.TransferWindowStartHour, &r.TransferWindowEndHour)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (b *BackupRepositoryRepository) ListRepositories() ([]tools.BackupRepository, error) {
+	rows, err := common.DB.Query("SELECT " + repositoryColumns + " FROM backup_repositories ORDER BY repository_id")
+	if err != nil {
+		Logger.Error("failed to list backup repositories: %v", err)
+		return nil, fmt.Errorf("failed to list backup repositories")
+	}
+	defer rows.Close()
+
+	var repositories []tools.BackupRepository
+	for rows.Next() {
+		repository, err := scanRepository(rows)
+		if err != nil {
+			Logger.Error("failed to scan backup repository: %v", err)
+			return nil, fmt.Errorf("failed to list backup repositories")
+		}
+		repositories = append(repositories, *repository)
+	}
+	return repositories, nil
+}
+
+func (b *BackupRepositoryRepository) ListEnabledRepositories() ([]tools.BackupRepository, error) {
+	repositories, err := b.ListRepositories()
+	if err != nil {
+		return nil, err
+	}
+	var enabledRepositories []tools.BackupRepository
+	for _, repository := range repositories {
+		if repository.IsEnabled {
+			enabledRepositories = append(enabledRepositories, repository)
+		}
+	}
+	return enabledRepositories, nil
+}
+
+func (b *BackupRepositoryRepository) GetRepository(repositoryId int) (*tools.BackupRepository, error) {
+	row := common.DB.QueryRow("SELECT "+repositoryColumns+" FROM backup_repositories WHERE repository_id = $1", repositoryId)
+	repository, err := scanRepository(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("backup repository does not exist")
+	} else if err != nil {
+		Logger.Error("failed to get backup repository %d: %v", repositoryId, err)
+		return nil, fmt.Errorf("failed to get backup repository")
+	}
+	return repository, nil
+}
+
+func (b *BackupRepositoryRepository) CreateRepository(r tools.BackupRepository) (int, error) {
+	var repositoryId int
+	err := common.DB.QueryRow(`INSERT INTO backup_repositories (name, type, is_enabled, backup_interval_days, keep_daily, keep_weekly, keep_monthly,
+		host, ssh_port, ssh_user, ssh_auth_method, ssh_password, ssh_known_hosts,
+		s3_endpoint, s3_bucket, s3_prefix, s3_region, s3_access_key, s3_secret_key, encryption_password,
+		upload_limit_kib, download_limit_kib, is_transfer_window_enabled, transfer_window_start_hour, transfer_window_end_hour)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25) RETURNING repository_id`,
+		r.Name, r.Type, r.IsEnabled, r.BackupIntervalDays, r.KeepDaily, r.KeepWeekly, r.KeepMonthly,
+		r.Host, r.SshPort, r.SshUser, r.SshAuthMethod, r.SshPassword, r.SshKnownHosts,
+		r.S3Endpoint, r.S3Bucket, r.S3Prefix, r.S3Region, r.S3AccessKey, r.S3SecretKey, r.EncryptionPassword,
+		r.UploadLimitKib, r.DownloadLimitKib, r.IsTransferWindowEnabled, r.TransferWindowStartHour, r.TransferWindowEndHour).Scan(&repositoryId)
+	if err != nil {
+		Logger.Error("failed to create backup repository %s: %v", r.Name, err)
+		return 0, fmt.Errorf("failed to create backup repository")
+	}
+	return repositoryId, nil
+}
+
+func (b *BackupRepositoryRepository) UpdateRepository(r tools.BackupRepository) error {
+	result, err := common.DB.Exec(`UPDATE backup_repositories SET name = $2, type = $3, is_enabled = $4, backup_interval_days = $5,
+		keep_daily = $6, keep_weekly = $7, keep_monthly = $8,
+		host = $9, ssh_port = $10, ssh_user = $11, ssh_auth_method = $12, ssh_password = $13, ssh_known_hosts = $14,
+		s3_endpoint = $15, s3_bucket = $16, s3_prefix = $17, s3_region = $18, s3_access_key = $19, s3_secret_key = $20, encryption_password = $21,
+		upload_limit_kib = $22, download_limit_kib = $23, is_transfer_window_enabled = $24, transfer_window_start_hour = $25, transfer_window_end_hour = $26
+		WHERE repository_id = $1`,
+		r.Id, r.Name, r.Type, r.IsEnabled, r.BackupIntervalDays, r.KeepDaily, r.KeepWeekly, r.KeepMonthly,
+		r.Host, r.SshPort, r.SshUser, r.SshAuthMethod, r.SshPassword, r.SshKnownHosts,
+		r.S3Endpoint, r.S3Bucket, r.S3Prefix, r.S3Region, r.S3AccessKey, r.S3SecretKey, r.EncryptionPassword,
+		r.UploadLimitKib, r.DownloadLimitKib, r.IsTransferWindowEnabled, r.TransferWindowStartHour, r.TransferWindowEndHour)
+	if err != nil {
+		Logger.Error("failed to update backup repository %d: %v", r.Id, err)
+		return fmt.Errorf("failed to update backup repository")
+	}
+	return expectOneAffectedRow(result)
+}
+
+func (b *BackupRepositoryRepository) DeleteRepository(repositoryId int) error {
+	if repositoryId == tools.LocalBackupRepositoryId {
+		return fmt.Errorf("local backup repository can not be deleted")
+	}
+	result, err := common.DB.Exec("DELETE FROM backup_repositories WHERE repository_id = $1", repositoryId)
+	if err != nil {
+		Logger.Error("failed to delete backup repository %d: %v", repositoryId, err)
+		return fmt.Errorf("failed to delete backup repository")
+	}
+	return expectOneAffectedRow(result)
+}
+
+func (b *BackupRepositoryRepository) GetLastAutoBackupDate(repositoryId int) (*time.Time, error) {
+	var lastAutoBackupDateString string
+	err := common.DB.QueryRow("SELECT last_auto_backup_date FROM backup_repositories WHERE repository_id = $1", repositoryId).Scan(&lastAutoBackupDateString)
+	if err != nil {
+		Logger.Error("failed to get last auto backup date of repository %d: %v", repositoryId, err)
+		return nil, fmt.Errorf("failed to get last auto backup date")
+	}
+	lastAutoBackupDate, err := time.Parse(time.RFC3339, lastAutoBackupDateString)
+	if err != nil {
+		Logger.Error("failed to parse last auto backup date '%s': %v", lastAutoBackupDateString, err)
+		return nil, fmt.Errorf("failed to parse last auto backup date")
+	}
+	return &lastAutoBackupDate, nil
+}
+
+func (b *BackupRepositoryRepository) SetLastAutoBackupDate(repositoryId int, date time.Time) error {
+	_, err := common.DB.Exec("UPDATE backup_repositories SET last_auto_backup_date = $2 WHERE repository_id = $1", repositoryId, date.UTC().Format(time.RFC3339))
+	if err != nil {
+		Logger.Error("failed to set last auto backup date of repository %d: %v", repositoryId, err)
+		return fmt.Errorf("failed to set last auto backup date")
+	}
+	return nil
+}
+
+// A repository is initialized once restic created it at its location. From then on, the encryption password can only be
+// changed by rotating the restic key, since the existing snapshots would become unreadable otherwise.
+func (b *BackupRepositoryRepository) IsRepositoryInitialized(repositoryId int) (bool, error) {
+	var isInitialized bool
+	err := common.DB.QueryRow("SELECT is_initialized FROM backup_repositories WHERE repository_id = $1", repositoryId).Scan(&isInitialized)
+	if err != nil {
+		Logger.Error("failed to get initialization state of repository %d: %v", repositoryId, err)
+		return false, fmt.Errorf("failed to get initialization state of backup repository")
+	}
+	return isInitialized, nil
+}
+
+func (b *BackupRepositoryRepository) SetRepositoryInitialized(repositoryId int, isInitialized bool) error {
+	_, err := common.DB.Exec("UPDATE backup_repositories SET is_initialized = $2 WHERE repository_id = $1", repositoryId, isInitialized)
+	if err != nil {
+		Logger.Error("failed to set initialization state of repository %d: %v", repositoryId, err)
+		return fmt.Errorf("failed to set initialization state of backup repository")
+	}
+	return nil
+}
+
+func (b *BackupRepositoryRepository) SetEncryptionPassword(repositoryId int, encryptionPassword string) error {
+	result, err := common.DB.Exec("UPDATE backup_repositories SET encryption_password = $2 WHERE repository_id = $1", repositoryId, encryptionPassword)
+	if err != nil {
+		Logger.Error("failed to set encryption password of repository %d: %v", repositoryId, err)
+		return fmt.Errorf("failed to set encryption password of backup repository")
+	}
+	return expectOneAffectedRow(result)
+}
+
+func expectOneAffectedRow(result sql.Result) error {
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows != 1 {
+		return fmt.Errorf("backup repository does not exist")
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"errors"
+	"ocelot/backend/tools"
+	"time"
+)
+
+const (
+	maxBackupIntervalDays = 365
+	maxBackupsToKeep      = 1000
+	maxBandwidthLimitKib  = 10000000
+
+	EncryptionPasswordOverwriteError = "the encryption password of an existing backup repository can not be overwritten, please change it via password rotation"
+)
+
+var (
+	DefaultBackupIntervalDays = 1
+	DefaultKeepDaily          = 7
+	DefaultKeepWeekly         = 4
+	DefaultKeepMonthly        = 12
+)
+
+func ValidateRepository(repository tools.BackupRepository) error {
+	// an interval of 0 days means that the repository receives a backup on every run of the backup schedule
+	if repository.BackupIntervalDays < 0 || repository.BackupIntervalDays > maxBackupIntervalDays {
+		return errors.New("backup interval must be between 0 and 365 days")
+	}
+	for _, numberToKeep := range []int{repository.KeepDaily, repository.KeepWeekly, repository.KeepMonthly} {
+		if numberToKeep < 0 || numberToKeep > maxBackupsToKeep {
+			return errors.New("number of backups to keep must be between 0 and 1000")
+		}
+	}
+	if repository.KeepDaily+repository.KeepWeekly+repository.KeepMonthly == 0 {
+		return errors.New("retention policy must keep at least one backup")
+	}
+	for _, limit := range []int{repository.UploadLimitKib, repository.DownloadLimitKib} {
+		if limit < 0 || limit > maxBandwidthLimitKib {
+			return errors.New("bandwidth limits must be between 0 and 10000000 KiB/s")
+		}
+	}
+	if repository.IsTransferWindowEnabled {
+		for _, hour := range []int{repository.TransferWindowStartHour, repository.TransferWindowEndHour} {
+			if hour < 0 || hour > 23 {
+				return errors.New("transfer window hours must be between 0 and 23")
+			}
+		}
+		if repository.TransferWindowStartHour == repository.TransferWindowEndHour {
+			return errors.New("transfer window must not start and end at the same hour")
+		}
+	}
+
+	switch repository.Type {
+	case tools.BackupRepositoryTypeSftp:
+		if repository.Host == "" || repository.SshPort == "" || repository.SshUser == "" || repository.EncryptionPassword == "" {
+			return errors.New("sftp repository requires host, ssh port, ssh user and encryption password")
+		}
+		if !repository.UsesSshKey() && repository.SshPassword == "" {
+			return errors.New("sftp repository requires an ssh password unless the ssh key is used")
+		}
+	case tools.BackupRepositoryTypeS3:
+		if repository.S3Endpoint == "" || repository.S3Bucket == "" || repository.S3AccessKey == "" || repository.S3SecretKey == "" || repository.EncryptionPassword == "" {
+			return errors.New("s3 repository requires endpoint, bucket, access key, secret key and encryption password")
+		}
+	}
+	return nil
+}
+
+// hasSameLocation tells whether both configurations point to the same restic repository. If the location changes, the
+// repository at the new location is initialized with the new encryption password.
+func hasSameLocation(repository, other tools.BackupRepository) bool {
+	return repository.Type == other.Type && repository.Host == other.Host && repository.SshPort == other.SshPort && repository.SshUser == other.SshUser &&
+		repository.S3Endpoint == other.S3Endpoint && repository.S3Bucket == other.S3Bucket && repository.S3Prefix == other.S3Prefix
+}
+
+// WithoutSecrets removes the passwords and keys of the repository, since they are never sent to the browser.
+func WithoutSecrets(repository tools.BackupRepository) tools.BackupRepository {
+	repository.SshPassword = ""
+	repository.S3SecretKey = ""
+	repository.EncryptionPassword = ""
+	return repository
+}
+
+// KeepStoredSecrets keeps the stored secrets which are submitted empty, since the browser only submits secrets which are
+// changed. The ssh password is dropped when the ssh key is used.
+func KeepStoredSecrets(submitted *tools.BackupRepository, stored tools.BackupRepository) {
+	if submitted.UsesSshKey() {
+		submitted.SshPassword = ""
+	} else if submitted.SshPassword == "" {
+		submitted.SshPassword = stored.SshPassword
+	}
+	if submitted.S3SecretKey == "" {
+		submitted.S3SecretKey = stored.S3SecretKey
+	}
+	if submitted.EncryptionPassword == "" {
+		submitted.EncryptionPassword = stored.EncryptionPassword
+	}
+}
+
+func (b *BackupRepositoryRepository) FindRepositoryWithSameLocation(repository tools.BackupRepository) (*tools.BackupRepository, error) {
+	repositories, err := b.ListRepositories()
+	if err != nil {
+		return nil, err
+	}
+	for _, existingRepository := range repositories {
+		if hasSameLocation(existingRepository, repository) {
+			return &existingRepository, nil
+		}
+	}
+	return nil, nil
+}
+
+// IsAutoBackupDue compares calendar days, so that a daily interval is not skipped when the maintenance cycle starts a few minutes earlier than on the day before.
+// The days are taken from the location of "now", but counted in UTC, so that days shortened by daylight saving time count fully.
+func IsAutoBackupDue(now, lastAutoBackupDate time.Time, backupIntervalDays int) bool {
+	lastAutoBackupDate = lastAutoBackupDate.In(now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	lastAutoBackupDay := time.Date(lastAutoBackupDate.Year(), lastAutoBackupDate.Month(), lastAutoBackupDate.Day(), 0, 0, 0, 0, time.UTC)
+	daysSinceLastAutoBackup := int(today.Sub(lastAutoBackupDay).Hours() / 24)
+	return daysSinceLastAutoBackup >= backupIntervalDays
+}
+
+func (b *BackupRepositoryRepository) ListRepositoriesDueForAutoBackup(now time.Time) ([]tools.BackupRepository, error) {
+	repositories, err := b.ListEnabledRepositories()
+	if err != nil {
+		return nil, err
+	}
+	var dueRepositories []tools.BackupRepository
+	for _, repository := range repositories {
+		lastAutoBackupDate, err := b.GetLastAutoBackupDate(repository.Id)
+		if err != nil {
+			return nil, err
+		}
+		if IsAutoBackupDue(now, *lastAutoBackupDate, repository.BackupIntervalDays) {
+			dueRepositories = append(dueRepositories, repository)
+		}
+	}
+	return dueRepositories, nil
+}
+
+func GetDefaultRemoteRepository(name, repositoryType string) tools.BackupRepository {
+	return tools.BackupRepository{
+		Name:               name,
+		Type:               repositoryType,
+		IsEnabled:          true,
+		SshAuthMethod:      tools.SshAuthMethodPassword,
+		BackupIntervalDays: DefaultBackupIntervalDays,
+		KeepDaily:          DefaultKeepDaily,
+		KeepWeekly:         DefaultKeepWeekly,
+		KeepMonthly:        DefaultKeepMonthly,
+	}
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/tools"
+	"testing"
+	"time"
+)
+
+func TestIsAutoBackupDue(t *testing.T) {
+	lastAutoBackup := time.Date(2025, 3, 10, 4, 5, 0, 0, time.UTC)
+
+	assert.False(t, IsAutoBackupDue(lastAutoBackup.Add(time.Hour), lastAutoBackup, 1))
+	assert.True(t, IsAutoBackupDue(time.Date(2025, 3, 11, 4, 0, 0, 0, time.UTC), lastAutoBackup, 1))
+	assert.False(t, IsAutoBackupDue(time.Date(2025, 3, 16, 4, 0, 0, 0, time.UTC), lastAutoBackup, 7))
+	assert.True(t, IsAutoBackupDue(time.Date(2025, 3, 17, 4, 0, 0, 0, time.UTC), lastAutoBackup, 7))
+	assert.True(t, IsAutoBackupDue(time.Date(2025, 3, 17, 4, 0, 0, 0, time.UTC), time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), 30))
+	assert.True(t, IsAutoBackupDue(lastAutoBackup.Add(6*time.Hour), lastAutoBackup, 0))
+
+	// 23:30 UTC is already the next day in Berlin
+	berlin := time.FixedZone("CET", 3600)
+	lateAutoBackup := time.Date(2025, 3, 10, 23, 30, 0, 0, time.UTC)
+	assert.False(t, IsAutoBackupDue(time.Date(2025, 3, 11, 22, 0, 0, 0, time.UTC).In(berlin), lateAutoBackup, 1))
+	assert.True(t, IsAutoBackupDue(time.Date(2025, 3, 11, 22, 0, 0, 0, time.UTC), lateAutoBackup, 1))
+}
+
+func TestValidateRepository(t *testing.T) {
+	repository := GetDefaultRemoteRepository("remote", tools.BackupRepositoryTypeS3)
+	assert.NotNil(t, ValidateRepository(repository))
+
+	repository.S3Endpoint = "http://localhost:9000"
+	repository.S3Bucket = "backups"
+	repository.S3AccessKey = "minioadmin"
+	repository.S3SecretKey = "minio-password"
+	repository.EncryptionPassword = "restic-password"
+	assert.Nil(t, ValidateRepository(repository))
+
+	repository.BackupIntervalDays = -1
+	assert.NotNil(t, ValidateRepository(repository))
+	repository.BackupIntervalDays = 0
+	assert.Nil(t, ValidateRepository(repository))
+	repository.BackupIntervalDays = 366
+	assert.NotNil(t, ValidateRepository(repository))
+	repository.BackupIntervalDays = 7
+	assert.Nil(t, ValidateRepository(repository))
+
+	repository.KeepDaily, repository.KeepWeekly, repository.KeepMonthly = 0, 0, 0
+	assert.NotNil(t, ValidateRepository(repository))
+	repository.KeepMonthly = 1
+	assert.Nil(t, ValidateRepository(repository))
+	repository.KeepDaily = -1
+	assert.NotNil(t, ValidateRepository(repository))
+	repository.KeepDaily = 7
+
+	repository.UploadLimitKib = -1
+	assert.NotNil(t, ValidateRepository(repository))
+	repository.UploadLimitKib = 1024
+	assert.Nil(t, ValidateRepository(repository))
+
+	repository.IsTransferWindowEnabled = true
+	assert.NotNil(t, ValidateRepository(repository))
+	repository.TransferWindowStartHour, repository.TransferWindowEndHour = 22, 24
+	assert.NotNil(t, ValidateRepository(repository))
+	repository.TransferWindowEndHour = 6
+	assert.Nil(t, ValidateRepository(repository))
+}
+
+func TestValidateSftpRepositoryWithSshKey(t *testing.T) {
+	repository := GetDefaultRemoteRepository("remote", tools.BackupRepositoryTypeSftp)
+	repository.Host = "localhost"
+	repository.SshPort = "2222"
+	repository.SshUser = "sshadmin"
+	repository.EncryptionPassword = "restic-password"
+	assert.NotNil(t, ValidateRepository(repository))
+
+	repository.SshAuthMethod = tools.SshAuthMethodKey
+	assert.Nil(t, ValidateRepository(repository))
+}
+
+func TestHasSameLocation(t *testing.T) {
+	repository := GetDefaultRemoteRepository("remote", tools.BackupRepositoryTypeSftp)
+	repository.Host = "localhost"
+	repository.SshUser = "sshadmin"
+	repository.EncryptionPassword = "restic-password"
+
+	other := repository
+	other.EncryptionPassword = "other-password"
+	assert.True(t, hasSameLocation(repository, other))
+	other.SshPort = "2223"
+	assert.False(t, hasSameLocation(repository, other))
+	other.SshPort = repository.SshPort
+	other.Host = "remote.example.com"
+	assert.False(t, hasSameLocation(repository, other))
+}
+
+func TestKeepStoredSecrets(t *testing.T) {
+	stored := GetDefaultRemoteRepository("remote", tools.BackupRepositoryTypeSftp)
+	stored.SshPassword = "ssh-password"
+	stored.S3SecretKey = "s3-secret-key"
+	stored.EncryptionPassword = "restic-password"
+
+	submitted := WithoutSecrets(stored)
+	assert.Equal(t, "", submitted.SshPassword)
+	assert.Equal(t, "", submitted.S3SecretKey)
+	assert.Equal(t, "", submitted.EncryptionPassword)
+	KeepStoredSecrets(&submitted, stored)
+	assert.Equal(t, stored, submitted)
+
+	submitted = WithoutSecrets(stored)
+	submitted.EncryptionPassword = "new-password"
+	KeepStoredSecrets(&submitted, stored)
+	assert.Equal(t, "new-password", submitted.EncryptionPassword)
+	assert.Equal(t, "ssh-password", submitted.SshPassword)
+
+	submitted = WithoutSecrets(stored)
+	submitted.SshAuthMethod = tools.SshAuthMethodKey
+	KeepStoredSecrets(&submitted, stored)
+	assert.Equal(t, "", submitted.SshPassword)
+}
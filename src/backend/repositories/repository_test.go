@@ -0,0 +1,129 @@
+//go:build fast
+
+package repositories
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"ocelot/backend/apps/common"
+	"ocelot/backend/tools"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	common.InitializeDatabase(false, false)
+	common.WipeWholeDatabase()
+	defer common.WipeWholeDatabase()
+	m.Run()
+}
+
+func getSampleSftpRepository() tools.BackupRepository {
+	repository := GetDefaultRemoteRepository("remote", tools.BackupRepositoryTypeSftp)
+	repository.Host = "localhost"
+	repository.SshPort = "2222"
+	repository.SshUser = "sshadmin"
+	repository.SshPassword = "ssh-password"
+	repository.SshKnownHosts = "sample-value"
+	repository.EncryptionPassword = "restic-password"
+	return repository
+}
+
+func TestLocalRepositoryExistsByDefault(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	repositories, err := BackupRepositoryRepo.ListRepositories()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(repositories))
+	assert.Equal(t, tools.LocalBackupRepositoryId, repositories[0].Id)
+	assert.True(t, repositories[0].IsLocal())
+	assert.True(t, repositories[0].IsEnabled)
+
+	assert.NotNil(t, BackupRepositoryRepo.DeleteRepository(tools.LocalBackupRepositoryId))
+}
+
+func TestRemoteRepositoryManagement(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	sampleRepository := getSampleSftpRepository()
+
+	repositoryId, err := BackupRepositoryRepo.CreateRepository(sampleRepository)
+	assert.Nil(t, err)
+	sampleRepository.Id = repositoryId
+
+	resultRepository, err := BackupRepositoryRepo.GetRepository(repositoryId)
+	assert.Nil(t, err)
+	assert.True(t, reflect.DeepEqual(sampleRepository, *resultRepository))
+
+	_, err = BackupRepositoryRepo.CreateRepository(sampleRepository)
+	assert.NotNil(t, err)
+
+	resultRepository.IsEnabled = false
+	resultRepository.KeepDaily = 3
+	resultRepository.UploadLimitKib = 1024
+	resultRepository.IsTransferWindowEnabled = true
+	resultRepository.TransferWindowStartHour, resultRepository.TransferWindowEndHour = 22, 6
+	assert.Nil(t, BackupRepositoryRepo.UpdateRepository(*resultRepository))
+	enabledRepositories, err := BackupRepositoryRepo.ListEnabledRepositories()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(enabledRepositories))
+	assert.Equal(t, tools.LocalBackupRepositoryId, enabledRepositories[0].Id)
+
+	updatedRepository, err := BackupRepositoryRepo.GetRepository(repositoryId)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, updatedRepository.KeepDaily)
+	assert.Equal(t, 1024, updatedRepository.UploadLimitKib)
+	assert.True(t, updatedRepository.IsTransferWindowEnabled)
+	assert.Equal(t, 22, updatedRepository.TransferWindowStartHour)
+	assert.Equal(t, 6, updatedRepository.TransferWindowEndHour)
+
+	assert.Nil(t, BackupRepositoryRepo.DeleteRepository(repositoryId))
+	_, err = BackupRepositoryRepo.GetRepository(repositoryId)
+	assert.NotNil(t, err)
+	assert.NotNil(t, BackupRepositoryRepo.DeleteRepository(repositoryId))
+}
+
+func TestRepositoriesDueForAutoBackup(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	remoteRepository := getSampleSftpRepository()
+	remoteRepository.BackupIntervalDays = 7
+	repositoryId, err := BackupRepositoryRepo.CreateRepository(remoteRepository)
+	assert.Nil(t, err)
+
+	now := time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)
+	dueRepositories, err := BackupRepositoryRepo.ListRepositoriesDueForAutoBackup(now)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(dueRepositories))
+
+	assert.Nil(t, BackupRepositoryRepo.SetLastAutoBackupDate(tools.LocalBackupRepositoryId, now))
+	assert.Nil(t, BackupRepositoryRepo.SetLastAutoBackupDate(repositoryId, now))
+
+	tomorrow := now.AddDate(0, 0, 1)
+	dueRepositories, err = BackupRepositoryRepo.ListRepositoriesDueForAutoBackup(tomorrow)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(dueRepositories))
+	assert.Equal(t, tools.LocalBackupRepositoryId, dueRepositories[0].Id)
+
+	nextWeek := now.AddDate(0, 0, 7)
+	dueRepositories, err = BackupRepositoryRepo.ListRepositoriesDueForAutoBackup(nextWeek)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(dueRepositories))
+}
+
+func TestRepositoryInitializationAndPasswordChange(t *testing.T) {
+	defer common.WipeWholeDatabase()
+	repositoryId, err := BackupRepositoryRepo.CreateRepository(getSampleSftpRepository())
+	assert.Nil(t, err)
+
+	isInitialized, err := BackupRepositoryRepo.IsRepositoryInitialized(repositoryId)
+	assert.Nil(t, err)
+	assert.False(t, isInitialized)
+	assert.Nil(t, BackupRepositoryRepo.SetRepositoryInitialized(repositoryId, true))
+	isInitialized, err = BackupRepositoryRepo.IsRepositoryInitialized(repositoryId)
+	assert.Nil(t, err)
+	assert.True(t, isInitialized)
+
+	assert.Nil(t, BackupRepositoryRepo.SetEncryptionPassword(repositoryId, "new-password"))
+	repository, err := BackupRepositoryRepo.GetRepository(repositoryId)
+	assert.Nil(t, err)
+	assert.Equal(t, "new-password", repository.EncryptionPassword)
+	assert.NotNil(t, BackupRepositoryRepo.SetEncryptionPassword(repositoryId+1, "new-password"))
+}
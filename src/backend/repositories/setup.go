@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"ocelot/backend/security"
+	"ocelot/backend/tools"
+)
+
+func InitializeRepositoriesModule() {
+	routes := []security.Route{
+		{Path: tools.SettingsRepositoriesListPath, HandlerFunc: ListRepositoriesHandler, AccessLevel: security.Admin},
+		{Path: tools.SettingsRepositoriesCreatePath, HandlerFunc: CreateRepositoryHandler, AccessLevel: security.Admin},
+		{Path: tools.SettingsRepositoriesUpdatePath, HandlerFunc: UpdateRepositoryHandler, AccessLevel: security.Admin},
+		{Path: tools.SettingsRepositoriesDeletePath, HandlerFunc: DeleteRepositoryHandler, AccessLevel: security.Admin},
+	}
+	security.RegisterRoutes(routes)
+}
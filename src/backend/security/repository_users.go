@@ -34,7 +34,7 @@ func (r *UserRepositoryImpl) IsExpired(cookieValue string) (bool, error) {
 		return false, errors.New("failed to parse cookie expiration date")
 	}
 
-	return time.Now().After(cookieExpirationDate), nil
+	return tools.Clock.Now().After(cookieExpirationDate), nil
 }
 
 // Not meant for production
@@ -315,7 +315,7 @@ func (r *UserRepositoryImpl) ListUsers() ([]UserInfo, error) {
 }
 
 func (r *UserRepositoryImpl) UpdateCookieExpirationDate(cookieValue string) error {
-	_, err := common.DB.Exec("UPDATE users SET cookie_expiration_date = $1 WHERE hashed_cookie_value = $2", time.Now().Add(tools.CookieExpirationTime).Format(time.RFC3339), hashCookie(cookieValue))
+	_, err := common.DB.Exec("UPDATE users SET cookie_expiration_date = $1 WHERE hashed_cookie_value = $2", tools.Clock.Now().Add(tools.CookieExpirationTime).Format(time.RFC3339), hashCookie(cookieValue))
 	if err != nil {
 		tools.Logger.Error("Failed to update cookie expiration date: %v", err)
 		return fmt.Errorf("failed to update cookie expiration date")
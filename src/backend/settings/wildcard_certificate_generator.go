@@ -330,18 +330,18 @@ func finalizeAndSaveCertificate(ctx context.Context, client *acme.Client, order
 }
 
 func Retry(operationName string, frequency, maxTime time.Duration, fn func() error) error {
-	deadline := time.Now().Add(maxTime)
+	deadline := tools.Clock.Now().Add(maxTime)
 	for {
 		Logger.Info("Retrying operation: %s", operationName)
 		if err := fn(); err == nil {
 			Logger.Info("Retry successful of operation: %s", operationName)
 			return nil
-		} else if time.Now().After(deadline) {
+		} else if tools.Clock.Now().After(deadline) {
 			Logger.Error("Retry deadline exceeded for operation: %s", operationName)
 			return errors.New("retry deadline exceeded for operation: " + operationName)
 		} else {
 			Logger.Info("Attempt failed for operation '%s': %v, waiting...", operationName, err)
-			time.Sleep(frequency)
+			tools.Clock.Sleep(frequency)
 		}
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/ocelot-cloud/shared/assert"
 	"ocelot/backend/tools"
 	"testing"
+	"time"
 )
 
 // The default setting is 'true' because this test requires a developer to intervene manually. If it were to be executed in GitHub Actions, it would crash the CI pipeline. For testing and development purposes, however, it should be temporarily set to 'false'.
@@ -30,3 +31,24 @@ func TestGetDnsRecordStub(t *testing.T) {
 	assert.Equal(t, sampleBaseKeyAuth, record.BaseKeyAuth)
 	assert.Equal(t, sampleWildcardKeyAuth, record.WildcardKeyAuth)
 }
+
+func TestRetryReturnsUnderMockedClock(t *testing.T) {
+	realClock := tools.Clock
+	tools.Clock = tools.ProvideClock(true)
+	t.Cleanup(func() { tools.Clock = realClock })
+
+	attempts := 0
+	err := Retry("test operation", 10*time.Millisecond, time.Second, func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("attempt %d failed", attempts)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+
+	err = Retry("failing operation", 10*time.Millisecond, 50*time.Millisecond, func() error { return fmt.Errorf("failed") })
+	assert.NotNil(t, err)
+	assert.Equal(t, "retry deadline exceeded for operation: failing operation", err.Error())
+}
@@ -7,7 +7,6 @@ import (
 	"ocelot/backend/security"
 	"ocelot/backend/tools"
 	"os"
-	"time"
 )
 
 const (
@@ -36,7 +35,7 @@ func initializeSampleUser() {
 	if err != nil {
 		tools.Logger.Fatal("Failed to create sample user: %v", err)
 	}
-	err = security.UserRepo.SaveCookie(admin, tools.TestCookieValue, time.Now().Add(tools.CookieExpirationTime))
+	err = security.UserRepo.SaveCookie(admin, tools.TestCookieValue, tools.Clock.Now().Add(tools.CookieExpirationTime))
 	if err != nil {
 		tools.Logger.Error("failed to save cookie: %v", err)
 	} else {
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"ocelot/backend/security"
 	"ocelot/backend/tools"
-	"time"
 )
 
 func loginHandler(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +33,7 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	http.SetCookie(w, cookie)
 
-	err = security.UserRepo.SaveCookie(creds.Username, cookie.Value, time.Now().UTC().Add(tools.CookieExpirationTime))
+	err = security.UserRepo.SaveCookie(creds.Username, cookie.Value, tools.Clock.Now().UTC().Add(tools.CookieExpirationTime))
 	if err != nil {
 		http.Error(w, "saving cookie failed", http.StatusInternalServerError)
 		return
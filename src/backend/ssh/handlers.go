@@ -4,65 +4,82 @@ import (
 	"github.com/ocelot-cloud/shared/utils"
 	"github.com/ocelot-cloud/shared/validation"
 	"net/http"
+	"ocelot/backend/repositories"
 	"ocelot/backend/tools"
 )
 
-func SaveSshSettingsHandler(w http.ResponseWriter, r *http.Request) {
-	remoteRepo, err := validation.ReadBody[tools.RemoteBackupRepository](w, r)
+func GetKnownHostsHandler(w http.ResponseWriter, r *http.Request) {
+	repo, err := validation.ReadBody[tools.BackupRepository](w, r)
 	if err != nil {
 		return
 	}
 
-	err = tools.TryLockAndRespondForError(w, "save remote backup repository settings")
+	knownHost, err := SshClient.GetKnownHosts(repo.Host, repo.SshPort)
 	if err != nil {
+		Logger.Info("Failed to get known hosts: %v", err)
+		http.Error(w, "Failed to get known hosts", http.StatusBadRequest)
 		return
 	}
-	defer tools.AppOperationMutex.Unlock()
+	utils.SendJsonResponse(w, tools.KnownHostsString{Value: knownHost})
+}
 
-	err = SetRemoteBackupRepository(*remoteRepo)
+func TestSshAccessHandler(w http.ResponseWriter, r *http.Request) {
+	repo, err := validation.ReadBody[tools.BackupRepository](w, r)
 	if err != nil {
-		Logger.Error("Failed to save ssh settings: %v", err)
-		http.Error(w, "Failed to save ssh settings", http.StatusInternalServerError)
 		return
 	}
-}
+	if !keepStoredSecretsAndRespondForError(w, repo) {
+		return
+	}
 
-func ReadSshSettingsHandler(w http.ResponseWriter, r *http.Request) {
-	remoteRepo, err := GetRemoteBackupRepository()
+	err = SshClient.TestWhetherSshAccessWorks(*repo)
 	if err != nil {
-		Logger.Error("Failed to read ssh settings: %v", err)
-		http.Error(w, "Failed to read ssh settings", http.StatusInternalServerError)
+		Logger.Info("SSH access failed: %v", err)
+		http.Error(w, "SSH access failed", http.StatusBadRequest)
 		return
 	}
-	utils.SendJsonResponse(w, remoteRepo)
+	w.WriteHeader(http.StatusOK)
 }
 
-func GetKnownHostsHandler(w http.ResponseWriter, r *http.Request) {
-	repo, err := validation.ReadBody[tools.RemoteBackupRepository](w, r)
+func TestS3AccessHandler(w http.ResponseWriter, r *http.Request) {
+	repo, err := validation.ReadBody[tools.BackupRepository](w, r)
 	if err != nil {
 		return
 	}
+	if !keepStoredSecretsAndRespondForError(w, repo) {
+		return
+	}
 
-	knownHost, err := SshClient.GetKnownHosts(repo.Host, repo.SshPort)
+	err = SshClient.TestWhetherS3AccessWorks(*repo)
 	if err != nil {
-		Logger.Info("Failed to get known hosts: %v", err)
-		http.Error(w, "Failed to get known hosts", http.StatusBadRequest)
+		Logger.Info("S3 access failed: %v", err)
+		http.Error(w, "S3 access failed", http.StatusBadRequest)
 		return
 	}
-	utils.SendJsonResponse(w, tools.KnownHostsString{Value: knownHost})
+	w.WriteHeader(http.StatusOK)
 }
 
-func TestSshAccessHandler(w http.ResponseWriter, r *http.Request) {
-	repo, err := validation.ReadBody[tools.RemoteBackupRepository](w, r)
+// Existing repositories are tested with their stored secrets unless changed ones are submitted.
+func keepStoredSecretsAndRespondForError(w http.ResponseWriter, repo *tools.BackupRepository) bool {
+	if repo.Id == 0 {
+		return true
+	}
+	storedRepo, err := repositories.BackupRepositoryRepo.GetRepository(repo.Id)
 	if err != nil {
-		return
+		Logger.Info("Failed to get backup repository: %v", err)
+		http.Error(w, "Backup repository not found", http.StatusNotFound)
+		return false
 	}
+	repositories.KeepStoredSecrets(repo, *storedRepo)
+	return true
+}
 
-	err = SshClient.TestWhetherSshAccessWorks(*repo)
+func GetSshPublicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	publicKey, err := GetSshPublicKey()
 	if err != nil {
-		Logger.Info("SSH access failed: %v", err)
-		http.Error(w, "SSH access failed", http.StatusBadRequest)
+		Logger.Error("Failed to get ssh public key: %v", err)
+		http.Error(w, "Failed to get ssh public key", http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	utils.SendJsonResponse(w, tools.SshPublicKeyString{Value: publicKey})
 }
@@ -2,6 +2,7 @@ package ssh
 
 import (
 	"github.com/ocelot-cloud/task-runner"
+	"ocelot/backend/repositories"
 	"ocelot/backend/security"
 	"ocelot/backend/tools"
 	"time"
@@ -9,24 +10,45 @@ import (
 
 func InitializeSshModule() {
 	routes := []security.Route{
-		{Path: tools.SettingsSshSavePath, HandlerFunc: SaveSshSettingsHandler, AccessLevel: security.Admin},
-		{Path: tools.SettingsSshReadPath, HandlerFunc: ReadSshSettingsHandler, AccessLevel: security.Admin},
 		{Path: tools.SettingsSshTestAccessPath, HandlerFunc: TestSshAccessHandler, AccessLevel: security.Admin},
 		{Path: tools.SettingsSshKnownHostsPath, HandlerFunc: GetKnownHostsHandler, AccessLevel: security.Admin},
+		{Path: tools.SettingsSshTestS3AccessPath, HandlerFunc: TestS3AccessHandler, AccessLevel: security.Admin},
+		{Path: tools.SettingsSshPublicKeyPath, HandlerFunc: GetSshPublicKeyHandler, AccessLevel: security.Admin},
 	}
 	security.RegisterRoutes(routes)
 }
 
-func GetSampleRemoteRepo() tools.RemoteBackupRepository {
-	return tools.RemoteBackupRepository{
-		IsEnabled:          true,
-		Host:               "localhost",
-		SshPort:            "2222",
-		SshUser:            "sshadmin",
-		SshPassword:        "ssh-password",
-		SshKnownHosts:      "sample-value",
-		EncryptionPassword: "restic-password",
-	}
+func GetSampleRemoteRepo() tools.BackupRepository {
+	repository := repositories.GetDefaultRemoteRepository("remote", tools.BackupRepositoryTypeSftp)
+	repository.Host = "localhost"
+	repository.SshPort = "2222"
+	repository.SshUser = "sshadmin"
+	repository.SshPassword = "ssh-password"
+	repository.SshKnownHosts = "sample-value"
+	repository.EncryptionPassword = "restic-password"
+	return repository
+}
+
+func GetSampleS3RemoteRepo() tools.BackupRepository {
+	repository := repositories.GetDefaultRemoteRepository("remote-s3", tools.BackupRepositoryTypeS3)
+	repository.S3Endpoint = "http://localhost:9000"
+	repository.S3Bucket = "backups"
+	repository.S3Prefix = "ocelot"
+	repository.S3Region = "us-east-1"
+	repository.S3AccessKey = "minioadmin"
+	repository.S3SecretKey = "minio-password"
+	repository.EncryptionPassword = "restic-password"
+	return repository
+}
+
+func ShutDownS3TestContainer() {
+	tr.ExecuteInDir(tools.DockerDir, "docker compose -f docker-compose.dummy-s3.yml down")
+}
+
+func StartS3TestContainer() {
+	tr.ExecuteInDir(tools.DockerDir, "docker compose -f docker-compose.dummy-s3.yml down || true")
+	tr.ExecuteInDir(tools.DockerDir, "docker compose -f docker-compose.dummy-s3.yml up -d")
+	time.Sleep(3 * time.Second)
 }
 
 func ShutDownSshTestContainer() {
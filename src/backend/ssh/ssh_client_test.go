@@ -3,10 +3,12 @@
 package ssh
 
 import (
+	"fmt"
 	"github.com/ocelot-cloud/shared/assert"
+	"github.com/ocelot-cloud/task-runner"
 	"ocelot/backend/apps/common"
+	"ocelot/backend/settings"
 	"ocelot/backend/tools"
-	"reflect"
 	"testing"
 	"time"
 )
@@ -25,41 +27,55 @@ func TestKnownHostsRetrieval(t *testing.T) {
 	assert.Nil(t, SshClient.TestWhetherSshAccessWorks(remoteRepo))
 }
 
-func TestRemoteBackupRepoManagement(t *testing.T) {
+func TestSshKeyAccess(t *testing.T) {
 	SshClient = &SshClientReal{}
 	common.InitializeDatabase(false, false)
 	defer common.WipeWholeDatabase()
-	sampleRemoteRepo := GetSampleRemoteRepo()
+	StartSshTestContainer()
+	defer ShutDownSshTestContainer()
 
-	enabled, err := IsRemoteBackupEnabled()
-	assert.Nil(t, err)
-	assert.False(t, enabled)
-	emptyRepo, err := GetRemoteBackupRepository()
-	assert.Nil(t, err)
-	assert.True(t, reflect.DeepEqual(emptyRepo, getEmptyRemoteRepoConfigs()))
-	enabled, err = IsRemoteBackupEnabled()
+	remoteRepo := GetSampleRemoteRepo()
+	remoteRepo.SshAuthMethod = tools.SshAuthMethodKey
+	remoteRepo.SshPassword = ""
+	knownHosts, err := SshClient.GetKnownHosts(remoteRepo.Host, remoteRepo.SshPort)
 	assert.Nil(t, err)
-	assert.False(t, enabled)
+	remoteRepo.SshKnownHosts = knownHosts
+	assert.NotNil(t, SshClient.TestWhetherSshAccessWorks(remoteRepo))
 
-	assert.Nil(t, SetRemoteBackupRepository(sampleRemoteRepo))
-	enabled, err = IsRemoteBackupEnabled()
+	publicKey, err := GetSshPublicKey()
 	assert.Nil(t, err)
-	assert.True(t, enabled)
+	tr.ExecuteInDir(tools.DockerDir, fmt.Sprintf(`docker exec remote_backup_server sh -c "echo '%s' >> /config/.ssh/authorized_keys"`, publicKey))
+	assert.Nil(t, SshClient.TestWhetherSshAccessWorks(remoteRepo))
+}
 
-	resultRepo, err := GetRemoteBackupRepository()
-	assert.Nil(t, err)
-	assert.True(t, reflect.DeepEqual(sampleRemoteRepo, *resultRepo))
+func TestSshPrivateKeyIsOnlyCreatedOnce(t *testing.T) {
+	common.InitializeDatabase(false, false)
+	defer common.WipeWholeDatabase()
 
-	resultRepo.IsEnabled = false
-	assert.Nil(t, SetRemoteBackupRepository(*resultRepo))
-	enabled, err = IsRemoteBackupEnabled()
+	privateKey, err := LoadOrCreateSshPrivateKey()
 	assert.Nil(t, err)
-	assert.False(t, enabled)
+	loadedPrivateKey, err := LoadOrCreateSshPrivateKey()
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey, loadedPrivateKey)
 
-	resultRepo, err = GetRemoteBackupRepository()
+	// a corrupted key must not be replaced silently, since the admin installed its public key on the remote hosts
+	assert.Nil(t, settings.ConfigsRepo.SetConfigField(sshPrivateKeyField, "invalid"))
+	_, err = LoadOrCreateSshPrivateKey()
+	assert.NotNil(t, err)
+	storedValue, err := settings.ConfigsRepo.GetValue(sshPrivateKeyField)
 	assert.Nil(t, err)
-	sampleRemoteRepo.IsEnabled = false
-	assert.True(t, reflect.DeepEqual(sampleRemoteRepo, *resultRepo))
+	assert.Equal(t, "invalid", storedValue)
+}
+
+func TestS3AccessCheck(t *testing.T) {
+	SshClient = &SshClientReal{}
+	StartS3TestContainer()
+	defer ShutDownS3TestContainer()
+
+	remoteRepo := GetSampleS3RemoteRepo()
+	assert.Nil(t, SshClient.TestWhetherS3AccessWorks(remoteRepo))
+	remoteRepo.S3SecretKey = "wrong-password"
+	assert.NotNil(t, SshClient.TestWhetherS3AccessWorks(remoteRepo))
 }
 
 func TestSshClientMock_GetKnownHosts(t *testing.T) {
@@ -75,11 +91,11 @@ func TestSshClientMock_GetKnownHosts(t *testing.T) {
 
 func TestSshClientMock_TestWhetherSshAccessWorks(t *testing.T) {
 	SshClient = &SshClientMock{}
-	err := SshClient.TestWhetherSshAccessWorks(tools.RemoteBackupRepository{})
+	err := SshClient.TestWhetherSshAccessWorks(tools.BackupRepository{})
 	assert.NotNil(t, err)
 	assert.Equal(t, "ssh client mock says incorrect repo info", err.Error())
 
-	err = SshClient.TestWhetherSshAccessWorks(tools.RemoteBackupRepository{
+	err = SshClient.TestWhetherSshAccessWorks(tools.BackupRepository{
 		IsEnabled:          false,
 		Host:               "localhost",
 		SshPort:            "2222",
@@ -90,3 +106,19 @@ func TestSshClientMock_TestWhetherSshAccessWorks(t *testing.T) {
 	})
 	assert.Nil(t, err)
 }
+
+func TestSshClientMock_TestWhetherSshAccessWorksWithKey(t *testing.T) {
+	SshClient = &SshClientMock{}
+	repo := GetSampleRemoteRepo()
+	repo.SshKnownHosts = sampleKnownHost
+	repo.SshPassword = ""
+	assert.NotNil(t, SshClient.TestWhetherSshAccessWorks(repo))
+	repo.SshAuthMethod = tools.SshAuthMethodKey
+	assert.Nil(t, SshClient.TestWhetherSshAccessWorks(repo))
+}
+
+func TestSshClientMock_TestWhetherS3AccessWorks(t *testing.T) {
+	SshClient = &SshClientMock{}
+	assert.NotNil(t, SshClient.TestWhetherS3AccessWorks(tools.BackupRepository{}))
+	assert.Nil(t, SshClient.TestWhetherS3AccessWorks(GetSampleS3RemoteRepo()))
+}
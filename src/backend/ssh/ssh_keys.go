@@ -0,0 +1,83 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	gossh "golang.org/x/crypto/ssh"
+	"ocelot/backend/settings"
+	"strings"
+	"sync"
+)
+
+const (
+	sshPrivateKeyField settings.ConfigFieldKey = "SSH_PRIVATE_KEY"
+	sshKeyComment                              = "ocelot-cloud"
+)
+
+// Prevents that concurrent first calls generate different keys, of which only the last one would be stored.
+var sshPrivateKeyCreationMutex sync.Mutex
+
+// LoadOrCreateSshPrivateKey returns the PEM encoded ed25519 key ocelot-cloud uses to authenticate at remote backup hosts.
+// A new key is only generated if none is stored yet, since replacing it would invalidate the public key the admin installed
+// on the remote hosts.
+func LoadOrCreateSshPrivateKey() (string, error) {
+	sshPrivateKeyCreationMutex.Lock()
+	defer sshPrivateKeyCreationMutex.Unlock()
+
+	privateKeyPem, err := settings.ConfigsRepo.GetValue(sshPrivateKeyField)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		Logger.Error("failed to load ssh private key: %v", err)
+		return "", errors.New("failed to load ssh private key")
+	}
+	if privateKeyPem != "" {
+		if _, err = gossh.ParsePrivateKey([]byte(privateKeyPem)); err != nil {
+			Logger.Error("stored ssh private key could not be parsed: %v", err)
+			return "", errors.New("stored ssh private key could not be parsed")
+		}
+		return privateKeyPem, nil
+	}
+
+	privateKeyPem, err = generateSshPrivateKey()
+	if err != nil {
+		return "", err
+	}
+	err = settings.ConfigsRepo.SetConfigField(sshPrivateKeyField, privateKeyPem)
+	if err != nil {
+		return "", err
+	}
+	return privateKeyPem, nil
+}
+
+func GetSshPublicKey() (string, error) {
+	privateKeyPem, err := LoadOrCreateSshPrivateKey()
+	if err != nil {
+		return "", err
+	}
+	return derivePublicKey(privateKeyPem)
+}
+
+func generateSshPrivateKey() (string, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	pemBlock, err := gossh.MarshalPrivateKey(privateKey, sshKeyComment)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(pemBlock)), nil
+}
+
+// derivePublicKey returns the public key in the authorized_keys format, so it can be copied to the remote host as is.
+func derivePublicKey(privateKeyPem string) (string, error) {
+	signer, err := gossh.ParsePrivateKey([]byte(privateKeyPem))
+	if err != nil {
+		Logger.Error("failed to parse ssh private key: %v", err)
+		return "", errors.New("failed to parse ssh private key")
+	}
+	authorizedKey := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(signer.PublicKey())))
+	return authorizedKey + " " + sshKeyComment, nil
+}
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AppsUnderMaintenance tracks apps which are temporarily unavailable, because they are stopped for a backup, restore or
+// update or because they are still starting, so that requests to them are answered with a maintenance page.
+var AppsUnderMaintenance = ProvideAppMaintenanceTracker()
+
+type AppMaintenanceReason string
+
+const (
+	AppMaintenanceReasonBackup   AppMaintenanceReason = "backup"
+	AppMaintenanceReasonRestore  AppMaintenanceReason = "restore"
+	AppMaintenanceReasonUpdate   AppMaintenanceReason = "update"
+	AppMaintenanceReasonStarting AppMaintenanceReason = "starting"
+)
+
+// After its containers are started, an app usually needs some time until it accepts connections.
+const appStartupGracePeriod = 3 * time.Minute
+
+// Browsers and clients are asked to retry after this many seconds.
+const maintenanceRetryAfterSeconds = 30
+
+// AppMaintenanceTracker is keyed by app name, since this is what requests to an app are routed by.
+type AppMaintenanceTracker struct {
+	mu sync.Mutex
+	// operations can be nested, e.g. an update creates a backup, so the reasons of all ongoing operations are kept
+	reasons   map[string][]AppMaintenanceReason
+	startedAt map[string]time.Time
+}
+
+func ProvideAppMaintenanceTracker() *AppMaintenanceTracker {
+	return &AppMaintenanceTracker{reasons: map[string][]AppMaintenanceReason{}, startedAt: map[string]time.Time{}}
+}
+
+func (t *AppMaintenanceTracker) Begin(appName string, reason AppMaintenanceReason) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reasons[appName] = append(t.reasons[appName], reason)
+}
+
+func (t *AppMaintenanceTracker) End(appName string, reason AppMaintenanceReason) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reasons := t.reasons[appName]
+	for i := len(reasons) - 1; i >= 0; i-- {
+		if reasons[i] == reason {
+			reasons = append(reasons[:i], reasons[i+1:]...)
+			break
+		}
+	}
+	if len(reasons) == 0 {
+		delete(t.reasons, appName)
+	} else {
+		t.reasons[appName] = reasons
+	}
+}
+
+// MarkStarted records that the containers of the app were started, so that connection errors shortly afterwards are
+// treated as the app still starting.
+func (t *AppMaintenanceTracker) MarkStarted(appName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.startedAt[appName] = Clock.Now()
+}
+
+// GetReason returns the reason of the most recently begun maintenance operation of the app.
+func (t *AppMaintenanceTracker) GetReason(appName string) (AppMaintenanceReason, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reasons := t.reasons[appName]
+	if len(reasons) == 0 {
+		return "", false
+	}
+	return reasons[len(reasons)-1], true
+}
+
+func (t *AppMaintenanceTracker) IsStartingUp(appName string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	startedAt, ok := t.startedAt[appName]
+	return ok && Clock.Now().Sub(startedAt) < appStartupGracePeriod
+}
+
+var maintenancePageTemplate = template.Must(template.New("maintenance").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta http-equiv="refresh" content="{{.RetryAfterSeconds}}">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.AppName}} is under maintenance - Ocelot Cloud</title>
+  <style>
+    body { font-family: sans-serif; background: #f5f5f5; color: #333; display: flex; align-items: center; justify-content: center; height: 100vh; margin: 0; }
+    main { background: #fff; border-radius: 8px; box-shadow: 0 2px 8px rgba(0, 0, 0, 0.1); padding: 2rem 3rem; text-align: center; max-width: 32rem; }
+    h1 { color: #e67e22; font-size: 1.5rem; }
+    footer { margin-top: 1.5rem; font-size: 0.8rem; color: #999; }
+  </style>
+</head>
+<body>
+<main>
+  <h1>{{.AppName}} is under maintenance</h1>
+  <p>{{.Message}}</p>
+  <p>This page reloads automatically in {{.RetryAfterSeconds}} seconds.</p>
+  <footer>Ocelot Cloud</footer>
+</main>
+</body>
+</html>
+`))
+
+var maintenanceMessages = map[AppMaintenanceReason]string{
+	AppMaintenanceReasonBackup:   "A backup of the app is being created.",
+	AppMaintenanceReasonRestore:  "A backup of the app is being restored.",
+	AppMaintenanceReasonUpdate:   "The app is being updated to a new version.",
+	AppMaintenanceReasonStarting: "The app is starting.",
+}
+
+// WriteMaintenancePage answers with 503 and a Retry-After header, so that clients know that the app is only temporarily unavailable.
+func WriteMaintenancePage(w http.ResponseWriter, appName string, reason AppMaintenanceReason) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Retry-After", strconv.Itoa(maintenanceRetryAfterSeconds))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	err := maintenancePageTemplate.Execute(w, struct {
+		AppName           string
+		Message           string
+		RetryAfterSeconds int
+	}{appName, maintenanceMessages[reason], maintenanceRetryAfterSeconds})
+	if err != nil {
+		Logger.Error("Failed to write maintenance page: %v", err)
+	}
+}
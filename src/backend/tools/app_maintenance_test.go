@@ -0,0 +1,54 @@
+//go:build fast
+
+package tools
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNestedAppMaintenance(t *testing.T) {
+	tracker := ProvideAppMaintenanceTracker()
+	_, isUnderMaintenance := tracker.GetReason("gitea")
+	assert.False(t, isUnderMaintenance)
+
+	tracker.Begin("gitea", AppMaintenanceReasonUpdate)
+	tracker.Begin("gitea", AppMaintenanceReasonBackup)
+	reason, isUnderMaintenance := tracker.GetReason("gitea")
+	assert.True(t, isUnderMaintenance)
+	assert.Equal(t, AppMaintenanceReasonBackup, reason)
+	_, isUnderMaintenance = tracker.GetReason("nextcloud")
+	assert.False(t, isUnderMaintenance)
+
+	tracker.End("gitea", AppMaintenanceReasonBackup)
+	reason, isUnderMaintenance = tracker.GetReason("gitea")
+	assert.True(t, isUnderMaintenance)
+	assert.Equal(t, AppMaintenanceReasonUpdate, reason)
+
+	tracker.End("gitea", AppMaintenanceReasonUpdate)
+	_, isUnderMaintenance = tracker.GetReason("gitea")
+	assert.False(t, isUnderMaintenance)
+}
+
+func TestAppIsStartingUpAfterBeingStarted(t *testing.T) {
+	tracker := ProvideAppMaintenanceTracker()
+	assert.False(t, tracker.IsStartingUp("gitea"))
+	tracker.MarkStarted("gitea")
+	assert.True(t, tracker.IsStartingUp("gitea"))
+	tracker.startedAt["gitea"] = Clock.Now().Add(-appStartupGracePeriod)
+	assert.False(t, tracker.IsStartingUp("gitea"))
+}
+
+func TestWriteMaintenancePage(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	WriteMaintenancePage(recorder, "gitea", AppMaintenanceReasonBackup)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+	body := recorder.Body.String()
+	assert.True(t, strings.Contains(body, "gitea is under maintenance"))
+	assert.True(t, strings.Contains(body, "A backup of the app is being created."))
+}
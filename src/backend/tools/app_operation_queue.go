@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// AllAppsScope is the scope of operations which affect all apps, e.g. operations on the database app or on backup repositories.
+// App ids start at 1, so it can not collide with the scope of a single app.
+const AllAppsScope = 0
+
+var AppOperationQueue = ProvideAppOperationQueue()
+
+// ErrOperationQueueClosed is returned for operations which can not be started anymore, since the backend is shutting down.
+var ErrOperationQueueClosed = errors.New("backend is shutting down")
+
+// OperationQueue serializes conflicting operations in FIFO order. Operations on different apps run concurrently, operations on
+// the same app are executed one after another and operations with AllAppsScope wait for all earlier operations and block all
+// later ones.
+type OperationQueue struct {
+	mu      sync.Mutex
+	nextId  int
+	tickets []*OperationTicket
+	// closed by Close, so that tickets waiting for their turn give up
+	closed chan struct{}
+	// closed and replaced whenever an operation is released, so that WaitForRunningOperations can wait for it
+	released chan struct{}
+}
+
+type OperationTicket struct {
+	Id        int
+	Scope     int
+	Operation string
+	isRunning bool
+	started   chan struct{}
+	closed    <-chan struct{}
+}
+
+func ProvideAppOperationQueue() *OperationQueue {
+	return &OperationQueue{nextId: 1, closed: make(chan struct{}), released: make(chan struct{})}
+}
+
+func (t *OperationTicket) conflictsWith(other *OperationTicket) bool {
+	return t.Scope == AllAppsScope || other.Scope == AllAppsScope || t.Scope == other.Scope
+}
+
+// Enqueue appends an operation to the queue without waiting for it to be started. Use Wait to block until it is its turn.
+// It fails with ErrOperationQueueClosed if the queue is closed.
+func (q *OperationQueue) Enqueue(scope int, operation string) (*OperationTicket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.isClosed() {
+		return nil, ErrOperationQueueClosed
+	}
+	ticket := &OperationTicket{Id: q.nextId, Scope: scope, Operation: operation, started: make(chan struct{}), closed: q.closed}
+	q.nextId++
+	q.tickets = append(q.tickets, ticket)
+	Logger.Debug("enqueued operation '%s' with scope %d at position %d", operation, scope, q.positionOf(ticket))
+	q.startRunnableTickets()
+	return ticket, nil
+}
+
+// Wait blocks until it is the ticket's turn. It fails with ErrOperationQueueClosed if the queue is closed before, in which
+// case the ticket must be released nevertheless.
+func (t *OperationTicket) Wait() error {
+	select {
+	case <-t.started:
+		return nil
+	case <-t.closed:
+		select {
+		case <-t.started:
+			return nil
+		default:
+			return ErrOperationQueueClosed
+		}
+	}
+}
+
+// Acquire enqueues an operation and blocks until it is its turn. The ticket must be released when the operation is done.
+// It fails with ErrOperationQueueClosed if the queue is closed before the operation could be started.
+func (q *OperationQueue) Acquire(scope int, operation string) (*OperationTicket, error) {
+	ticket, err := q.Enqueue(scope, operation)
+	if err != nil {
+		return nil, err
+	}
+	err = ticket.Wait()
+	if err != nil {
+		q.Release(ticket)
+		return nil, err
+	}
+	return ticket, nil
+}
+
+func (q *OperationQueue) Release(ticket *OperationTicket) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, queuedTicket := range q.tickets {
+		if queuedTicket == ticket {
+			q.tickets = append(q.tickets[:i], q.tickets[i+1:]...)
+			break
+		}
+	}
+	Logger.Trace("released operation '%s' with scope %d", ticket.Operation, ticket.Scope)
+	close(q.released)
+	q.released = make(chan struct{})
+	q.startRunnableTickets()
+}
+
+// A ticket can be started when none of the tickets before it in the queue conflicts with it.
+func (q *OperationQueue) startRunnableTickets() {
+	if q.isClosed() {
+		return
+	}
+	for _, ticket := range q.tickets {
+		if !ticket.isRunning && q.positionOf(ticket) == 0 {
+			ticket.isRunning = true
+			close(ticket.started)
+		}
+	}
+}
+
+// Position returns the number of conflicting operations which are queued before the given one, 0 meaning that it is running.
+func (q *OperationQueue) Position(ticket *OperationTicket) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.positionOf(ticket)
+}
+
+func (q *OperationQueue) positionOf(ticket *OperationTicket) int {
+	position := 0
+	for _, queuedTicket := range q.tickets {
+		if queuedTicket == ticket {
+			return position
+		}
+		if queuedTicket.conflictsWith(ticket) {
+			position++
+		}
+	}
+	return 0
+}
+
+func (q *OperationQueue) ListOperations() []QueuedOperation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var operations []QueuedOperation
+	for _, ticket := range q.tickets {
+		operations = append(operations, QueuedOperation{
+			Id:            ticket.Id,
+			Scope:         ticket.Scope,
+			Operation:     ticket.Operation,
+			IsRunning:     ticket.isRunning,
+			QueuePosition: q.positionOf(ticket),
+		})
+	}
+	return operations
+}
+
+// Close prevents queued and new operations from being started, e.g. when the backend is shutting down. Running operations
+// are not affected, so they can be waited for with WaitForRunningOperations.
+func (q *OperationQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.isClosed() {
+		close(q.closed)
+	}
+}
+
+func (q *OperationQueue) isClosed() bool {
+	select {
+	case <-q.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForRunningOperations returns true as soon as no operation is running anymore, or false if the timeout expires first.
+func (q *OperationQueue) WaitForRunningOperations(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		q.mu.Lock()
+		runningOperationsCount := q.countRunningOperations()
+		released := q.released
+		q.mu.Unlock()
+		if runningOperationsCount == 0 {
+			return true
+		}
+		select {
+		case <-released:
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// must be called while holding the mutex
+func (q *OperationQueue) countRunningOperations() int {
+	count := 0
+	for _, ticket := range q.tickets {
+		if ticket.isRunning {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,137 @@
+//go:build fast
+
+package tools
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"testing"
+	"time"
+)
+
+func isStarted(ticket *OperationTicket) bool {
+	select {
+	case <-ticket.started:
+		return true
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+func enqueue(t *testing.T, queue *OperationQueue, scope int, operation string) *OperationTicket {
+	ticket, err := queue.Enqueue(scope, operation)
+	assert.Nil(t, err)
+	return ticket
+}
+
+func acquire(t *testing.T, queue *OperationQueue, scope int, operation string) *OperationTicket {
+	ticket, err := queue.Acquire(scope, operation)
+	assert.Nil(t, err)
+	return ticket
+}
+
+func TestOperationsOnDifferentAppsRunConcurrently(t *testing.T) {
+	queue := ProvideAppOperationQueue()
+	ticket1 := enqueue(t, queue, 1, "start app")
+	ticket2 := enqueue(t, queue, 2, "stop app")
+	assert.True(t, isStarted(ticket1))
+	assert.True(t, isStarted(ticket2))
+	assert.Equal(t, 0, queue.Position(ticket2))
+	queue.Release(ticket1)
+	queue.Release(ticket2)
+	assert.Equal(t, 0, len(queue.ListOperations()))
+}
+
+func TestOperationsOnSameAppAreSerialized(t *testing.T) {
+	queue := ProvideAppOperationQueue()
+	ticket1 := enqueue(t, queue, 1, "create backup")
+	ticket2 := enqueue(t, queue, 1, "stop app")
+	ticket3 := enqueue(t, queue, 1, "start app")
+	assert.True(t, isStarted(ticket1))
+	assert.False(t, isStarted(ticket2))
+	assert.Equal(t, 1, queue.Position(ticket2))
+	assert.Equal(t, 2, queue.Position(ticket3))
+
+	queue.Release(ticket1)
+	assert.True(t, isStarted(ticket2))
+	assert.False(t, isStarted(ticket3))
+	assert.Equal(t, 1, queue.Position(ticket3))
+
+	queue.Release(ticket2)
+	assert.True(t, isStarted(ticket3))
+	queue.Release(ticket3)
+}
+
+func TestAllAppsScopeBlocksEverything(t *testing.T) {
+	queue := ProvideAppOperationQueue()
+	appTicket := enqueue(t, queue, 1, "create backup")
+	globalTicket := enqueue(t, queue, AllAppsScope, "maintenance cycle")
+	laterAppTicket := enqueue(t, queue, 2, "start app")
+	assert.True(t, isStarted(appTicket))
+	assert.False(t, isStarted(globalTicket))
+	assert.False(t, isStarted(laterAppTicket))
+	assert.Equal(t, 1, queue.Position(laterAppTicket))
+
+	operations := queue.ListOperations()
+	assert.Equal(t, 3, len(operations))
+	assert.True(t, operations[0].IsRunning)
+	assert.Equal(t, "maintenance cycle", operations[1].Operation)
+	assert.Equal(t, 1, operations[1].QueuePosition)
+
+	queue.Release(appTicket)
+	assert.True(t, isStarted(globalTicket))
+	assert.False(t, isStarted(laterAppTicket))
+	queue.Release(globalTicket)
+	assert.True(t, isStarted(laterAppTicket))
+	queue.Release(laterAppTicket)
+}
+
+func TestAcquireWaitsForItsTurn(t *testing.T) {
+	queue := ProvideAppOperationQueue()
+	ticket := acquire(t, queue, 1, "operation1")
+	acquired := make(chan *OperationTicket)
+	go func() {
+		secondTicket, _ := queue.Acquire(1, "operation2")
+		acquired <- secondTicket
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second operation must wait for the first one")
+	case <-time.After(50 * time.Millisecond):
+	}
+	queue.Release(ticket)
+	queue.Release(<-acquired)
+}
+
+func TestClosedQueueDoesNotStartFurtherOperations(t *testing.T) {
+	queue := ProvideAppOperationQueue()
+	runningTicket := enqueue(t, queue, 1, "restore backup")
+	queuedTicket := enqueue(t, queue, 1, "start app")
+	assert.True(t, isStarted(runningTicket))
+
+	acquireResult := make(chan error)
+	go func() {
+		_, err := queue.Acquire(1, "stop app")
+		acquireResult <- err
+	}()
+	queue.Close()
+	select {
+	case err := <-acquireResult:
+		assert.Equal(t, ErrOperationQueueClosed, err)
+	case <-time.After(time.Second):
+		t.Fatal("acquiring an operation must fail when the queue is closed")
+	}
+	_, err := queue.Enqueue(2, "create backup")
+	assert.Equal(t, ErrOperationQueueClosed, err)
+	assert.Equal(t, ErrOperationQueueClosed, queuedTicket.Wait())
+	assert.Nil(t, runningTicket.Wait())
+	assert.False(t, queue.WaitForRunningOperations(50*time.Millisecond))
+
+	waitResult := make(chan bool)
+	go func() {
+		waitResult <- queue.WaitForRunningOperations(time.Second)
+	}()
+	queue.Release(runningTicket)
+	assert.True(t, <-waitResult)
+	assert.False(t, isStarted(queuedTicket))
+}
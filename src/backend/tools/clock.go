@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for scheduled operations like the maintenance agent, cookie expiry and certificate retries.
+// In testing profiles it is mocked, so that tests can fast-forward time instead of waiting for it.
+var Clock = ProvideClock(Config.UseMockedClock)
+
+type ClockInterface interface {
+	Now() time.Time
+	After(duration time.Duration) <-chan time.Time
+	Sleep(duration time.Duration)
+}
+
+func ProvideClock(useMockedClock bool) ClockInterface {
+	if useMockedClock {
+		return NewMockClock(time.Now().UTC())
+	}
+	return &RealClock{}
+}
+
+type RealClock struct{}
+
+func (c *RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *RealClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+func (c *RealClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+// MockClock follows the real time, but can additionally be fast-forwarded with Advance. Waiters are released as soon as the
+// clock passes their deadline, either because real time passed or because the clock was advanced, so operations waiting on
+// it, like retries of failed requests, do not hang when tests do not advance it.
+type MockClock struct {
+	mu sync.Mutex
+	// the difference between the mocked and the real time, which grows with every advance
+	offset  time.Duration
+	waiters []mockClockWaiter
+}
+
+type mockClockWaiter struct {
+	deadline time.Time
+	channel  chan time.Time
+}
+
+func NewMockClock(now time.Time) *MockClock {
+	return &MockClock{offset: time.Until(now)}
+}
+
+func (c *MockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now()
+}
+
+func (c *MockClock) now() time.Time {
+	return time.Now().Add(c.offset).UTC()
+}
+
+func (c *MockClock) After(duration time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	channel := make(chan time.Time, 1)
+	if duration <= 0 {
+		channel <- c.now()
+	} else {
+		c.waiters = append(c.waiters, mockClockWaiter{deadline: c.now().Add(duration), channel: channel})
+		time.AfterFunc(duration, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.releaseDueWaiters()
+		})
+	}
+	return channel
+}
+
+func (c *MockClock) Sleep(duration time.Duration) {
+	<-c.After(duration)
+}
+
+// Advance moves the clock forward, releases all waiters whose deadline has passed and returns the new time.
+func (c *MockClock) Advance(duration time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset += duration
+	c.releaseDueWaiters()
+	return c.now()
+}
+
+func (c *MockClock) releaseDueWaiters() {
+	now := c.now()
+	var pendingWaiters []mockClockWaiter
+	for _, waiter := range c.waiters {
+		if waiter.deadline.After(now) {
+			pendingWaiters = append(pendingWaiters, waiter)
+		} else {
+			waiter.channel <- now
+		}
+	}
+	c.waiters = pendingWaiters
+}
@@ -0,0 +1,86 @@
+//go:build fast
+
+package tools
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"testing"
+	"time"
+)
+
+func isReleased(channel <-chan time.Time) bool {
+	select {
+	case <-channel:
+		return true
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+func assertCloseTo(t *testing.T, expected, actual time.Time) {
+	difference := actual.Sub(expected)
+	assert.True(t, difference >= 0 && difference < time.Second)
+}
+
+func TestMockClockCanBeFastForwarded(t *testing.T) {
+	start := time.Date(2025, 3, 10, 4, 30, 0, 0, time.UTC)
+	clock := NewMockClock(start)
+	assertCloseTo(t, start, clock.Now())
+	assertCloseTo(t, start.Add(time.Hour), clock.Advance(time.Hour))
+	assertCloseTo(t, start.Add(time.Hour), clock.Now())
+}
+
+func TestMockClockFollowsRealTimeWithoutAdvance(t *testing.T) {
+	clock := NewMockClock(time.Date(2025, 3, 10, 4, 30, 0, 0, time.UTC))
+	start := clock.Now()
+	waiter := clock.After(20 * time.Millisecond)
+	select {
+	case <-waiter:
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released after the real time passed")
+	}
+	assert.True(t, clock.Now().Sub(start) >= 20*time.Millisecond)
+}
+
+func TestMockClockReleasesWaitersWhenDeadlineIsPassed(t *testing.T) {
+	clock := NewMockClock(time.Date(2025, 3, 10, 4, 30, 0, 0, time.UTC))
+	minuteWaiter := clock.After(time.Minute)
+	hourWaiter := clock.After(time.Hour)
+	assert.False(t, isReleased(minuteWaiter))
+
+	clock.Advance(30 * time.Second)
+	assert.False(t, isReleased(minuteWaiter))
+
+	clock.Advance(30 * time.Second)
+	assert.True(t, isReleased(minuteWaiter))
+	assert.False(t, isReleased(hourWaiter))
+
+	clock.Advance(2 * time.Hour)
+	assert.True(t, isReleased(hourWaiter))
+	assert.True(t, isReleased(clock.After(0)))
+}
+
+func TestMockClockSleepReturnsAfterAdvance(t *testing.T) {
+	clock := NewMockClock(time.Date(2025, 3, 10, 4, 30, 0, 0, time.UTC))
+	done := make(chan struct{})
+	go func() {
+		clock.Sleep(time.Minute)
+		close(done)
+	}()
+	// waits until the goroutine registered its waiter, since advancing earlier would not release it
+	for {
+		clock.mu.Lock()
+		waiterCount := len(clock.waiters)
+		clock.mu.Unlock()
+		if waiterCount == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sleep did not return after the clock was advanced")
+	}
+}
@@ -13,6 +13,7 @@ const (
 	OcelotAuthCookieName  = "ocelot-auth"
 	OcelotQuerySecretName = "ocelot-secret"
 	TestCookieValue       = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	ResticImageName       = "restic:local"
 
 	ApiPath       = "/api"
 	SecretPath    = ApiPath + "/secret"
@@ -20,6 +21,10 @@ const (
 	WipePath      = ApiPath + "/wipe"
 	CheckAuthPath = ApiPath + "/check-auth"
 
+	TestingPath                      = ApiPath + "/testing"
+	TestingClockAdvancePath          = TestingPath + "/clock/advance"
+	TestingMaintenanceAgentStartPath = TestingPath + "/maintenance-agent/start"
+
 	UsersPath          = ApiPath + "/users"
 	UsersLogoutPath    = UsersPath + "/logout"
 	UsersListPath      = UsersPath + "/list"
@@ -32,21 +37,31 @@ const (
 	VersionsDownloadPath = VersionsPath + "/download"
 	VersionsListPath     = VersionsPath + "/list"
 
-	AppsPath       = ApiPath + "/apps"
-	AppsSearchPath = AppsPath + "/search"
-	AppsListPath   = AppsPath + "/list"
-	AppsPrunePath  = AppsPath + "/prune"
-	AppsUpdatePath = AppsPath + "/update"
-	AppsStartPath  = AppsPath + "/start"
-	AppsStopPath   = AppsPath + "/stop"
+	AppsPath             = ApiPath + "/apps"
+	AppsSearchPath       = AppsPath + "/search"
+	AppsListPath         = AppsPath + "/list"
+	AppsPrunePath        = AppsPath + "/prune"
+	AppsUpdatePath       = AppsPath + "/update"
+	AppsStartPath        = AppsPath + "/start"
+	AppsStopPath         = AppsPath + "/stop"
+	AppsMaintenancePath  = AppsPath + "/maintenance"
+	AppsUpdatePolicyPath = AppsPath + "/update-policy"
+	AppsCheckUpdatesPath = AppsPath + "/check-updates"
 
 	BackupsPath         = ApiPath + "/backups"
 	BackupsCreatePath   = BackupsPath + "/create"
 	BackupsListPath     = BackupsPath + "/list"
 	BackupsRestorePath  = BackupsPath + "/restore"
+	BackupsClonePath    = BackupsRestorePath + "/clone"
+	BackupsExportPath   = BackupsPath + "/export"
+	BackupsImportPath   = BackupsPath + "/import"
 	BackupsDeletePath   = BackupsPath + "/delete"
 	BackupsListAppsPath = BackupsPath + "/list-apps"
 
+	BackupsFilesPath         = BackupsPath + "/files"
+	BackupsFilesListPath     = BackupsFilesPath + "/list"
+	BackupsFilesDownloadPath = BackupsFilesPath + "/download"
+
 	SettingsPath         = ApiPath + "/settings"
 	SettingsHostPath     = SettingsPath + "/host"
 	SettingsHostSavePath = SettingsHostPath + "/save"
@@ -56,15 +71,43 @@ const (
 	SettingsCertificateUploadPath   = SettingsCertificatePath + "/upload"
 	SettingsGenerateCertificatePath = SettingsCertificatePath + "/generate"
 
-	SettingsSshPath           = SettingsPath + "/ssh"
-	SettingsSshReadPath       = SettingsSshPath + "/read"
-	SettingsSshSavePath       = SettingsSshPath + "/save"
-	SettingsSshTestAccessPath = SettingsSshPath + "/test-access"
-	SettingsSshKnownHostsPath = SettingsSshPath + "/known-hosts"
+	SettingsSshPath             = SettingsPath + "/ssh"
+	SettingsSshTestAccessPath   = SettingsSshPath + "/test-access"
+	SettingsSshKnownHostsPath   = SettingsSshPath + "/known-hosts"
+	SettingsSshTestS3AccessPath = SettingsSshPath + "/test-s3-access"
+	SettingsSshPublicKeyPath    = SettingsSshPath + "/public-key"
+
+	SettingsRepositoriesPath       = SettingsPath + "/repositories"
+	SettingsRepositoriesListPath   = SettingsRepositoriesPath + "/list"
+	SettingsRepositoriesCreatePath = SettingsRepositoriesPath + "/create"
+	SettingsRepositoriesUpdatePath = SettingsRepositoriesPath + "/update"
+	SettingsRepositoriesDeletePath = SettingsRepositoriesPath + "/delete"
+
+	SettingsRepositoriesRotatePasswordPath = SettingsRepositoriesPath + "/rotate-password"
+
+	JobsPath       = ApiPath + "/jobs"
+	JobsStatusPath = JobsPath + "/status"
+	JobsListPath   = JobsPath + "/list"
+	JobsQueuePath  = JobsPath + "/queue"
+
+	RecoveryPath        = ApiPath + "/recovery"
+	RecoveryStatusPath  = RecoveryPath + "/status"
+	RecoveryStartPath   = RecoveryPath + "/start"
+	RecoverySummaryPath = RecoveryPath + "/summary"
+
+	MaintenancePath          = ApiPath + "/maintenance"
+	MaintenanceRunsPath      = MaintenancePath + "/runs"
+	MaintenanceRunsListPath  = MaintenanceRunsPath + "/list"
+	MaintenanceRunsStartPath = MaintenanceRunsPath + "/start"
 
 	SettingsMaintenancePath     = SettingsPath + "/maintenance"
 	SettingsMaintenanceSavePath = SettingsMaintenancePath + "/save"
 	SettingsMaintenanceReadPath = SettingsMaintenancePath + "/read"
+
+	SettingsNotificationsPath     = SettingsPath + "/notifications"
+	SettingsNotificationsSavePath = SettingsNotificationsPath + "/save"
+	SettingsNotificationsReadPath = SettingsNotificationsPath + "/read"
+	SettingsNotificationsTestPath = SettingsNotificationsPath + "/test"
 )
 
 var (
@@ -104,6 +147,7 @@ type GlobalConfig struct {
 	UseProductionDatabaseContainer bool
 	IsMaintenanceAgentEnabled      bool
 	UseMockedSshClient             bool
+	UseMockedClock                 bool
 	CertificateDnsChallengeClient  CertificateDnsChallengeClientType
 }
 
@@ -145,6 +189,7 @@ func getGlobalConfigBasedOnProfile(profile BackendProfile) *GlobalConfig {
 		config.UseProductionDatabaseContainer = false
 		config.IsMaintenanceAgentEnabled = false
 		config.UseMockedSshClient = true
+		config.UseMockedClock = true
 		config.CertificateDnsChallengeClient = STUB_CERTIFICATE
 		Logger = utils.ProvideLogger("DEBUG")
 	} else if profile == DOCKER_TEST {
@@ -156,6 +201,7 @@ func getGlobalConfigBasedOnProfile(profile BackendProfile) *GlobalConfig {
 		config.UseProductionDatabaseContainer = false
 		config.IsMaintenanceAgentEnabled = false
 		config.UseMockedSshClient = true
+		config.UseMockedClock = true
 		config.CertificateDnsChallengeClient = FAKE_LETSENCRYPT_CERTIFICATE
 		Logger = utils.ProvideLogger("DEBUG")
 	} else {
@@ -167,6 +213,7 @@ func getGlobalConfigBasedOnProfile(profile BackendProfile) *GlobalConfig {
 		config.UseProductionDatabaseContainer = true
 		config.IsMaintenanceAgentEnabled = true
 		config.UseMockedSshClient = false
+		config.UseMockedClock = false
 		config.CertificateDnsChallengeClient = PRODUCTION_LETSENCRYPT_CERTIFICATE
 	}
 
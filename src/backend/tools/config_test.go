@@ -24,6 +24,7 @@ func TestGetConfig(t *testing.T) {
 	assert.Equal(t, false, nativeConfig.IsMaintenanceAgentEnabled)
 	assert.Equal(t, STUB_CERTIFICATE, nativeConfig.CertificateDnsChallengeClient)
 	assert.True(t, nativeConfig.UseMockedSshClient)
+	assert.True(t, nativeConfig.UseMockedClock)
 
 	dockerTestConfig := getGlobalConfigBasedOnProfile(DOCKER_TEST)
 	assert.Equal(t, true, dockerTestConfig.IsGuiEnabled)
@@ -35,6 +36,7 @@ func TestGetConfig(t *testing.T) {
 	assert.Equal(t, false, dockerTestConfig.IsMaintenanceAgentEnabled)
 	assert.Equal(t, FAKE_LETSENCRYPT_CERTIFICATE, dockerTestConfig.CertificateDnsChallengeClient)
 	assert.True(t, dockerTestConfig.UseMockedSshClient)
+	assert.True(t, dockerTestConfig.UseMockedClock)
 
 	prodConfig := getGlobalConfigBasedOnProfile(PROD)
 	assert.Equal(t, true, prodConfig.IsGuiEnabled)
@@ -46,4 +48,5 @@ func TestGetConfig(t *testing.T) {
 	assert.Equal(t, true, prodConfig.IsMaintenanceAgentEnabled)
 	assert.Equal(t, PRODUCTION_LETSENCRYPT_CERTIFICATE, prodConfig.CertificateDnsChallengeClient)
 	assert.False(t, prodConfig.UseMockedSshClient)
+	assert.False(t, prodConfig.UseMockedClock)
 }
@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed cron expression with the five standard fields "minute hour day-of-month month day-of-week".
+// Each field supports "*", single values, ranges like "1-5", lists like "1,3" and steps like "*/6" or "0-12/3".
+type CronSchedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek []bool
+	isDayOfMonthRestricted, isDayOfWeekRestricted   bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Schedules are searched for a matching time at most this far ahead, which covers all valid combinations like "29 of February".
+const maxCronSearchYears = 8
+
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression '%s' must consist of 5 fields: minute, hour, day of month, month and day of week", expression)
+	}
+	values := make([][]bool, len(cronFields))
+	for i, field := range cronFields {
+		var err error
+		values[i], err = parseCronField(parts[i], field)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// both 0 and 7 stand for sunday
+	if values[4][7] {
+		values[4][0] = true
+	}
+
+	schedule := &CronSchedule{
+		minutes:                values[0],
+		hours:                  values[1],
+		daysOfMonth:            values[2],
+		months:                 values[3],
+		daysOfWeek:             values[4][:7],
+		isDayOfMonthRestricted: parts[2] != "*",
+		isDayOfWeekRestricted:  parts[4] != "*",
+	}
+	if schedule.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("cron expression '%s' never matches", expression)
+	}
+	return schedule, nil
+}
+
+func parseCronField(value string, field cronField) ([]bool, error) {
+	allowed := make([]bool, field.max+1)
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step '%s' in %s field", stepPart, field.name)
+			}
+		}
+
+		start, end := field.min, field.max
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			start, err = parseCronValue(startPart, field)
+			if err != nil {
+				return nil, err
+			}
+			end = start
+			if isRange {
+				end, err = parseCronValue(endPart, field)
+				if err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				end = field.max
+			}
+			if start > end {
+				return nil, fmt.Errorf("invalid range '%s' in %s field", rangePart, field.name)
+			}
+		}
+
+		for i := start; i <= end; i += step {
+			allowed[i] = true
+		}
+	}
+	return allowed, nil
+}
+
+func parseCronValue(value string, field cronField) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < field.min || number > field.max {
+		return 0, fmt.Errorf("value '%s' in %s field must be a number between %d and %d", value, field.name, field.min, field.max)
+	}
+	return number, nil
+}
+
+// Next returns the first matching time strictly after the given time in its location, or the zero time if there is none.
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxCronSearchYears, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !s.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Like in cron, a day matches either field if both day of month and day of week are restricted.
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	matchesDayOfMonth := s.daysOfMonth[t.Day()]
+	matchesDayOfWeek := s.daysOfWeek[t.Weekday()]
+	if s.isDayOfMonthRestricted && s.isDayOfWeekRestricted {
+		return matchesDayOfMonth || matchesDayOfWeek
+	}
+	return matchesDayOfMonth && matchesDayOfWeek
+}
@@ -0,0 +1,39 @@
+//go:build fast
+
+package tools
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"testing"
+	"time"
+)
+
+func assertNextCronTime(t *testing.T, expression string, after, expected time.Time) {
+	schedule, err := ParseCronSchedule(expression)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, schedule.Next(after))
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	monday := time.Date(2025, 3, 10, 4, 30, 0, 0, time.UTC)
+
+	assertNextCronTime(t, "0 4 * * *", monday, time.Date(2025, 3, 11, 4, 0, 0, 0, time.UTC))
+	assertNextCronTime(t, "0 4 * * *", monday.Add(-time.Hour), time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC))
+	assertNextCronTime(t, "0 */6 * * *", monday, time.Date(2025, 3, 10, 6, 0, 0, 0, time.UTC))
+	assertNextCronTime(t, "*/15 * * * *", monday, time.Date(2025, 3, 10, 4, 45, 0, 0, time.UTC))
+	assertNextCronTime(t, "0 3 * * 0", monday, time.Date(2025, 3, 16, 3, 0, 0, 0, time.UTC))
+	assertNextCronTime(t, "0 3 * * 7", monday, time.Date(2025, 3, 16, 3, 0, 0, 0, time.UTC))
+	assertNextCronTime(t, "30 2 1 * *", monday, time.Date(2025, 4, 1, 2, 30, 0, 0, time.UTC))
+	assertNextCronTime(t, "0 0 1,15 * 1-5", monday, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC))
+	assertNextCronTime(t, "0 12 29 2 *", monday, time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC))
+	assertNextCronTime(t, "10-20/5 8 * * *", monday, time.Date(2025, 3, 10, 8, 10, 0, 0, time.UTC))
+	assertNextCronTime(t, "30 4 * * *", monday, time.Date(2025, 3, 11, 4, 30, 0, 0, time.UTC))
+}
+
+func TestParseInvalidCronSchedules(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "0 0 30 2 *"} {
+		_, err := ParseCronSchedule(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
@@ -10,6 +10,10 @@ type RepoApp struct {
 	VersionCreationTimestamp         time.Time
 	VersionContent                   []byte
 	ShouldBeRunning                  bool
+	IsAutoBackupEnabled              bool
+	IsAutoUpdateEnabled              bool
+	UpdatePolicy                     UpdatePolicy
+	IsPreReleaseChannelEnabled       bool
 }
 
 type FullAppInfo struct {
@@ -23,12 +27,31 @@ type FullAppInfo struct {
 }
 
 type AppDto struct {
-	Maintainer  string `json:"maintainer"`
-	AppName     string `json:"app_name"`
-	VersionName string `json:"version_name"`
-	AppId       string `json:"app_id"`
-	UrlPath     string `json:"url_path"`
-	Status      string `json:"status"`
+	Maintainer                 string       `json:"maintainer"`
+	AppName                    string       `json:"app_name"`
+	VersionName                string       `json:"version_name"`
+	AppId                      string       `json:"app_id"`
+	UrlPath                    string       `json:"url_path"`
+	Status                     string       `json:"status"`
+	IsAutoBackupEnabled        bool         `json:"is_auto_backup_enabled"`
+	IsAutoUpdateEnabled        bool         `json:"is_auto_update_enabled"`
+	UpdatePolicy               UpdatePolicy `json:"update_policy"`
+	IsPreReleaseChannelEnabled bool         `json:"is_pre_release_channel_enabled"`
+	// empty if no update is available within the update policy of the app
+	AvailableUpdateVersionName string `json:"available_update_version_name"`
+}
+
+// AppMaintenanceOverrides exclude a single app from the automatic backups or updates of the maintenance agent.
+type AppMaintenanceOverrides struct {
+	AppId               string `json:"app_id" validate:"number"`
+	IsAutoBackupEnabled bool   `json:"is_auto_backup_enabled"`
+	IsAutoUpdateEnabled bool   `json:"is_auto_update_enabled"`
+}
+
+type AppUpdatePolicy struct {
+	AppId                      string       `json:"app_id" validate:"number"`
+	UpdatePolicy               UpdatePolicy `json:"update_policy" validate:"update_policy"`
+	IsPreReleaseChannelEnabled bool         `json:"is_pre_release_channel_enabled"`
 }
 
 type VersionInfo struct {
@@ -67,14 +90,90 @@ type Authorization struct {
 	DidAcceptEula        bool      `json:"did_accept_eula"`
 }
 
-type RemoteBackupRepository struct {
+// BackupRepository is a restic repository backups are stored in. The SSH fields are only used by the "sftp" type and
+// the S3 fields only by the "s3" type. There is exactly one repository of type "local", which can not be deleted.
+// The SSH password is not needed when authenticating with the ssh key of ocelot-cloud.
+type BackupRepository struct {
+	Id                 int    `json:"id"`
+	Name               string `json:"name" validate:"repository_name"`
+	Type               string `json:"type" validate:"backup_repository_type"`
 	IsEnabled          bool   `json:"is_enabled"`
+	BackupIntervalDays int    `json:"backup_interval_days"`
+	KeepDaily          int    `json:"keep_daily"`
+	KeepWeekly         int    `json:"keep_weekly"`
+	KeepMonthly        int    `json:"keep_monthly"`
 	Host               string `json:"host" validate:"remote_host"`
-	SshPort            string `json:"ssh_port" validate:"number"`
-	SshUser            string `json:"ssh_user" validate:"user_name"`
-	SshPassword        string `json:"ssh_password" validate:"password"`
+	SshPort            string `json:"ssh_port" validate:"number_or_empty"`
+	SshUser            string `json:"ssh_user" validate:"user_name_or_empty"`
+	SshAuthMethod      string `json:"ssh_auth_method" validate:"ssh_auth_method"`
+	SshPassword        string `json:"ssh_password" validate:"password_or_empty"`
 	SshKnownHosts      string `json:"ssh_known_hosts" validate:"known_hosts"`
-	EncryptionPassword string `json:"encryption_password" validate:"password"`
+	S3Endpoint         string `json:"s3_endpoint" validate:"s3_endpoint"`
+	S3Bucket           string `json:"s3_bucket" validate:"s3_bucket"`
+	S3Prefix           string `json:"s3_prefix" validate:"s3_prefix"`
+	S3Region           string `json:"s3_region" validate:"s3_region"`
+	S3AccessKey        string `json:"s3_access_key" validate:"s3_access_key"`
+	S3SecretKey        string `json:"s3_secret_key" validate:"s3_secret_key"`
+	EncryptionPassword string `json:"encryption_password" validate:"password_or_empty"`
+	// Bandwidth limits in KiB/s, 0 means unlimited.
+	UploadLimitKib          int  `json:"upload_limit_kib"`
+	DownloadLimitKib        int  `json:"download_limit_kib"`
+	IsTransferWindowEnabled bool `json:"is_transfer_window_enabled"`
+	TransferWindowStartHour int  `json:"transfer_window_start_hour"`
+	TransferWindowEndHour   int  `json:"transfer_window_end_hour"`
+}
+
+const (
+	LocalBackupRepositoryId = 1
+
+	BackupRepositoryTypeLocal = "local"
+	BackupRepositoryTypeSftp  = "sftp"
+	BackupRepositoryTypeS3    = "s3"
+
+	SshAuthMethodPassword = "password"
+	SshAuthMethodKey      = "key"
+)
+
+func (r BackupRepository) IsLocal() bool {
+	return r.Type == BackupRepositoryTypeLocal
+}
+
+// IsTransferAllowedAt tells whether uploads to the repository are allowed at the given time. The window hours refer to the
+// location of the given time. A window ending before it starts spans midnight, e.g. from 22 to 6.
+func (r BackupRepository) IsTransferAllowedAt(t time.Time) bool {
+	if r.IsLocal() || !r.IsTransferWindowEnabled {
+		return true
+	}
+	hour := t.Hour()
+	if r.TransferWindowStartHour <= r.TransferWindowEndHour {
+		return hour >= r.TransferWindowStartHour && hour < r.TransferWindowEndHour
+	}
+	return hour >= r.TransferWindowStartHour || hour < r.TransferWindowEndHour
+}
+
+func (r BackupRepository) GetNextTransferWindowStart(t time.Time) time.Time {
+	start := time.Date(t.Year(), t.Month(), t.Day(), r.TransferWindowStartHour, 0, 0, 0, t.Location())
+	if start.Before(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+func (r BackupRepository) IsS3() bool {
+	return r.Type == BackupRepositoryTypeS3
+}
+
+func (r BackupRepository) UsesSshKey() bool {
+	return r.SshAuthMethod == SshAuthMethodKey
+}
+
+type BackupRepositoryRequest struct {
+	RepositoryId int `json:"repository_id"`
+}
+
+type RepositoryPasswordRotationRequest struct {
+	RepositoryId int    `json:"repository_id"`
+	NewPassword  string `json:"new_password" validate:"password"`
 }
 
 type RestoredVersionInfo struct {
@@ -99,6 +198,17 @@ type BackupInfo struct {
 	VersionCreationTimestamp time.Time         `json:"version_creation_timestamp"`
 	Description              BackupDescription `json:"description"`
 	BackupCreationTimestamp  time.Time         `json:"backup_creation_timestamp"`
+	Statistics               *BackupStatistics `json:"statistics"`
+}
+
+type BackupStatistics struct {
+	FilesNew             int     `json:"files_new"`
+	FilesChanged         int     `json:"files_changed"`
+	FilesUnmodified      int     `json:"files_unmodified"`
+	DataAdded            int64   `json:"data_added"`
+	TotalFilesProcessed  int     `json:"total_files_processed"`
+	TotalBytesProcessed  int64   `json:"total_bytes_processed"`
+	TotalDurationSeconds float64 `json:"total_duration_seconds"`
 }
 
 type UserFullInfo struct {
@@ -121,41 +231,70 @@ type MaintainerAndApp struct {
 }
 
 type BackupListRequest struct {
-	Maintainer string `json:"maintainer" validate:"user_name"`
-	AppName    string `json:"app_name" validate:"app_name"`
-	IsLocal    bool   `json:"is_local"`
+	Maintainer   string `json:"maintainer" validate:"user_name"`
+	AppName      string `json:"app_name" validate:"app_name"`
+	RepositoryId int    `json:"repository_id"`
 }
 
+// the database wipe for testing resets the id sequence, so the first remote repository created afterward always gets this id
+const SampleRemoteBackupRepositoryId = 2
+
 var (
 	SampleAppBackupListRequestLocal = BackupListRequest{
-		Maintainer: SampleMaintainer,
-		AppName:    SampleApp,
-		IsLocal:    true,
+		Maintainer:   SampleMaintainer,
+		AppName:      SampleApp,
+		RepositoryId: LocalBackupRepositoryId,
 	}
 	SampleAppBackupListRequestRemote = BackupListRequest{
-		Maintainer: SampleMaintainer,
-		AppName:    SampleApp,
-		IsLocal:    false,
+		Maintainer:   SampleMaintainer,
+		AppName:      SampleApp,
+		RepositoryId: SampleRemoteBackupRepositoryId,
 	}
 	OcelotDbAppBackupListRequestLocal = BackupListRequest{
-		Maintainer: OcelotDbMaintainer,
-		AppName:    OcelotDbAppName,
-		IsLocal:    true,
+		Maintainer:   OcelotDbMaintainer,
+		AppName:      OcelotDbAppName,
+		RepositoryId: LocalBackupRepositoryId,
 	}
 	OcelotDbAppBackupListRequestRemote = BackupListRequest{
-		Maintainer: OcelotDbMaintainer,
-		AppName:    OcelotDbAppName,
-		IsLocal:    false,
+		Maintainer:   OcelotDbMaintainer,
+		AppName:      OcelotDbAppName,
+		RepositoryId: SampleRemoteBackupRepositoryId,
 	}
 )
 
-type SingleBool struct {
-	Value bool `json:"value"`
+type BackupOperationRequest struct {
+	BackupId     string `json:"backup_id" validate:"restic_backup_id"`
+	RepositoryId int    `json:"repository_id"`
 }
 
-type BackupOperationRequest struct {
-	BackupId string `json:"backup_id" validate:"restic_backup_id"`
-	IsLocal  bool   `json:"is_local"`
+type BackupCloneRequest struct {
+	BackupId     string `json:"backup_id" validate:"restic_backup_id"`
+	RepositoryId int    `json:"repository_id"`
+	NewAppName   string `json:"new_app_name" validate:"app_name"`
+}
+
+type BackupExportRequest struct {
+	BackupId     string `json:"backup_id" validate:"restic_backup_id"`
+	RepositoryId int    `json:"repository_id"`
+	Password     string `json:"password" validate:"password"`
+}
+
+type BackupImportRequest struct {
+	Password string `validate:"password"`
+}
+
+type BackupFileRequest struct {
+	BackupId     string `json:"backup_id" validate:"restic_backup_id"`
+	RepositoryId int    `json:"repository_id"`
+	Path         string `json:"path" validate:"backup_file_path"`
+}
+
+type BackupFileInfo struct {
+	Name             string    `json:"name"`
+	Path             string    `json:"path"`
+	Type             string    `json:"type"`
+	Size             int64     `json:"size"`
+	ModificationTime time.Time `json:"modification_time"`
 }
 
 type NumberString struct {
@@ -174,6 +313,167 @@ type KnownHostsString struct {
 	Value string `json:"value" validate:"known_hosts"`
 }
 
+type SshPublicKeyString struct {
+	Value string `json:"value"`
+}
+
 type UserNameString struct {
 	Value string `json:"value" validate:"user_name"`
 }
+
+type QueuedOperation struct {
+	Id            int    `json:"id"`
+	Scope         int    `json:"scope"`
+	Operation     string `json:"operation"`
+	IsRunning     bool   `json:"is_running"`
+	QueuePosition int    `json:"queue_position"`
+}
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+type JobProgress struct {
+	PercentDone      float64 `json:"percent_done"`
+	BytesDone        int64   `json:"bytes_done"`
+	TotalBytes       int64   `json:"total_bytes"`
+	SecondsRemaining int     `json:"seconds_remaining"`
+}
+
+// ProgressReporter receives progress updates of long-running operations. It may be nil if nobody is interested in the progress.
+type ProgressReporter func(progress JobProgress)
+
+func (p ProgressReporter) Report(progress JobProgress) {
+	if p != nil {
+		p(progress)
+	}
+}
+
+type Job struct {
+	Id        int    `json:"id"`
+	Operation string `json:"operation"`
+	// 0 if the app is not known when the job is started, e.g. when restoring a backup
+	AppId  int       `json:"app_id"`
+	Status JobStatus `json:"status"`
+	// number of conflicting operations which must finish before a queued job is started
+	QueuePosition int         `json:"queue_position"`
+	Progress      JobProgress `json:"progress"`
+	Error         string      `json:"error"`
+	CreatedAt     time.Time   `json:"created_at"`
+	FinishedAt    *time.Time  `json:"finished_at"`
+}
+
+func (j Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed
+}
+
+type JobRequest struct {
+	JobId int `json:"job_id"`
+}
+
+type JobCreationResponse struct {
+	JobId int `json:"job_id"`
+}
+
+type MaintenanceRunTrigger string
+
+const (
+	MaintenanceRunTriggerSchedule MaintenanceRunTrigger = "schedule"
+	MaintenanceRunTriggerManual   MaintenanceRunTrigger = "manual"
+)
+
+type MaintenanceRunStatus string
+
+const (
+	MaintenanceRunStatusRunning   MaintenanceRunStatus = "running"
+	MaintenanceRunStatusSucceeded MaintenanceRunStatus = "succeeded"
+	MaintenanceRunStatusFailed    MaintenanceRunStatus = "failed"
+	// the backend was stopped or shut down while the run was ongoing
+	MaintenanceRunStatusInterrupted MaintenanceRunStatus = "interrupted"
+)
+
+type MaintenanceOutcome string
+
+const (
+	MaintenanceOutcomeBackedUp  MaintenanceOutcome = "backed_up"
+	MaintenanceOutcomeUpdated   MaintenanceOutcome = "updated"
+	MaintenanceOutcomeSucceeded MaintenanceOutcome = "succeeded"
+	MaintenanceOutcomeSkipped   MaintenanceOutcome = "skipped"
+	MaintenanceOutcomeFailed    MaintenanceOutcome = "failed"
+)
+
+type MaintenanceAppResult struct {
+	Maintainer string             `json:"maintainer"`
+	AppName    string             `json:"app_name"`
+	Outcome    MaintenanceOutcome `json:"outcome"`
+	Reason     string             `json:"reason"`
+}
+
+// MaintenanceRun records a maintenance cycle. An app may have several results, e.g. a failed update followed by a backup.
+type MaintenanceRun struct {
+	Id          int                    `json:"id"`
+	TriggeredBy MaintenanceRunTrigger  `json:"triggered_by"`
+	Status      MaintenanceRunStatus   `json:"status"`
+	StartedAt   time.Time              `json:"started_at"`
+	FinishedAt  *time.Time             `json:"finished_at"`
+	AppResults  []MaintenanceAppResult `json:"app_results"`
+	// empty if the retention policy was not due in this run
+	RetentionOutcome MaintenanceOutcome `json:"retention_outcome"`
+	RetentionReason  string             `json:"retention_reason"`
+	ProblemsCount    int                `json:"problems_count"`
+}
+
+type MaintenanceRunCreationResponse struct {
+	RunId int `json:"run_id"`
+}
+
+type RecoveryStatus struct {
+	IsRecoveryPossible bool `json:"is_recovery_possible"`
+}
+
+// RecoverySummary reports which apps were restored by the last disaster recovery. It is stored in the restored database,
+// so it can still be read after logging in with the credentials from the backup.
+type RecoverySummary struct {
+	RepositoryName string              `json:"repository_name"`
+	StartedAt      time.Time           `json:"started_at"`
+	FinishedAt     time.Time           `json:"finished_at"`
+	Apps           []AppRecoveryResult `json:"apps"`
+}
+
+type AppRecoveryResult struct {
+	Maintainer              string    `json:"maintainer"`
+	AppName                 string    `json:"app_name"`
+	BackupId                string    `json:"backup_id"`
+	BackupCreationTimestamp time.Time `json:"backup_creation_timestamp"`
+	IsRestored              bool      `json:"is_restored"`
+	Error                   string    `json:"error"`
+}
+
+// NotificationSettings configure the channels through which the administrator is informed about failures and about the
+// results of maintenance cycles. The SMTP user and password are optional, since local relays often accept mails without them.
+type NotificationSettings struct {
+	IsEmailEnabled                 bool     `json:"is_email_enabled"`
+	SmtpHost                       string   `json:"smtp_host" validate:"remote_host"`
+	SmtpPort                       string   `json:"smtp_port" validate:"number_or_empty"`
+	SmtpUser                       string   `json:"smtp_user" validate:"smtp_user"`
+	SmtpPassword                   string   `json:"smtp_password" validate:"smtp_password"`
+	SenderAddress                  string   `json:"sender_address" validate:"email_or_empty"`
+	Recipients                     []string `json:"recipients" validate:"email"`
+	IsWebhookEnabled               bool     `json:"is_webhook_enabled"`
+	WebhookUrl                     string   `json:"webhook_url" validate:"webhook_url"`
+	AreMaintenanceSummariesEnabled bool     `json:"are_maintenance_summaries_enabled"`
+}
+
+// ClockAdvanceRequest fast-forwards the mocked clock of testing profiles.
+type ClockAdvanceRequest struct {
+	Seconds int `json:"seconds"`
+}
+
+type ClockResponse struct {
+	Now time.Time `json:"now"`
+}
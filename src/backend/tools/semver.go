@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SemanticVersion is a version name like "1.2.3", "v2.0" or "1.3.0-beta.1". Missing minor and patch numbers count as 0 and
+// build metadata like "+build.5" is ignored, as it does not affect the precedence of versions.
+type SemanticVersion struct {
+	Major, Minor, Patch int
+	PreRelease          string
+}
+
+var semanticVersionPattern = regexp.MustCompile(`^v?(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*))?(?:\.(0|[1-9][0-9]*))?(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+func ParseSemanticVersion(name string) (*SemanticVersion, error) {
+	matches := semanticVersionPattern.FindStringSubmatch(name)
+	if matches == nil {
+		return nil, fmt.Errorf("version name '%s' is not a semantic version", name)
+	}
+	numbers := make([]int, 3)
+	for i := range numbers {
+		if matches[i+1] == "" {
+			continue
+		}
+		number, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("version name '%s' is not a semantic version", name)
+		}
+		numbers[i] = number
+	}
+	return &SemanticVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], PreRelease: matches[4]}, nil
+}
+
+func (v SemanticVersion) IsPreRelease() bool {
+	return v.PreRelease != ""
+}
+
+// Compare returns a negative number if v precedes other, a positive number if v follows other and 0 if both are equal.
+func (v SemanticVersion) Compare(other SemanticVersion) int {
+	if v.Major != other.Major {
+		return v.Major - other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor - other.Minor
+	}
+	if v.Patch != other.Patch {
+		return v.Patch - other.Patch
+	}
+	return comparePreReleases(v.PreRelease, other.PreRelease)
+}
+
+// A release follows all of its pre-releases. Pre-releases are compared identifier by identifier, numeric identifiers
+// numerically and others lexically, with numeric identifiers preceding the others.
+func comparePreReleases(a, b string) int {
+	if a == b {
+		return 0
+	} else if a == "" {
+		return 1
+	} else if b == "" {
+		return -1
+	}
+	identifiersA := strings.Split(a, ".")
+	identifiersB := strings.Split(b, ".")
+	for i := 0; i < len(identifiersA) && i < len(identifiersB); i++ {
+		numberA, errA := strconv.Atoi(identifiersA[i])
+		numberB, errB := strconv.Atoi(identifiersB[i])
+		switch {
+		case errA == nil && errB == nil:
+			if numberA != numberB {
+				return numberA - numberB
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if comparison := strings.Compare(identifiersA[i], identifiersB[i]); comparison != 0 {
+				return comparison
+			}
+		}
+	}
+	return len(identifiersA) - len(identifiersB)
+}
+
+// UpdatePolicy limits which versions an app is updated to, by manual updates as well as by the maintenance agent.
+type UpdatePolicy string
+
+const (
+	UpdatePolicyPatch UpdatePolicy = "patch"
+	UpdatePolicyMinor UpdatePolicy = "minor"
+	UpdatePolicyMajor UpdatePolicy = "major"
+	// UpdatePolicyAny updates to the most recently published version, even if its version name is not semantically newer.
+	UpdatePolicyAny UpdatePolicy = "any"
+
+	DefaultUpdatePolicy = UpdatePolicyMajor
+)
+
+func (p UpdatePolicy) allows(installed, candidate SemanticVersion) bool {
+	switch p {
+	case UpdatePolicyPatch:
+		return candidate.Major == installed.Major && candidate.Minor == installed.Minor
+	case UpdatePolicyMinor:
+		return candidate.Major == installed.Major
+	default:
+		return true
+	}
+}
+
+// SelectUpdateVersion returns the version the app should be updated to according to its update policy, or nil if there is
+// none. Versions are compared semantically where possible. If the installed version name is not semantic, the most
+// recently published version is chosen instead. Pre-releases are only considered if the pre-release channel of the app is enabled.
+func SelectUpdateVersion(app RepoApp, versions []VersionInfo) *VersionInfo {
+	installed, installedErr := ParseSemanticVersion(app.VersionName)
+	isComparingByPublicationDate := installedErr != nil || app.UpdatePolicy == UpdatePolicyAny
+
+	var selected *VersionInfo
+	var selectedSemantic *SemanticVersion
+	for i := range versions {
+		candidate := &versions[i]
+		candidateSemantic, candidateErr := ParseSemanticVersion(candidate.Name)
+		if candidateErr == nil && candidateSemantic.IsPreRelease() && !app.IsPreReleaseChannelEnabled {
+			continue
+		}
+
+		if isComparingByPublicationDate {
+			if candidate.VersionCreationTimestamp.After(app.VersionCreationTimestamp) &&
+				(selected == nil || candidate.VersionCreationTimestamp.After(selected.VersionCreationTimestamp)) {
+				selected = candidate
+			}
+			continue
+		}
+
+		if candidateErr != nil || candidateSemantic.Compare(*installed) <= 0 || !app.UpdatePolicy.allows(*installed, *candidateSemantic) {
+			continue
+		}
+		if selected == nil {
+			selected, selectedSemantic = candidate, candidateSemantic
+			continue
+		}
+		// equal versions may be published under different names like "1.0" and "v1.0.0", in which case the newest one wins
+		comparison := candidateSemantic.Compare(*selectedSemantic)
+		if comparison > 0 || (comparison == 0 && candidate.VersionCreationTimestamp.After(selected.VersionCreationTimestamp)) {
+			selected, selectedSemantic = candidate, candidateSemantic
+		}
+	}
+	return selected
+}
@@ -0,0 +1,107 @@
+//go:build fast
+
+package tools
+
+import (
+	"github.com/ocelot-cloud/shared/assert"
+	"testing"
+	"time"
+)
+
+func TestParseSemanticVersion(t *testing.T) {
+	version, err := ParseSemanticVersion("1.2.3")
+	assert.Nil(t, err)
+	assert.Equal(t, SemanticVersion{Major: 1, Minor: 2, Patch: 3}, *version)
+
+	version, err = ParseSemanticVersion("v2.0")
+	assert.Nil(t, err)
+	assert.Equal(t, SemanticVersion{Major: 2}, *version)
+
+	version, err = ParseSemanticVersion("1.3.0-beta.1+build.5")
+	assert.Nil(t, err)
+	assert.Equal(t, SemanticVersion{Major: 1, Minor: 3, PreRelease: "beta.1"}, *version)
+	assert.True(t, version.IsPreRelease())
+
+	for _, name := range []string{"", "latest", "1.2.3.4", "01.2", "1.2-", "release-2024"} {
+		_, err = ParseSemanticVersion(name)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestCompareSemanticVersions(t *testing.T) {
+	orderedVersions := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.2", "1.10", "2.0"}
+	for i := 0; i < len(orderedVersions)-1; i++ {
+		lower, err := ParseSemanticVersion(orderedVersions[i])
+		assert.Nil(t, err)
+		higher, err := ParseSemanticVersion(orderedVersions[i+1])
+		assert.Nil(t, err)
+		assert.True(t, lower.Compare(*higher) < 0)
+		assert.True(t, higher.Compare(*lower) > 0)
+	}
+
+	a, _ := ParseSemanticVersion("v1.0")
+	b, _ := ParseSemanticVersion("1.0.0+build.1")
+	assert.Equal(t, 0, a.Compare(*b))
+}
+
+func assertSelectedUpdateVersion(t *testing.T, app RepoApp, versions []VersionInfo, expectedVersionName string) {
+	selectedVersionName := ""
+	if selected := SelectUpdateVersion(app, versions); selected != nil {
+		selectedVersionName = selected.Name
+	}
+	assert.Equal(t, expectedVersionName, selectedVersionName)
+}
+
+func TestSelectUpdateVersion(t *testing.T) {
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	versions := []VersionInfo{
+		{Id: "1", Name: "1.2.0", VersionCreationTimestamp: start},
+		{Id: "2", Name: "1.2.1", VersionCreationTimestamp: start.Add(1 * time.Hour)},
+		{Id: "3", Name: "1.3.0", VersionCreationTimestamp: start.Add(2 * time.Hour)},
+		{Id: "4", Name: "2.0.0", VersionCreationTimestamp: start.Add(3 * time.Hour)},
+		{Id: "5", Name: "2.1.0-beta.1", VersionCreationTimestamp: start.Add(4 * time.Hour)},
+		// an old branch which was republished recently must not cause a downgrade
+		{Id: "6", Name: "1.1.9", VersionCreationTimestamp: start.Add(5 * time.Hour)},
+	}
+	app := RepoApp{VersionName: "1.2.0", VersionCreationTimestamp: start}
+
+	app.UpdatePolicy = UpdatePolicyPatch
+	assertSelectedUpdateVersion(t, app, versions, "1.2.1")
+	app.UpdatePolicy = UpdatePolicyMinor
+	assertSelectedUpdateVersion(t, app, versions, "1.3.0")
+	app.UpdatePolicy = UpdatePolicyMajor
+	assertSelectedUpdateVersion(t, app, versions, "2.0.0")
+	app.IsPreReleaseChannelEnabled = true
+	assertSelectedUpdateVersion(t, app, versions, "2.1.0-beta.1")
+	app.UpdatePolicy = UpdatePolicyAny
+	assertSelectedUpdateVersion(t, app, versions, "1.1.9")
+
+	app = RepoApp{VersionName: "2.0.0", VersionCreationTimestamp: start.Add(3 * time.Hour), UpdatePolicy: UpdatePolicyMajor}
+	assertSelectedUpdateVersion(t, app, versions, "")
+
+	// an unknown policy, e.g. of apps installed before policies existed, behaves like the default policy
+	app = RepoApp{VersionName: "1.2.0", VersionCreationTimestamp: start}
+	assertSelectedUpdateVersion(t, app, versions, "2.0.0")
+}
+
+func TestSelectUpdateVersionWithoutSemanticVersionNames(t *testing.T) {
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	versions := []VersionInfo{
+		{Id: "1", Name: "stable", VersionCreationTimestamp: start},
+		{Id: "2", Name: "nightly", VersionCreationTimestamp: start.Add(24 * time.Hour)},
+		{Id: "3", Name: "3.0.0-rc.1", VersionCreationTimestamp: start.Add(48 * time.Hour)},
+	}
+	app := RepoApp{VersionName: "stable", VersionCreationTimestamp: start, UpdatePolicy: UpdatePolicyPatch}
+	assertSelectedUpdateVersion(t, app, versions, "nightly")
+
+	// semantic version names can not be compared to a non-semantic installed version, so only newer releases are considered
+	app.VersionName = "nightly"
+	app.VersionCreationTimestamp = start.Add(24 * time.Hour)
+	assertSelectedUpdateVersion(t, app, versions, "")
+	app.IsPreReleaseChannelEnabled = true
+	assertSelectedUpdateVersion(t, app, versions, "3.0.0-rc.1")
+
+	// versions which are not semantic are ignored if the installed version is semantic
+	app = RepoApp{VersionName: "1.0", VersionCreationTimestamp: start.Add(-time.Hour), UpdatePolicy: UpdatePolicyMajor}
+	assertSelectedUpdateVersion(t, app, versions[:2], "")
+}
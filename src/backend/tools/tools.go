@@ -82,16 +82,6 @@ func AreMocksUsed() bool {
 	return os.Getenv("USE_MOCKS") == "true"
 }
 
-func TryLockAndRespondForError(w http.ResponseWriter, operation string) error {
-	err := AppOperationMutex.TryLock(operation)
-	if err != nil {
-		message := "could not execute this operation since there is another operation in progress: " + operation
-		Logger.Error(message)
-		http.Error(w, message, http.StatusBadRequest)
-	}
-	return err
-}
-
 func FindUniqueMaintainerAndAppNamePairs(apps []MaintainerAndApp) []MaintainerAndApp {
 	seen := make(map[string]struct{})
 	var result []MaintainerAndApp
@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"github.com/ocelot-cloud/shared/validation"
+	"regexp"
+)
+
+// These validation types are only needed by the cloud backend, so they are registered here instead of in the shared module.
+func init() {
+	validation.ValidationTypeMap["backup_file_path"] = regexp.MustCompile(`^(/[a-zA-Z0-9 _.,@+-]+)*/?$`)
+	validation.ValidationTypeMap["repository_name"] = regexp.MustCompile("^[a-z0-9-]{3,20}$")
+	validation.ValidationTypeMap["backup_repository_type"] = regexp.MustCompile("^(" + BackupRepositoryTypeLocal + "|" + BackupRepositoryTypeSftp + "|" + BackupRepositoryTypeS3 + ")$")
+	validation.ValidationTypeMap["ssh_auth_method"] = regexp.MustCompile("^(|" + SshAuthMethodPassword + "|" + SshAuthMethodKey + ")$")
+	validation.ValidationTypeMap["number_or_empty"] = regexp.MustCompile("^[0-9]{0,20}$")
+	validation.ValidationTypeMap["user_name_or_empty"] = regexp.MustCompile("^$|^[a-z0-9]{3,20}$")
+	validation.ValidationTypeMap["password_or_empty"] = regexp.MustCompile("^$|^[a-zA-Z0-9._-]{8,30}$")
+	validation.ValidationTypeMap["s3_endpoint"] = regexp.MustCompile("^$|^https?://[a-zA-Z0-9.:_-]{1,128}$")
+	validation.ValidationTypeMap["s3_bucket"] = regexp.MustCompile("^$|^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$")
+	validation.ValidationTypeMap["s3_prefix"] = regexp.MustCompile("^$|^[a-zA-Z0-9_.-]+(/[a-zA-Z0-9_.-]+)*$")
+	validation.ValidationTypeMap["s3_region"] = regexp.MustCompile("^[a-z0-9-]{0,32}$")
+	validation.ValidationTypeMap["s3_access_key"] = regexp.MustCompile("^[a-zA-Z0-9._-]{0,128}$")
+	validation.ValidationTypeMap["s3_secret_key"] = regexp.MustCompile("^[a-zA-Z0-9/+=._-]{0,128}$")
+	validation.ValidationTypeMap["timestamp"] = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})$`)
+	validation.ValidationTypeMap["smtp_user"] = regexp.MustCompile("^[a-zA-Z0-9@._+-]{0,128}$")
+	validation.ValidationTypeMap["smtp_password"] = regexp.MustCompile(`^[!-~]{0,128}$`)
+	validation.ValidationTypeMap["webhook_url"] = regexp.MustCompile(`^$|^https?://[a-zA-Z0-9.:_-]{1,128}(/[a-zA-Z0-9._~%/+=&?-]*)?$`)
+	validation.ValidationTypeMap["cron_expression"] = regexp.MustCompile(`^[0-9*/,-]{1,64}( [0-9*/,-]{1,64}){4}$`)
+	validation.ValidationTypeMap["timezone"] = regexp.MustCompile(`^[A-Za-z0-9_+/-]{1,64}$`)
+	validation.ValidationTypeMap["update_policy"] = regexp.MustCompile("^(" + string(UpdatePolicyPatch) + "|" + string(UpdatePolicyMinor) + "|" + string(UpdatePolicyMajor) + "|" + string(UpdatePolicyAny) + ")$")
+	validation.ValidationTypeMap["backup_description"] = regexp.MustCompile("^(" + string(AutoBackupDescription) + "|" + string(ManualBackupDescription) + ")$")
+}
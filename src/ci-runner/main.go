@@ -51,7 +51,7 @@ var fullCleanupCmd = &cobra.Command{
 	Long:  "Removes processes and docker artifacts",
 	Run: func(cmd *cobra.Command, args []string) {
 		tr.Cleanup()
-		removeContainersCommand := fmt.Sprintf("docker rm -f ocelotcloud $s restic remote_backup_server || true'", ocelotDbContainerName)
+		removeContainersCommand := fmt.Sprintf("docker rm -f ocelotcloud $s restic remote_backup_server remote_backup_s3_server || true'", ocelotDbContainerName)
 		tr.Execute(removeContainersCommand)
 		tr.Execute("docker rmi -f ocelotcloud/ocelotcloud:local restic:local sampleapp:local app:local || true")
 		tr.Execute("docker network rm $(docker network ls -q)")
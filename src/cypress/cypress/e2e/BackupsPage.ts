@@ -83,19 +83,19 @@ export class BackupsPage {
 
     shouldOnlyContainLocalRepoType() {
         cy.get('#backup-repository-selector').click({force: true})
-        cy.contains('.v-list-item', 'Local')
-        cy.get('.v-list-item').should('not.contain', 'Remote')
+        cy.contains('.v-list-item', 'local')
+        cy.get('.v-list-item').should('not.contain', 'remote')
     }
 
     shouldContainBothRepoTypes() {
         cy.get('#backup-repository-selector').click({force: true})
-        cy.contains('.v-list-item', 'Local')
-        cy.contains('.v-list-item', 'Remote')
+        cy.contains('.v-list-item', 'local')
+        cy.contains('.v-list-item', 'remote')
     }
 
     selectRemoteRepo() {
         cy.get('#backup-repository-selector').click({force: true})
-        cy.contains('.v-list-item', 'Remote').click({ force: true })
+        cy.contains('.v-list-item', 'remote').click({ force: true })
         return this
     }
 }
@@ -1,5 +1,6 @@
 import {clickConfirmationButton, goTo, Pages} from "./tools";
 
+let sampleRepositoryName = "remote"
 let sampleHost = "localhost"
 let sampleSshPort = "2222"
 let sampleSshUser = "sshadmin"
@@ -23,6 +24,19 @@ export class SettingsPage {
         return this
     }
 
+    selectNewRemoteRepository() {
+        cy.get('#backup-repository-selection-dropdown').click({force: true})
+        cy.contains('.v-list-item', 'New remote repository').click({force: true})
+        cy.get("#backup-repository-name").type(sampleRepositoryName)
+        return this
+    }
+
+    selectSampleRemoteRepository() {
+        cy.get('#backup-repository-selection-dropdown').click({force: true})
+        cy.contains('.v-list-item', sampleRepositoryName).click({force: true})
+        return this
+    }
+
     shouldRemoteBackupsBeEnabled(isEnabled: boolean) {
         let remoteBackupEnabledStatus: String
         if (isEnabled) {
@@ -87,7 +101,7 @@ export class SettingsPage {
             this.saveRemoteBackupsSettings()
 
             cy.get("#remote-backup-test-access").click()
-            cy.get('@alert').should('have.been.calledWith', 'SSH access test was successful')
+            cy.get('@alert').should('have.been.calledWith', 'Access test was successful')
         })
 
         return this
@@ -111,17 +125,15 @@ export class SettingsPage {
         return this
     }
 
-    assertMaintenanceHour(number: number) {
-        let expectedTime = number.toString() + ":00"
-        cy.get('body').should('contain.text', expectedTime)
+    assertBackupSchedule(schedule: string) {
+        cy.get('#backup-schedule').should('have.value', schedule)
         return this
     }
 
     setCustomMaintenanceSettings() {
         cy.get('#auto-updates-enabled-checkbox').click()
         cy.get('#auto-backups-enabled-checkbox').click()
-        cy.get('#preferred-hour-selection-dropdown').click({force: true})
-        cy.get('.v-list-item-title').contains('7:00').click()
+        cy.get('#backup-schedule').clear().type('0 */6 * * *')
         cy.get('#maintenance-save-button').click()
         return this
     }
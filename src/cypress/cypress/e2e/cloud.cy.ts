@@ -96,12 +96,12 @@ describe('template spec', () => {
     new SettingsPage()
       .assertEnableAutoUpdates(true)
       .assertEnableAutoBackups(true)
-      .assertMaintenanceHour(4)
+      .assertBackupSchedule('0 4 * * *')
       .setCustomMaintenanceSettings()
     new SettingsPage()
       .assertEnableAutoUpdates(false)
       .assertEnableAutoBackups(false)
-      .assertMaintenanceHour(7)
+      .assertBackupSchedule('0 */6 * * *')
   })
 
   it('assert apps search feature', () => {
@@ -122,6 +122,7 @@ describe('template spec', () => {
   it('check remote backup', () => {
     setup()
     new SettingsPage()
+        .selectNewRemoteRepository()
         .addSampleSshSettings()
         .enableRemoteBackups()
         .saveRemoteBackupsSettings()
@@ -154,6 +155,7 @@ describe('template spec', () => {
     new BackupsPage()
         .shouldOnlyContainLocalRepoType()
     new SettingsPage()
+        .selectNewRemoteRepository()
         .addSampleSshSettings()
         .enableRemoteBackups()
         .saveRemoteBackupsSettings()
@@ -164,6 +166,7 @@ describe('template spec', () => {
   it('check remote backup settings', () => {
     setup()
     new SettingsPage()
+        .selectNewRemoteRepository()
         .shouldRemoteBackupsBeEnabled(false)
         .assertEmptySshSettings()
         .enableRemoteBackups()
@@ -172,6 +175,7 @@ describe('template spec', () => {
         .saveRemoteBackupsSettings()
     cy.reload()
     new SettingsPage()
+        .selectSampleRemoteRepository()
         .readAndAssertSshSettings()
         .assertSshKnownHostsAndTestConnection()
   })
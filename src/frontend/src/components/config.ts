@@ -28,9 +28,17 @@ enum PROFILE_VALUES {
     DOCKER = "DOCKER",
 }
 
-export const backendReadSshSettingsPath = "/api/settings/ssh/read"
+export const backendListBackupRepositoriesPath = "/api/settings/repositories/list"
 export const backendReadMaintenanceSettingsPath = "/api/settings/maintenance/read"
 export const backendSaveMaintenanceSettingsPath = "/api/settings/maintenance/save"
+export const backendListMaintenanceRunsPath = "/api/maintenance/runs/list"
+export const backendStartMaintenanceRunPath = "/api/maintenance/runs/start"
+export const backendReadNotificationSettingsPath = "/api/settings/notifications/read"
+export const backendSaveNotificationSettingsPath = "/api/settings/notifications/save"
+export const backendTestNotificationSettingsPath = "/api/settings/notifications/test"
+export const backendRecoveryStatusPath = "/api/recovery/status"
+export const backendStartRecoveryPath = "/api/recovery/start"
+export const backendRecoverySummaryPath = "/api/recovery/summary"
 
 export const frontendHomePath = "/"
 export const frontendStorePath = "store"
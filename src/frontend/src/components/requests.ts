@@ -39,4 +39,41 @@ export async function doRequest(url: string, data: any): Promise<(AxiosResponse
 export async function doCloudRequest(path: string, body: any) {
     return await doRequest(cloudBaseUrl + path, body)
 
-}
\ No newline at end of file
+}
+
+export interface JobProgress {
+    percent_done: number
+    bytes_done: number
+    total_bytes: number
+    seconds_remaining: number
+}
+
+export interface Job {
+    id: number
+    operation: string
+    status: string
+    queue_position: number
+    progress: JobProgress
+    error: string
+}
+
+// Polls the status of a background job until it is finished. Failed polls are ignored, since the backend may be temporarily
+// unreachable while the database app itself is backed up or restored.
+export async function waitForJob(jobId: number, onUpdate: (job: Job) => void = () => {}): Promise<Job | null> {
+    for (;;) {
+        await new Promise(resolve => setTimeout(resolve, 1000))
+        try {
+            const response = await axios.post(cloudBaseUrl + "/api/jobs/status", { job_id: jobId }, { withCredentials: true })
+            const job: Job = response.data
+            if (job.status === "succeeded" || job.status === "failed") {
+                if (job.status === "failed") {
+                    alert(`An error occurred: ${job.operation} failed: ${job.error}`)
+                }
+                return job
+            }
+            onUpdate(job)
+        } catch (error: any) {
+            console.log("could not fetch job status: ", error)
+        }
+    }
+}
@@ -11,4 +11,9 @@ export interface AppDto {
     version_name: string
     url_path: string
     status: string
+    is_auto_backup_enabled: boolean
+    is_auto_update_enabled: boolean
+    update_policy: string
+    is_pre_release_channel_enabled: boolean
+    available_update_version_name: string
 }
\ No newline at end of file